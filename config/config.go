@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/apex/log"
 	yaml "gopkg.in/yaml.v2"
@@ -50,6 +51,8 @@ type Homebrew struct {
 	Conflicts    []string     `yaml:",omitempty"`
 	Description  string       `yaml:",omitempty"`
 	Homepage     string       `yaml:",omitempty"`
+	Builds       []string     `yaml:",omitempty"`
+	Goamd64      string       `yaml:",omitempty"`
 
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
@@ -70,9 +73,62 @@ type Hooks struct {
 	XXX map[string]interface{} `yaml:",inline"`
 }
 
+// Hook is a command run before or after the release
+type Hook struct {
+	Cmd          string   `yaml:",omitempty"`
+	Shell        bool     `yaml:",omitempty"`
+	Env          []string `yaml:",omitempty"`
+	IgnoreErrors bool     `yaml:"ignore_errors,omitempty"`
+
+	// Capture all undefined fields and should be empty after loading
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
+// UnmarshalYAML allows a hook to be written as just its command
+func (h *Hook) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var cmd string
+	if err := unmarshal(&cmd); err == nil {
+		h.Cmd = cmd
+		return nil
+	}
+	type hook Hook
+	var result hook
+	if err := unmarshal(&result); err != nil {
+		return err
+	}
+	*h = Hook(result)
+	return nil
+}
+
+// GlobalHooks define commands to run before or after the whole release
+type GlobalHooks struct {
+	Hooks []Hook `yaml:",omitempty"`
+
+	// Capture all undefined fields and should be empty after loading
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
 // IgnoredBuild represents a build ignored by the user
 type IgnoredBuild struct {
-	Goos, Goarch, Goarm string
+	Goos, Goarch, Goarm             string
+	Goamd64, Go386, Gomips, Goppc64 string
+
+	// Capture all undefined fields and should be empty after loading
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
+// BuildOverride changes the flags, env and tags of a build for the targets
+// matching its goos, goarch and goarm. Empty matchers match any target.
+type BuildOverride struct {
+	Goos     string   `yaml:",omitempty"`
+	Goarch   string   `yaml:",omitempty"`
+	Goarm    string   `yaml:",omitempty"`
+	Flags    string   `yaml:",omitempty"`
+	Ldflags  string   `yaml:",omitempty"`
+	Gcflags  string   `yaml:",omitempty"`
+	Asmflags string   `yaml:",omitempty"`
+	Env      []string `yaml:",omitempty"`
+	Tags     []string `yaml:",omitempty"`
 
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
@@ -80,16 +136,25 @@ type IgnoredBuild struct {
 
 // Build contains the build configuration section
 type Build struct {
-	Goos    []string       `yaml:",omitempty"`
-	Goarch  []string       `yaml:",omitempty"`
-	Goarm   []string       `yaml:",omitempty"`
-	Ignore  []IgnoredBuild `yaml:",omitempty"`
-	Main    string         `yaml:",omitempty"`
-	Ldflags string         `yaml:",omitempty"`
-	Flags   string         `yaml:",omitempty"`
-	Binary  string         `yaml:",omitempty"`
-	Hooks   Hooks          `yaml:",omitempty"`
-	Env     []string       `yaml:",omitempty"`
+	ID        string          `yaml:",omitempty"`
+	Goos      []string        `yaml:",omitempty"`
+	Goarch    []string        `yaml:",omitempty"`
+	Goarm     []string        `yaml:",omitempty"`
+	Goamd64   []string        `yaml:",omitempty"`
+	Go386     []string        `yaml:",omitempty"`
+	Gomips    []string        `yaml:",omitempty"`
+	Goppc64   []string        `yaml:",omitempty"`
+	Ignore    []IgnoredBuild  `yaml:",omitempty"`
+	Main      string          `yaml:",omitempty"`
+	Ldflags   string          `yaml:",omitempty"`
+	Flags     string          `yaml:",omitempty"`
+	Gcflags   string          `yaml:",omitempty"`
+	Asmflags  string          `yaml:",omitempty"`
+	Tags      []string        `yaml:",omitempty"`
+	Binary    string          `yaml:",omitempty"`
+	Hooks     Hooks           `yaml:",omitempty"`
+	Env       []string        `yaml:",omitempty"`
+	Overrides []BuildOverride `yaml:",omitempty"`
 
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
@@ -112,6 +177,28 @@ type Archive struct {
 	WrapInDirectory bool              `yaml:"wrap_in_directory,omitempty"`
 	Replacements    map[string]string `yaml:",omitempty"`
 	Files           []string          `yaml:",omitempty"`
+	Builds          []string          `yaml:",omitempty"`
+	WasmExec        bool              `yaml:"wasm_exec,omitempty"`
+
+	// Capture all undefined fields and should be empty after loading
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
+// SBOM config used to generate the software bills of materials of the
+// binaries and of the release
+type SBOM struct {
+	Formats      []string `yaml:",omitempty"`
+	NameTemplate string   `yaml:"name_template,omitempty"`
+	Builds       []string `yaml:",omitempty"`
+
+	// Capture all undefined fields and should be empty after loading
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
+// Provenance config used to generate the provenance statement of the release
+type Provenance struct {
+	BuilderID    string `yaml:"builder_id,omitempty"`
+	NameTemplate string `yaml:"name_template,omitempty"`
 
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
@@ -119,10 +206,12 @@ type Archive struct {
 
 // Release config used for the GitHub release
 type Release struct {
-	GitHub       Repo   `yaml:",omitempty"`
-	Draft        bool   `yaml:",omitempty"`
-	Prerelease   bool   `yaml:",omitempty"`
-	NameTemplate string `yaml:"name_template,omitempty"`
+	GitHub           Repo   `yaml:",omitempty"`
+	Draft            bool   `yaml:",omitempty"`
+	Prerelease       string `yaml:",omitempty"`
+	NameTemplate     string `yaml:"name_template,omitempty"`
+	BodyTemplate     string `yaml:"body_template,omitempty"`
+	BodyTemplateFile string `yaml:"body_template_file,omitempty"`
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
 }
@@ -139,6 +228,8 @@ type FPM struct {
 	License      string            `yaml:",omitempty"`
 	Bindir       string            `yaml:",omitempty"`
 	Files        map[string]string `yaml:",omitempty"`
+	Builds       []string          `yaml:",omitempty"`
+	Packager     string            `yaml:",omitempty"`
 
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
@@ -166,6 +257,7 @@ type Snapcraft struct {
 	Grade       string                          `yaml:",omitempty"`
 	Confinement string                          `yaml:",omitempty"`
 	Apps        map[string]SnapcraftAppMetadata `yaml:",omitempty"`
+	Builds      []string                        `yaml:",omitempty"`
 
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
@@ -193,11 +285,16 @@ type Docker struct {
 	Goos        string   `yaml:",omitempty"`
 	Goarch      string   `yaml:",omitempty"`
 	Goarm       string   `yaml:",omitempty"`
+	Goamd64     string   `yaml:",omitempty"`
+	Go386       string   `yaml:",omitempty"`
+	Gomips      string   `yaml:",omitempty"`
+	Goppc64     string   `yaml:",omitempty"`
 	Image       string   `yaml:",omitempty"`
 	Dockerfile  string   `yaml:",omitempty"`
 	Latest      bool     `yaml:",omitempty"`
 	TagTemplate string   `yaml:"tag_template,omitempty"`
 	Files       []string `yaml:"extra_files,omitempty"`
+	Builds      []string `yaml:",omitempty"`
 
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
@@ -205,10 +302,11 @@ type Docker struct {
 
 // Artifactory server configuration
 type Artifactory struct {
-	Target   string `yaml:",omitempty"`
-	Name     string `yaml:",omitempty"`
-	Username string `yaml:",omitempty"`
-	Mode     string `yaml:",omitempty"`
+	Target   string   `yaml:",omitempty"`
+	Name     string   `yaml:",omitempty"`
+	Username string   `yaml:",omitempty"`
+	Mode     string   `yaml:",omitempty"`
+	Builds   []string `yaml:",omitempty"`
 
 	// Capture all undefined fields and should be empty after loading
 	XXX map[string]interface{} `yaml:",inline"`
@@ -222,6 +320,27 @@ type Filters struct {
 	XXX map[string]interface{} `yaml:",inline"`
 }
 
+// Retry config for the network uploads
+type Retry struct {
+	Attempts    int           `yaml:",omitempty"`
+	Delay       time.Duration `yaml:",omitempty"`
+	MaxDelay    time.Duration `yaml:"max_delay,omitempty"`
+	StatusCodes []int         `yaml:"status_codes,omitempty"`
+
+	// Capture all undefined fields and should be empty after loading
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
+// Monorepo config, used to release one of several projects that share a
+// repository
+type Monorepo struct {
+	TagPrefix string `yaml:"tag_prefix,omitempty"`
+	Dir       string `yaml:",omitempty"`
+
+	// Capture all undefined fields and should be empty after loading
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
 // Changelog Config
 type Changelog struct {
 	Filters Filters `yaml:",omitempty"`
@@ -231,6 +350,18 @@ type Changelog struct {
 	XXX map[string]interface{} `yaml:",inline"`
 }
 
+// Plugin config, an external executable run as a pipe
+type Plugin struct {
+	Name  string   `yaml:",omitempty"`
+	Cmd   string   `yaml:",omitempty"`
+	Args  []string `yaml:",omitempty"`
+	Env   []string `yaml:",omitempty"`
+	After string   `yaml:",omitempty"`
+
+	// Capture all undefined fields and should be empty after loading
+	XXX map[string]interface{} `yaml:",inline"`
+}
+
 // Project includes all project configuration
 type Project struct {
 	ProjectName   string        `yaml:"project_name,omitempty"`
@@ -238,15 +369,24 @@ type Project struct {
 	Brew          Homebrew      `yaml:",omitempty"`
 	Builds        []Build       `yaml:",omitempty"`
 	Archive       Archive       `yaml:",omitempty"`
+	Archives      []Archive     `yaml:",omitempty"`
 	FPM           FPM           `yaml:",omitempty"`
 	Snapcraft     Snapcraft     `yaml:",omitempty"`
 	Snapshot      Snapshot      `yaml:",omitempty"`
 	Checksum      Checksum      `yaml:",omitempty"`
+	SBOM          SBOM          `yaml:"sbom,omitempty"`
+	Provenance    Provenance    `yaml:",omitempty"`
 	Dockers       []Docker      `yaml:",omitempty"`
 	Artifactories []Artifactory `yaml:",omitempty"`
 	Changelog     Changelog     `yaml:",omitempty"`
 	Dist          string        `yaml:",omitempty"`
 	Sign          Sign          `yaml:",omitempty"`
+	Plugins       []Plugin      `yaml:",omitempty"`
+	Before        GlobalHooks   `yaml:",omitempty"`
+	After         GlobalHooks   `yaml:",omitempty"`
+	Monorepo      Monorepo      `yaml:",omitempty"`
+	Retry         Retry         `yaml:",omitempty"`
+	Reproducible  bool          `yaml:",omitempty"`
 
 	// this is a hack ¯\_(ツ)_/¯
 	SingleBuild Build `yaml:"build,omitempty"`
@@ -258,6 +398,32 @@ type Project struct {
 	XXX map[string]interface{} `yaml:",inline"`
 }
 
+// AllArchives returns the archives to create, which are the archives list
+// or, if it is empty, the single archive section
+func (p Project) AllArchives() []Archive {
+	if len(p.Archives) > 0 {
+		return p.Archives
+	}
+	return []Archive{p.Archive}
+}
+
+// ArchiveFor returns the first archive that includes the binaries of the
+// build with the given ID, or the first archive if none does
+func (p Project) ArchiveFor(id string) Archive {
+	var archives = p.AllArchives()
+	for _, archive := range archives {
+		if len(archive.Builds) == 0 {
+			return archive
+		}
+		for _, build := range archive.Builds {
+			if build == id {
+				return archive
+			}
+		}
+	}
+	return archives[0]
+}
+
 // Load config file
 func Load(file string) (config Project, err error) {
 	f, err := os.Open(file)
@@ -288,13 +454,22 @@ func checkOverflows(config Project) error {
 	for i, ov := range config.Archive.FormatOverrides {
 		overflow.check(ov.XXX, fmt.Sprintf("archive.format_overrides[%d]", i))
 	}
+	for i, archive := range config.Archives {
+		overflow.check(archive.XXX, fmt.Sprintf("archives[%d]", i))
+		for j, ov := range archive.FormatOverrides {
+			overflow.check(ov.XXX, fmt.Sprintf("archives[%d].format_overrides[%d]", i, j))
+		}
+	}
 	overflow.check(config.Brew.XXX, "brew")
 	overflow.check(config.Brew.GitHub.XXX, "brew.github")
 	for i, build := range config.Builds {
 		overflow.check(build.XXX, fmt.Sprintf("builds[%d]", i))
 		overflow.check(build.Hooks.XXX, fmt.Sprintf("builds[%d].hooks", i))
 		for j, ignored := range build.Ignore {
-			overflow.check(ignored.XXX, fmt.Sprintf("builds[%d].ignored_builds[%d]", i, j))
+			overflow.check(ignored.XXX, fmt.Sprintf("builds[%d].ignore[%d]", i, j))
+		}
+		for j, override := range build.Overrides {
+			overflow.check(override.XXX, fmt.Sprintf("builds[%d].overrides[%d]", i, j))
 		}
 	}
 	overflow.check(config.FPM.XXX, "fpm")
@@ -302,20 +477,38 @@ func checkOverflows(config Project) error {
 	overflow.check(config.Release.XXX, "release")
 	overflow.check(config.Release.GitHub.XXX, "release.github")
 	overflow.check(config.SingleBuild.XXX, "build")
-	overflow.check(config.SingleBuild.Hooks.XXX, "builds.hooks")
+	overflow.check(config.SingleBuild.Hooks.XXX, "build.hooks")
 	for i, ignored := range config.SingleBuild.Ignore {
-		overflow.check(ignored.XXX, fmt.Sprintf("builds.ignored_builds[%d]", i))
+		overflow.check(ignored.XXX, fmt.Sprintf("build.ignore[%d]", i))
+	}
+	for i, override := range config.SingleBuild.Overrides {
+		overflow.check(override.XXX, fmt.Sprintf("build.overrides[%d]", i))
 	}
 	overflow.check(config.Snapshot.XXX, "snapshot")
 	overflow.check(config.Checksum.XXX, "checksum")
+	overflow.check(config.SBOM.XXX, "sbom")
+	overflow.check(config.Provenance.XXX, "provenance")
 	for i, docker := range config.Dockers {
-		overflow.check(docker.XXX, fmt.Sprintf("docker[%d]", i))
+		overflow.check(docker.XXX, fmt.Sprintf("dockers[%d]", i))
 	}
 	for i, artifactory := range config.Artifactories {
-		overflow.check(artifactory.XXX, fmt.Sprintf("artifactory[%d]", i))
+		overflow.check(artifactory.XXX, fmt.Sprintf("artifactories[%d]", i))
 	}
+	overflow.check(config.Monorepo.XXX, "monorepo")
+	overflow.check(config.Retry.XXX, "retry")
 	overflow.check(config.Changelog.XXX, "changelog")
 	overflow.check(config.Changelog.Filters.XXX, "changelog.filters")
+	for i, plugin := range config.Plugins {
+		overflow.check(plugin.XXX, fmt.Sprintf("plugins[%d]", i))
+	}
+	overflow.check(config.Before.XXX, "before")
+	for i, hook := range config.Before.Hooks {
+		overflow.check(hook.XXX, fmt.Sprintf("before.hooks[%d]", i))
+	}
+	overflow.check(config.After.XXX, "after")
+	for i, hook := range config.After.Hooks {
+		overflow.check(hook.XXX, fmt.Sprintf("after.hooks[%d]", i))
+	}
 	return overflow.err()
 }
 
@@ -337,8 +530,19 @@ func (o *overflowChecker) err() error {
 	if len(o.fields) == 0 {
 		return nil
 	}
-	return fmt.Errorf(
+	return UnknownFieldsError{Fields: o.fields}
+}
+
+// UnknownFieldsError happens when the config file has fields goreleaser
+// doesn't know about
+type UnknownFieldsError struct {
+	// Fields holds the path of each unknown field, e.g. `builds[0].foo`
+	Fields []string
+}
+
+func (e UnknownFieldsError) Error() string {
+	return fmt.Sprintf(
 		"unknown fields in the config file: %s",
-		strings.Join(o.fields, ", "),
+		strings.Join(e.Fields, ", "),
 	)
 }
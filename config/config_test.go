@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -35,6 +36,99 @@ fpm:
 	assert.Equal(t, "http://goreleaser.github.io", prop.FPM.Homepage, "yaml did not load correctly")
 }
 
+func TestLoadReaderHooks(t *testing.T) {
+	var conf = `
+before:
+  hooks:
+    - go generate ./...
+    - cmd: echo {{ .Tag }} > version.txt
+      shell: true
+      env:
+        - FOO=bar
+after:
+  hooks:
+    - cmd: ./notify.sh
+      ignore_errors: true
+`
+	prop, err := LoadReader(strings.NewReader(conf))
+	assert.NoError(t, err)
+	assert.Equal(t, []Hook{
+		{Cmd: "go generate ./..."},
+		{Cmd: "echo {{ .Tag }} > version.txt", Shell: true, Env: []string{"FOO=bar"}},
+	}, prop.Before.Hooks)
+	assert.Equal(t, []Hook{
+		{Cmd: "./notify.sh", IgnoreErrors: true},
+	}, prop.After.Hooks)
+}
+
+func TestLoadReaderArchives(t *testing.T) {
+	var conf = `
+builds:
+  - id: server
+    binary: server
+  - id: client
+    binary: client
+archives:
+  - name_template: "server_{{ .Os }}"
+    builds: [server]
+  - name_template: "client_{{ .Os }}"
+    builds: [client]
+    nope: true
+`
+	prop, err := LoadReader(strings.NewReader(conf))
+	assert.EqualError(t, err, "unknown fields in the config file: archives[1].nope")
+	assert.Equal(t, "server", prop.Builds[0].ID)
+	assert.Equal(t, []string{"client"}, prop.Archives[1].Builds)
+	assert.Equal(t, prop.Archives, prop.AllArchives())
+}
+
+func TestAllArchives(t *testing.T) {
+	var archive = Archive{Format: "zip"}
+	assert.Equal(t, []Archive{archive}, Project{Archive: archive}.AllArchives())
+}
+
+func TestArchiveFor(t *testing.T) {
+	var project = Project{
+		Archives: []Archive{
+			{Format: "tar.gz", Builds: []string{"server"}},
+			{Format: "zip", Builds: []string{"client", "agent"}},
+		},
+	}
+	assert.Equal(t, "tar.gz", project.ArchiveFor("server").Format)
+	assert.Equal(t, "zip", project.ArchiveFor("agent").Format)
+	assert.Equal(t, "tar.gz", project.ArchiveFor("other").Format)
+	assert.Equal(t, "zip", Project{Archive: Archive{Format: "zip"}}.ArchiveFor("server").Format)
+}
+
+func TestLoadReaderInvalidHook(t *testing.T) {
+	var conf = `
+before:
+  hooks:
+    - cmd: ls
+      shel: true
+`
+	_, err := LoadReader(strings.NewReader(conf))
+	assert.EqualError(t, err, "unknown fields in the config file: before.hooks[0].shel")
+}
+
+func TestLoadReaderRetry(t *testing.T) {
+	var conf = `
+retry:
+  attempts: 5
+  delay: 2s
+  max_delay: 1m
+  status_codes: [502, 503]
+`
+	prop, err := LoadReader(strings.NewReader(conf))
+	assert.NoError(t, err)
+	assert.Equal(t, Retry{
+		Attempts:    5,
+		Delay:       2 * time.Second,
+		MaxDelay:    time.Minute,
+		StatusCodes: []int{502, 503},
+	}, prop.Retry)
+}
+
 type errorReader struct{}
 
 func (errorReader) Read(p []byte) (n int, err error) {
@@ -59,7 +153,8 @@ func TestFileNotFound(t *testing.T) {
 
 func TestInvalidFields(t *testing.T) {
 	_, err := Load("testdata/invalid_config.yml")
-	assert.EqualError(t, err, "unknown fields in the config file: invalid_root, archive.invalid_archive, archive.format_overrides[0].invalid_archive_fmtoverrides, brew.invalid_brew, brew.github.invalid_brew_github, builds[0].invalid_builds, builds[0].hooks.invalid_builds_hooks, builds[0].ignored_builds[0].invalid_builds_ignore, fpm.invalid_fpm, release.invalid_release, release.github.invalid_release_github, build.invalid_build, builds.hooks.invalid_build_hook, builds.ignored_builds[0].invalid_build_ignore, snapshot.invalid_snapshot, docker[0].invalid_docker, artifactory[0].invalid_artifactory, changelog.invalid_changelog, changelog.filters.invalid_filters")
+	assert.IsType(t, UnknownFieldsError{}, err)
+	assert.EqualError(t, err, "unknown fields in the config file: invalid_root, archive.invalid_archive, archive.format_overrides[0].invalid_archive_fmtoverrides, brew.invalid_brew, brew.github.invalid_brew_github, builds[0].invalid_builds, builds[0].hooks.invalid_builds_hooks, builds[0].ignore[0].invalid_builds_ignore, fpm.invalid_fpm, release.invalid_release, release.github.invalid_release_github, build.invalid_build, build.hooks.invalid_build_hook, build.ignore[0].invalid_build_ignore, snapshot.invalid_snapshot, dockers[0].invalid_docker, artifactories[0].invalid_artifactory, changelog.invalid_changelog, changelog.filters.invalid_filters")
 }
 
 func TestInvalidYaml(t *testing.T) {
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Lines maps the path of each field of a config file, e.g.
+// `builds[0].goos[1]`, to the line it is at
+type Lines map[string]int
+
+var keyRe = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s:#'"][^:#]*?)\s*:(\s|$)`)
+
+// LinesOf finds the lines of the fields of the given YAML config file.
+// It only understands the block style used by config files, flow style
+// values like `[linux, darwin]` are located by their key.
+func LinesOf(data []byte) Lines {
+	type frame struct {
+		indent int
+		path   string
+		item   bool
+	}
+	var lines = Lines{}
+	var items = map[string]int{}
+	var stack = []frame{{indent: -1}}
+	var scanner = bufio.NewScanner(bytes.NewReader(data))
+	var number = 0
+	for scanner.Scan() {
+		number++
+		var line = scanner.Text()
+		var text = strings.TrimLeft(line, " ")
+		if text == "" || strings.HasPrefix(text, "#") || text == "---" {
+			continue
+		}
+		var indent = len(line) - len(text)
+		for strings.HasPrefix(text, "-") && (len(text) == 1 || text[1] == ' ') {
+			for len(stack) > 1 {
+				var top = stack[len(stack)-1]
+				if top.indent < indent || (top.indent == indent && !top.item) {
+					break
+				}
+				stack = stack[:len(stack)-1]
+			}
+			var owner = stack[len(stack)-1].path
+			var path = fmt.Sprintf("%s[%d]", owner, items[owner])
+			items[owner]++
+			set(lines, path, number)
+			stack = append(stack, frame{indent: indent, path: path, item: true})
+			var rest = strings.TrimLeft(text[1:], " ")
+			indent += len(text) - len(rest)
+			text = rest
+		}
+		var match = keyRe.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		var key = strings.Trim(match[1], `"'`)
+		var path = key
+		if parent := stack[len(stack)-1].path; parent != "" {
+			path = parent + "." + key
+		}
+		set(lines, path, number)
+		stack = append(stack, frame{indent: indent, path: path})
+	}
+	return lines
+}
+
+func set(lines Lines, path string, number int) {
+	if _, ok := lines[path]; !ok {
+		lines[path] = number
+	}
+}
+
+// Line returns the line of the given path. If the path is not in the file,
+// e.g. because it was set by a default, the line of its closest parent is
+// returned instead. It returns 0 if no parent is in the file either.
+func (l Lines) Line(path string) int {
+	for path != "" {
+		if line, ok := l[path]; ok {
+			return line
+		}
+		var i = strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			return 0
+		}
+		path = path[:i]
+	}
+	return 0
+}
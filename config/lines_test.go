@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const linesYaml = `# comment
+project_name: foo
+builds:
+- binary: foo
+  goos:
+    - linux
+    - darwin
+  hooks:
+    pre: make
+
+- binary: bar
+  goarch: [amd64]
+archive:
+  format_overrides:
+    -
+      goos: windows
+      format: zip
+  files:
+  - README.md
+dockers:
+  - image: foo/bar
+    "tag_template": "{{ .Tag }}"
+`
+
+func TestLinesOf(t *testing.T) {
+	var lines = LinesOf([]byte(linesYaml))
+	for path, line := range map[string]int{
+		"project_name":                       2,
+		"builds":                             3,
+		"builds[0]":                          4,
+		"builds[0].binary":                   4,
+		"builds[0].goos":                     5,
+		"builds[0].goos[0]":                  6,
+		"builds[0].goos[1]":                  7,
+		"builds[0].hooks.pre":                9,
+		"builds[1].binary":                   11,
+		"builds[1].goarch":                   12,
+		"archive.format_overrides[0]":        15,
+		"archive.format_overrides[0].goos":   16,
+		"archive.format_overrides[0].format": 17,
+		"archive.files[0]":                   19,
+		"dockers[0].image":                   21,
+		"dockers[0].tag_template":            22,
+	} {
+		assert.Equal(t, line, lines[path], path)
+	}
+}
+
+func TestLine(t *testing.T) {
+	var lines = LinesOf([]byte(linesYaml))
+	assert.Equal(t, 12, lines.Line("builds[1].goarch[0]"))
+	assert.Equal(t, 11, lines.Line("builds[1].ldflags"))
+	assert.Equal(t, 13, lines.Line("archive.name_template"))
+	assert.Equal(t, 0, lines.Line("sign.artifacts"))
+}
@@ -8,24 +8,26 @@ package context
 
 import (
 	ctx "context"
+	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/semver"
 )
 
 // GitInfo includes tags and diffs used in some point
 type GitInfo struct {
-	CurrentTag string
-	Commit     string
-}
-
-// Binary with pretty name and path
-type Binary struct {
-	Name, Path string
+	CurrentTag  string `json:"current_tag"`
+	PreviousTag string `json:"previous_tag,omitempty"`
+	Commit      string `json:"commit"`
+	// CommitDate is the committer date of the released commit
+	CommitDate time.Time `json:"commit_date"`
+	// URL of the `origin` remote, without credentials, if there is one
+	URL string `json:"url,omitempty"`
 }
 
 // Context carries along some data through the pipes
@@ -33,87 +35,131 @@ type Context struct {
 	ctx.Context
 	Config       config.Project
 	Env          map[string]string
+	Date         time.Time
 	Token        string
 	Git          GitInfo
-	Binaries     map[string]map[string][]Binary
-	Artifacts    []string
-	Checksums    []string
-	Dockers      []string
+	Artifacts    artifact.Artifacts
 	ReleaseNotes string
 	Version      string
+	Semver       semver.Version
 	Validate     bool
 	Publish      bool
 	Snapshot     bool
 	RmDist       bool
+	DryRun       bool
 	Debug        bool
 	Parallelism  int
+
+	undo      []UndoAction
+	published bool
+	undoLock  sync.Mutex
 }
 
-var (
-	artifactsLock sync.Mutex
-	checksumsLock sync.Mutex
-	dockersLock   sync.Mutex
-	binariesLock  sync.Mutex
-)
+// UndoAction reverts something that a pipe published
+type UndoAction struct {
+	Description string
+	Func        func(ctx *Context) error
+	// release is true if the action reverts the release itself, which is
+	// not done anymore once the release is published
+	release bool
+}
+
+// New context
+func New(config config.Project) *Context {
+	return &Context{
+		Context:     ctx.Background(),
+		Config:      config,
+		Env:         splitEnv(os.Environ()),
+		Date:        time.Now(),
+		Parallelism: 4,
+	}
+}
 
-// AddArtifact adds a file to upload list
-func (ctx *Context) AddArtifact(file string) {
-	artifactsLock.Lock()
-	defer artifactsLock.Unlock()
-	file = strings.TrimPrefix(file, ctx.Config.Dist+string(filepath.Separator))
-	ctx.Artifacts = append(ctx.Artifacts, file)
-	log.WithField("artifact", file).Info("new release artifact")
+// NewWithTimeout new context with the given timeout. The returned cancel
+// func must be called to release its resources.
+func NewWithTimeout(config config.Project, timeout time.Duration) (*Context, ctx.CancelFunc) {
+	var c = New(config)
+	var cancel ctx.CancelFunc
+	c.Context, cancel = ctx.WithTimeout(c.Context, timeout)
+	return c, cancel
 }
 
-// AddChecksum adds a checksum file.
-func (ctx *Context) AddChecksum(file string) {
-	checksumsLock.Lock()
-	defer checksumsLock.Unlock()
-	file = strings.TrimPrefix(file, ctx.Config.Dist+string(filepath.Separator))
-	ctx.Checksums = append(ctx.Checksums, file)
-	log.WithField("checksum", file).Info("new checksum file")
+// PreRelease returns true if the release should be marked as a
+// prerelease, either because release.prerelease is true or because it is
+// auto and the version has a prerelease part, e.g. 1.2.0-rc.1
+func (c *Context) PreRelease() bool {
+	switch c.Config.Release.Prerelease {
+	case "true":
+		return true
+	case "auto":
+		return c.Semver.IsPrerelease()
+	}
+	return false
+}
+
+// SourceDate returns the date the build outputs should carry so they are
+// reproducible: the commit date, or the release date if it is unknown
+func (c *Context) SourceDate() time.Time {
+	if c.Git.CommitDate.IsZero() {
+		return c.Date
+	}
+	return c.Git.CommitDate
 }
 
-// AddDocker adds a docker image to the docker images list
-func (ctx *Context) AddDocker(image string) {
-	dockersLock.Lock()
-	defer dockersLock.Unlock()
-	ctx.Dockers = append(ctx.Dockers, image)
-	log.WithField("image", image).Info("new docker image")
+// SourceDateEpoch returns the SOURCE_DATE_EPOCH environment variable set
+// to the source date, as described in
+// https://reproducible-builds.org/specs/source-date-epoch/
+func (c *Context) SourceDateEpoch() string {
+	return fmt.Sprintf("SOURCE_DATE_EPOCH=%d", c.SourceDate().Unix())
 }
 
-// AddBinary adds a built binary to the current context
-func (ctx *Context) AddBinary(platform, folder, name, path string) {
-	binariesLock.Lock()
-	defer binariesLock.Unlock()
-	if ctx.Binaries == nil {
-		ctx.Binaries = map[string]map[string][]Binary{}
+// Undo records an action that reverts something a pipe published, so it
+// can be rolled back if the release fails later on.
+// It is safe for concurrent use.
+func (c *Context) Undo(description string, fn func(ctx *Context) error) {
+	c.undoLock.Lock()
+	defer c.undoLock.Unlock()
+	c.undo = append(c.undo, UndoAction{Description: description, Func: fn})
+}
+
+// UndoRelease records an action that reverts the release itself, e.g.
+// deletes it or its assets, until it is Published.
+// It is safe for concurrent use.
+func (c *Context) UndoRelease(description string, fn func(ctx *Context) error) {
+	c.undoLock.Lock()
+	defer c.undoLock.Unlock()
+	if c.published {
+		return
 	}
-	if ctx.Binaries[platform] == nil {
-		ctx.Binaries[platform] = map[string][]Binary{}
+	c.undo = append(c.undo, UndoAction{Description: description, Func: fn, release: true})
+}
+
+// Published marks the point of no return of the release: once it is
+// public, the release itself isn't rolled back anymore. Its undo actions
+// recorded so far are dropped, and the later ones are ignored, while the
+// ones of the other pipes, e.g. brew or artifactory, are kept.
+func (c *Context) Published() {
+	c.undoLock.Lock()
+	defer c.undoLock.Unlock()
+	c.published = true
+	var kept []UndoAction
+	for _, action := range c.undo {
+		if !action.release {
+			kept = append(kept, action)
+		}
 	}
-	ctx.Binaries[platform][folder] = append(
-		ctx.Binaries[platform][folder],
-		Binary{
-			Name: name,
-			Path: path,
-		},
-	)
-	log.WithField("platform", platform).
-		WithField("folder", folder).
-		WithField("name", name).
-		WithField("path", path).
-		Debug("new binary")
+	c.undo = kept
 }
 
-// New context
-func New(config config.Project) *Context {
-	return &Context{
-		Context:     ctx.Background(),
-		Config:      config,
-		Env:         splitEnv(os.Environ()),
-		Parallelism: 4,
+// UndoActions returns the recorded undo actions, the most recent first
+func (c *Context) UndoActions() []UndoAction {
+	c.undoLock.Lock()
+	defer c.undoLock.Unlock()
+	var result = make([]UndoAction, 0, len(c.undo))
+	for i := len(c.undo) - 1; i >= 0; i-- {
+		result = append(result, c.undo[i])
 	}
+	return result
 }
 
 func splitEnv(env []string) map[string]string {
@@ -1,84 +1,103 @@
 package context
 
 import (
+	"os"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/internal/semver"
 	"github.com/stretchr/testify/assert"
-	"golang.org/x/sync/errgroup"
 )
 
-func TestMultipleAdds(t *testing.T) {
-	var artifacts = []string{
-		"dist/a",
-		"dist/b",
-		"dist/c",
-		"dist/d",
-	}
-	var checksums = []string{
-		"dist/a.sha256",
-	}
-	var dockerfiles = []string{
-		"a/b:1.0.0",
-		"c/d:2.0.0",
-		"e/f:3.0.0",
-	}
+func TestNew(t *testing.T) {
+	assert.NoError(t, os.Setenv("FOO", "NOT BAR"))
+	assert.NoError(t, os.Setenv("BAR", "1"))
 	var ctx = New(config.Project{
 		Dist: "dist",
 	})
-	var g errgroup.Group
-	for _, f := range artifacts {
-		f := f
-		g.Go(func() error {
-			ctx.AddArtifact(f)
-			return nil
+	assert.Equal(t, "NOT BAR", ctx.Env["FOO"])
+	assert.Equal(t, "1", ctx.Env["BAR"])
+	assert.Equal(t, 4, ctx.Parallelism)
+	assert.Equal(t, "dist", ctx.Config.Dist)
+	assert.Empty(t, ctx.Artifacts.List())
+	assert.False(t, ctx.Date.IsZero())
+}
+
+func TestNewWithTimeout(t *testing.T) {
+	ctx, cancel := NewWithTimeout(config.Project{}, time.Second)
+	assert.NotEmpty(t, ctx.Env)
+	assert.NoError(t, ctx.Err())
+	cancel()
+	<-ctx.Done()
+	assert.Error(t, ctx.Err())
+}
+
+func TestPreRelease(t *testing.T) {
+	var rc = semver.Version{Major: 1, Minor: 2, Prerelease: "rc.1"}
+	var final = semver.Version{Major: 1, Minor: 2}
+	for _, tt := range []struct {
+		prerelease string
+		semver     semver.Version
+		expected   bool
+	}{
+		{"", rc, false},
+		{"false", rc, false},
+		{"true", final, true},
+		{"auto", final, false},
+		{"auto", rc, true},
+	} {
+		var ctx = New(config.Project{
+			Release: config.Release{Prerelease: tt.prerelease},
 		})
+		ctx.Semver = tt.semver
+		assert.Equal(t, tt.expected, ctx.PreRelease(), tt.prerelease+" "+tt.semver.String())
 	}
-	assert.NoError(t, g.Wait())
-	for _, c := range checksums {
-		c := c
-		g.Go(func() error {
-			ctx.AddChecksum(c)
+}
+
+func TestSourceDate(t *testing.T) {
+	var ctx = New(config.Project{})
+	ctx.Date = time.Unix(2000, 0)
+	assert.Equal(t, ctx.Date, ctx.SourceDate())
+	assert.Equal(t, "SOURCE_DATE_EPOCH=2000", ctx.SourceDateEpoch())
+	ctx.Git.CommitDate = time.Unix(1000, 0)
+	assert.Equal(t, ctx.Git.CommitDate, ctx.SourceDate())
+	assert.Equal(t, "SOURCE_DATE_EPOCH=1000", ctx.SourceDateEpoch())
+}
+
+func TestUndoActions(t *testing.T) {
+	var ctx = New(config.Project{})
+	assert.Empty(t, ctx.UndoActions())
+	var undone []string
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		ctx.Undo("undo "+name, func(ctx *Context) error {
+			undone = append(undone, name)
 			return nil
 		})
 	}
-	assert.NoError(t, g.Wait())
-	for _, d := range dockerfiles {
-		d := d
-		g.Go(func() error {
-			ctx.AddDocker(d)
-			return nil
-		})
+	var actions = ctx.UndoActions()
+	assert.Len(t, actions, 3)
+	assert.Equal(t, "undo third", actions[0].Description)
+	for _, action := range actions {
+		assert.NoError(t, action.Func(ctx))
 	}
-	assert.NoError(t, g.Wait())
-	assert.Len(t, ctx.Artifacts, len(artifacts))
-	assert.Contains(t, ctx.Artifacts, "a", "b", "c", "d")
-	assert.Len(t, ctx.Checksums, len(checksums))
-	assert.Contains(t, ctx.Checksums, "a.sha256")
-	assert.Len(t, ctx.Dockers, len(dockerfiles))
-	assert.Contains(t, ctx.Dockers, "a/b:1.0.0", "c/d:2.0.0", "e/f:3.0.0")
+	assert.Equal(t, []string{"third", "second", "first"}, undone)
 }
 
-func TestMultipleBinaryAdds(t *testing.T) {
-	var list = map[string]string{
-		"a": "folder/a",
-		"b": "folder/b",
-		"c": "folder/c",
-		"d": "folder/d",
+func TestPublished(t *testing.T) {
+	var ctx = New(config.Project{})
+	var noop = func(ctx *Context) error {
+		return nil
 	}
-	var ctx = New(config.Project{
-		Dist: "dist",
-	})
-	var g errgroup.Group
-	for k, f := range list {
-		f := f
-		k := k
-		g.Go(func() error {
-			ctx.AddBinary("linuxamd64", k, k, f)
-			return nil
-		})
+	ctx.Undo("undo first", noop)
+	ctx.UndoRelease("delete release", noop)
+	ctx.Published()
+	ctx.UndoRelease("delete asset", noop)
+	ctx.Undo("undo second", noop)
+	var descriptions []string
+	for _, action := range ctx.UndoActions() {
+		descriptions = append(descriptions, action.Description)
 	}
-	assert.NoError(t, g.Wait())
-	assert.Len(t, ctx.Binaries["linuxamd64"], len(list))
-	assert.Len(t, ctx.Binaries, 1)
+	assert.Equal(t, []string{"undo second", "undo first"}, descriptions)
 }
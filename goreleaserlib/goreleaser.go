@@ -1,15 +1,28 @@
 package goreleaserlib
 
 import (
+	"bytes"
+	stdctx "context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/cli"
+	"github.com/goreleaser/goreleaser/checksum"
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/buildtarget"
+	"github.com/goreleaser/goreleaser/internal/check"
 	"github.com/goreleaser/goreleaser/pipeline"
 	"github.com/goreleaser/goreleaser/pipeline/archive"
 	"github.com/goreleaser/goreleaser/pipeline/artifactory"
@@ -23,8 +36,14 @@ import (
 	"github.com/goreleaser/goreleaser/pipeline/effectiveconfig"
 	"github.com/goreleaser/goreleaser/pipeline/env"
 	"github.com/goreleaser/goreleaser/pipeline/fpm"
+	"github.com/goreleaser/goreleaser/pipeline/fromdist"
 	"github.com/goreleaser/goreleaser/pipeline/git"
+	"github.com/goreleaser/goreleaser/pipeline/hooks"
+	"github.com/goreleaser/goreleaser/pipeline/metadata"
+	"github.com/goreleaser/goreleaser/pipeline/plugin"
+	"github.com/goreleaser/goreleaser/pipeline/provenance"
 	"github.com/goreleaser/goreleaser/pipeline/release"
+	"github.com/goreleaser/goreleaser/pipeline/sbom"
 	"github.com/goreleaser/goreleaser/pipeline/sign"
 	"github.com/goreleaser/goreleaser/pipeline/snapcraft"
 	yaml "gopkg.in/yaml.v2"
@@ -46,24 +65,60 @@ var pipes = []pipeline.Piper{
 	effectiveconfig.Pipe{}, // writes the actual config (with defaults et al set) to dist
 	changelog.Pipe{},       // builds the release changelog
 	env.Pipe{},             // load and validate environment variables
+	hooks.BeforePipe{},     // run global before hooks
 	build.Pipe{},           // build
 	archive.Pipe{},         // archive (tar.gz, zip, etc)
-	fpm.Pipe{},             // archive via fpm (deb, rpm, etc)
+	fpm.Pipe{},             // linux packages (deb, rpm, apk, etc)
 	snapcraft.Pipe{},       // archive via snapcraft (snap)
+	sbom.Pipe{},            // software bills of materials
 	checksums.Pipe{},       // checksums of the files
+	provenance.Pipe{},      // provenance of the release
 	sign.Pipe{},            // sign artifacts
 	docker.Pipe{},          // create and push docker images
 	artifactory.Pipe{},     // push to artifactory
 	release.Pipe{},         // release to github
 	brew.Pipe{},            // push to brew tap
+	hooks.AfterPipe{},      // run global after hooks
 }
 
+var publishPipes = []pipeline.Piper{
+	fromdist.Pipe{},      // load the artifacts and metadata of a previous run
+	changelog.Pipe{},     // builds the release changelog
+	env.Pipe{},           // load and validate environment variables
+	docker.PublishPipe{}, // push docker images
+	artifactory.Pipe{},   // push to artifactory
+	release.Pipe{},       // release to github
+	brew.Pipe{},          // push to brew tap
+	hooks.AfterPipe{},    // run global after hooks
+}
+
+var rebuildPipes = []pipeline.Piper{
+	defaults.Pipe{},    // load default configs
+	git.Pipe{},         // get and validate git repo state
+	hooks.BeforePipe{}, // run global before hooks
+	build.Pipe{},       // build
+	archive.Pipe{},     // archive (tar.gz, zip, etc)
+	sbom.Pipe{},        // software bills of materials
+}
+
+// rebuiltTypes are the types of the artifacts compared by
+// VerifyReproducible. The checksums file isn't, as it also lists artifacts
+// that aren't rebuilt, e.g. linux packages and snaps, but the checksums of
+// the rebuilt artifacts are compared one by one.
+var rebuiltTypes = artifact.Or(
+	artifact.ByType(artifact.Binary),
+	artifact.ByType(artifact.UploadableArchive),
+	artifact.ByType(artifact.UploadableBinary),
+	artifact.ByType(artifact.SBOM),
+)
+
 // Flags interface represents an extractor of cli flags
 type Flags interface {
 	IsSet(s string) bool
 	String(s string) string
 	Int(s string) int
 	Bool(s string) bool
+	Duration(s string) time.Duration
 }
 
 // Release runs the release process with the given flags
@@ -83,20 +138,15 @@ func Release(flags Flags) error {
 		}
 		log.WithField("file", file).Warn("could not load config, using defaults")
 	}
-	var ctx = context.New(cfg)
+	ctx, cancel := newContext(cfg, flags.Duration("timeout"))
+	defer cancel()
 	ctx.Parallelism = flags.Int("parallelism")
 	ctx.Debug = flags.Bool("debug")
 	log.Debugf("parallelism: %v", ctx.Parallelism)
 	ctx.Validate = !flags.Bool("skip-validate")
 	ctx.Publish = !flags.Bool("skip-publish")
-	if notes != "" {
-		bts, err := ioutil.ReadFile(notes)
-		if err != nil {
-			return err
-		}
-		log.WithField("file", notes).Info("loaded custom release notes")
-		log.WithField("file", notes).Debugf("custon release notes: \n%s", string(bts))
-		ctx.ReleaseNotes = string(bts)
+	if err := loadReleaseNotes(ctx, notes); err != nil {
+		return err
 	}
 	ctx.Snapshot = flags.Bool("snapshot")
 	if ctx.Snapshot {
@@ -104,11 +154,167 @@ func Release(flags Flags) error {
 		ctx.Publish = false
 	}
 	ctx.RmDist = flags.Bool("rm-dist")
+	ctx.DryRun = flags.Bool("dry-run")
+	if ctx.DryRun {
+		log.Info("dry-run mode: external commands and uploads will only be logged")
+	}
+	withPlugins, missing := plugin.Insert(pipes, ctx.Config.Plugins)
+	if len(missing) > 0 {
+		return fmt.Errorf("plugin %s: there is no pipe with ID %s to run after", missing[0].Name, missing[0].After)
+	}
+	return run(ctx, flags, withPlugins)
+}
+
+// Publish publishes the artifacts of a previous run, loaded from the dist
+// folder, without building them again
+func Publish(flags Flags) error {
+	if !flags.Bool("from-dist") {
+		return fmt.Errorf("publish currently only works with --from-dist")
+	}
+	if flags.Bool("debug") {
+		log.SetLevel(log.DebugLevel)
+	}
+	var dist = flags.String("dist")
+	cfg, err := config.Load(filepath.Join(dist, effectiveconfig.Filename))
+	if err != nil {
+		return err
+	}
+	cfg.Dist = dist
+	ctx, cancel := newContext(cfg, flags.Duration("timeout"))
+	defer cancel()
+	ctx.Parallelism = flags.Int("parallelism")
+	ctx.Debug = flags.Bool("debug")
+	ctx.Validate = true
+	ctx.Publish = true
+	ctx.DryRun = flags.Bool("dry-run")
+	if err := loadReleaseNotes(ctx, flags.String("release-notes")); err != nil {
+		return err
+	}
+	withPlugins, missing := plugin.Insert(publishPipes, ctx.Config.Plugins)
+	for _, p := range missing {
+		log.WithField("plugin", p.Name).
+			WithField("after", p.After).
+			Debug("plugin doesn't run on publish")
+	}
+	return run(ctx, flags, withPlugins)
+}
+
+// VerifyReproducible builds the release found in the dist folder again, in
+// a temporary folder, and checks that the binaries and archives are the same
+func VerifyReproducible(flags Flags) error {
+	if flags.Bool("debug") {
+		log.SetLevel(log.DebugLevel)
+	}
+	var dist = flags.String("dist")
+	cfg, err := config.Load(filepath.Join(dist, effectiveconfig.Filename))
+	if err != nil {
+		return err
+	}
+	if !cfg.Reproducible {
+		return fmt.Errorf("reproducible is not enabled in the config of %s", dist)
+	}
+	var info metadata.Metadata
+	if err := readJSON(filepath.Join(dist, metadata.MetadataFile), &info); err != nil {
+		return err
+	}
+	var original []metadata.Artifact
+	if err := readJSON(filepath.Join(dist, metadata.ArtifactsFile), &original); err != nil {
+		return err
+	}
+	cfg.Dist, err = ioutil.TempDir("", "goreleaser-verify")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(cfg.Dist) // nolint: errcheck
+	ctx, cancel := newContext(cfg, flags.Duration("timeout"))
+	defer cancel()
+	ctx.Parallelism = flags.Int("parallelism")
+	ctx.Debug = flags.Bool("debug")
+	ctx.Validate = false
+	ctx.Publish = false
+	for _, pipe := range rebuildPipes {
+		if err := runPipe(ctx, pipe); err != nil {
+			return err
+		}
+		if _, ok := pipe.(git.Pipe); ok && ctx.Git.Commit != info.Commit {
+			return fmt.Errorf(
+				"dist commit %s doesn't match the current git commit %s",
+				info.Commit, ctx.Git.Commit,
+			)
+		}
+	}
+	cli.Default.Padding = normalPadding
+	return compareArtifacts(original, ctx.Artifacts.Filter(rebuiltTypes).List())
+}
+
+// compareArtifacts compares the SHA256 of the rebuilt artifacts with the
+// ones of the original artifacts with the same type, platform and name
+func compareArtifacts(original []metadata.Artifact, rebuilt []artifact.Artifact) error {
+	var key = func(a artifact.Artifact) string {
+		return strings.Join([]string{a.Type.String(), a.Platform(), a.Name}, "/")
+	}
+	var sums = map[string]string{}
+	for _, a := range original {
+		sums[key(a.Artifact)] = a.SHA256
+	}
+	var mismatches int
+	for _, a := range rebuilt {
+		var log = log.WithField("artifact", a.Name).WithField("type", a.Type)
+		expected, ok := sums[key(a)]
+		if !ok {
+			log.Warn("not found in dist, ignoring")
+			continue
+		}
+		sha, err := checksum.SHA256(a.Path)
+		if err != nil {
+			return err
+		}
+		if sha != expected {
+			mismatches++
+			log.WithField("expected", expected).
+				WithField("got", sha).
+				Error("not reproducible")
+			continue
+		}
+		log.Info("reproducible")
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("%d artifact(s) are not reproducible", mismatches)
+	}
+	return nil
+}
+
+func readJSON(path string, v interface{}) error {
+	bts, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bts, v)
+}
+
+func run(ctx *context.Context, flags Flags, pipes []pipeline.Piper) error {
+	pipes, err := pipeline.Select(
+		pipes,
+		pipeline.SplitIDs(flags.String("only")),
+		pipeline.SplitIDs(flags.String("skip")),
+	)
+	if err != nil {
+		return err
+	}
+	// the artifacts and metadata files are written along with the effective
+	// config, and then after each pipe, so they are there even if the
+	// release fails, e.g. to publish it later with `goreleaser publish`
+	var written bool
 	for _, pipe := range pipes {
-		cli.Default.Padding = normalPadding
-		log.Infof("\033[1m%s\033[0m", strings.ToUpper(pipe.String()))
-		cli.Default.Padding = increasedPadding
-		if err := handle(pipe.Run(ctx)); err != nil {
+		var err = runPipe(ctx, pipe)
+		if _, ok := pipe.(effectiveconfig.Pipe); ok && err == nil {
+			written = true
+		}
+		if written {
+			err = writeMetadata(ctx, err)
+		}
+		if err != nil {
+			rollback(ctx)
 			return err
 		}
 	}
@@ -116,6 +322,119 @@ func Release(flags Flags) error {
 	return nil
 }
 
+// writeMetadata writes the artifacts and metadata files, and returns the
+// error of the pipe that just ran, if any, or the one of the writing
+func writeMetadata(ctx *context.Context, err error) error {
+	var werr = metadata.Write(ctx)
+	if err == nil {
+		return werr
+	}
+	if werr != nil {
+		log.WithError(werr).Warn("failed to write the artifacts metadata")
+	}
+	return err
+}
+
+func runPipe(ctx *context.Context, pipe pipeline.Piper) error {
+	if err := cancelled(ctx); err != nil {
+		return err
+	}
+	cli.Default.Padding = normalPadding
+	log.Infof("\033[1m%s\033[0m", strings.ToUpper(pipe.String()))
+	cli.Default.Padding = increasedPadding
+	if err := handle(pipe.Run(ctx)); err != nil {
+		if cerr := cancelled(ctx); cerr != nil {
+			return cerr
+		}
+		return err
+	}
+	return nil
+}
+
+// rollbackTimeout is how long the rollback of a failed release may take
+const rollbackTimeout = 5 * time.Minute
+
+// rollback runs the undo actions recorded by the pipes, reverting what was
+// published before the release failed. It gets its own context, as the
+// release context may have been cancelled or timed out.
+func rollback(ctx *context.Context) {
+	var actions = ctx.UndoActions()
+	if len(actions) == 0 {
+		return
+	}
+	cli.Default.Padding = normalPadding
+	log.Infof("\033[1m%s\033[0m", "ROLLING BACK")
+	cli.Default.Padding = increasedPadding
+	defer func() {
+		cli.Default.Padding = normalPadding
+	}()
+	var cancel stdctx.CancelFunc
+	ctx.Context, cancel = stdctx.WithTimeout(stdctx.Background(), rollbackTimeout)
+	defer cancel()
+	for _, action := range actions {
+		if err := action.Func(ctx); err != nil {
+			log.WithError(err).Errorf("failed to %s", action.Description)
+			continue
+		}
+		log.Info(action.Description)
+	}
+}
+
+// newContext creates a context which is cancelled once the timeout expires
+// or when goreleaser gets a SIGINT or SIGTERM. A zero timeout disables the
+// timeout.
+func newContext(cfg config.Project, timeout time.Duration) (*context.Context, func()) {
+	var ctx *context.Context
+	var cancel stdctx.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.NewWithTimeout(cfg, timeout)
+	} else {
+		ctx = context.New(cfg)
+		ctx.Context, cancel = stdctx.WithCancel(ctx.Context)
+	}
+	var signals = make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			log.WithField("signal", sig).Warn("cancelling...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// cancelled returns a meaningful error if the context was cancelled,
+// nil otherwise
+func cancelled(ctx *context.Context) error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+	case stdctx.DeadlineExceeded:
+		return fmt.Errorf("release timed out")
+	default:
+		return fmt.Errorf("release cancelled")
+	}
+}
+
+func loadReleaseNotes(ctx *context.Context, notes string) error {
+	if notes == "" {
+		return nil
+	}
+	bts, err := ioutil.ReadFile(notes)
+	if err != nil {
+		return err
+	}
+	log.WithField("file", notes).Info("loaded custom release notes")
+	log.WithField("file", notes).Debugf("custon release notes: \n%s", string(bts))
+	ctx.ReleaseNotes = string(bts)
+	return nil
+}
+
 func handle(err error) error {
 	if err == nil {
 		return nil
@@ -127,6 +446,91 @@ func handle(err error) error {
 	return err
 }
 
+// Check loads the config file, applies the defaults and reports all the
+// problems found in it, with their file and line
+func Check(flags Flags) error {
+	var file = getConfigFile(flags)
+	bts, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	problems, err := checkConfig(file, bts)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		log.WithField("file", file).Info("config is valid")
+		return nil
+	}
+	for _, problem := range problems {
+		log.Error(problem)
+	}
+	return fmt.Errorf("%d problem(s) found in %s", len(problems), file)
+}
+
+// checkConfig returns the problems of the given config file, formatted as
+// `file:line: path: problem`
+func checkConfig(file string, bts []byte) ([]string, error) {
+	var problems []check.Problem
+	cfg, err := config.LoadReader(bytes.NewReader(bts))
+	if unknown, ok := err.(config.UnknownFieldsError); ok {
+		for _, field := range unknown.Fields {
+			problems = append(problems, check.Problem{Path: field, Err: fmt.Errorf("unknown field")})
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	var ctx = context.New(cfg)
+	if err := (defaults.Pipe{}).Run(ctx); err != nil {
+		problems = append(problems, check.Problem{Err: err})
+	}
+	problems = append(problems, check.Config(ctx)...)
+	var lines = config.LinesOf(bts)
+	sort.SliceStable(problems, func(i, j int) bool {
+		return lines.Line(problems[i].Path) < lines.Line(problems[j].Path)
+	})
+	var result []string
+	for _, problem := range problems {
+		var position = file
+		if line := lines.Line(problem.Path); line > 0 {
+			position = fmt.Sprintf("%s:%d", file, line)
+		}
+		result = append(result, fmt.Sprintf("%s: %s", position, problem.Error()))
+	}
+	return result, nil
+}
+
+// Targets loads the config file, applies the defaults and prints the
+// targets each build is made for
+func Targets(flags Flags) error {
+	var file = getConfigFile(flags)
+	cfg, err := config.Load(file)
+	if err != nil {
+		_, statErr := os.Stat(file)
+		if !os.IsNotExist(statErr) || flags.IsSet("config") {
+			return err
+		}
+		log.WithField("file", file).Warn("could not load config, using defaults")
+	}
+	var ctx = context.New(cfg)
+	if err := (defaults.Pipe{}).Run(ctx); err != nil {
+		return err
+	}
+	printTargets(os.Stdout, ctx.Config.Builds)
+	return nil
+}
+
+// printTargets prints the ID of each build followed by its targets, one
+// per line
+func printTargets(w io.Writer, builds []config.Build) {
+	for _, build := range builds {
+		fmt.Fprintf(w, "%s:\n", build.ID)
+		for _, target := range buildtarget.All(build) {
+			fmt.Fprintf(w, "  %s\n", target.PrettyString())
+		}
+	}
+}
+
 // InitProject creates an example goreleaser.yml in the current directory
 func InitProject(filename string) error {
 	if _, err := os.Stat(filename); !os.IsNotExist(err) {
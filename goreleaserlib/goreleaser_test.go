@@ -1,14 +1,23 @@
 package goreleaserlib
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pipeline"
+	"github.com/goreleaser/goreleaser/pipeline/env"
+	"github.com/goreleaser/goreleaser/pipeline/metadata"
 	"github.com/stretchr/testify/assert"
 	yaml "gopkg.in/yaml.v2"
 )
@@ -31,6 +40,21 @@ func TestRelease(t *testing.T) {
 	assert.NoError(t, Release(flags))
 }
 
+func TestReleaseTimeout(t *testing.T) {
+	folder, back := setup(t)
+	defer back()
+	var flags = fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"timeout":      "1ns",
+			"parallelism":  "4",
+		},
+	}
+	assert.EqualError(t, Release(flags), "release timed out")
+	_, err := os.Stat(filepath.Join(folder, "dist"))
+	assert.True(t, os.IsNotExist(err))
+}
+
 func TestSnapshotRelease(t *testing.T) {
 	_, back := setup(t)
 	defer back()
@@ -43,6 +67,459 @@ func TestSnapshotRelease(t *testing.T) {
 	assert.NoError(t, Release(flags))
 }
 
+func TestReleaseDryRun(t *testing.T) {
+	folder, back := setup(t)
+	defer back()
+	var token = os.Getenv("GITHUB_TOKEN")
+	defer func() {
+		assert.NoError(t, os.Setenv("GITHUB_TOKEN", token))
+	}()
+	assert.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
+	var flags = fakeFlags{
+		flags: map[string]string{
+			"dry-run":     "true",
+			"parallelism": "4",
+		},
+	}
+	assert.NoError(t, Release(flags))
+	_, err := os.Stat(filepath.Join(folder, "dist", "fake_0.0.2_linux_amd64.tar.gz"))
+	assert.True(t, os.IsNotExist(err))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "dist", "artifacts.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bts), "fake_0.0.2_linux_amd64.tar.gz")
+}
+
+func TestReleaseWithPlugin(t *testing.T) {
+	folder, back := setup(t)
+	defer back()
+	createFile(t, "plugin.sh", "#!/bin/sh\necho foo > dist/fake.txt\necho '{\"artifacts\":[{\"name\":\"fake.txt\",\"path\":\"dist/fake.txt\",\"type\":\"archive\"}]}'\n")
+	assert.NoError(t, os.Chmod(filepath.Join(folder, "plugin.sh"), 0755))
+	createFile(t, "goreleaser.yml", `build:
+  binary: fake
+  goos:
+    - linux
+  goarch:
+    - amd64
+plugins:
+  - name: cdn
+    cmd: ./plugin.sh
+    after: archive
+`)
+	var flags = fakeFlags{
+		flags: map[string]string{
+			"skip-publish":  "true",
+			"skip-validate": "true",
+			"parallelism":   "4",
+		},
+	}
+	assert.NoError(t, Release(flags))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "dist", "artifacts.json"))
+	assert.NoError(t, err)
+	// paths are relative to dist
+	assert.Contains(t, string(bts), `"path": "fake.txt"`)
+	bts, err = ioutil.ReadFile(filepath.Join(folder, "dist", "fake_0.0.2_checksums.txt"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bts), "fake.txt")
+}
+
+func TestReleaseUnknownPluginAfter(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	createFile(t, "goreleaser.yml", `plugins:
+  - name: cdn
+    cmd: ./plugin.sh
+    after: nope
+`)
+	var flags = fakeFlags{
+		flags: map[string]string{
+			"parallelism": "4",
+		},
+	}
+	assert.EqualError(t, Release(flags), "plugin cdn: there is no pipe with ID nope to run after")
+}
+
+func TestCheck(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	var flags = fakeFlags{
+		flags: map[string]string{},
+	}
+	assert.NoError(t, Check(flags))
+	createFile(t, "goreleaser.yml", "build:\n  goos: [linx]\n")
+	assert.EqualError(t, Check(flags), "2 problem(s) found in goreleaser.yml")
+}
+
+func TestCheckConfig(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	var yaml = `project_name: fake
+build:
+  binary: fake
+  goos:
+    - linux
+    - linx
+  ldflags: -X main.version={{ .Version }
+archive:
+  format: rar
+  foo: bar
+changelog:
+  filters:
+    exclude:
+      - "(docs"
+sign:
+  artifacts: some
+`
+	problems, err := checkConfig("goreleaser.yml", []byte(yaml))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"goreleaser.yml:6: build.goos[1]: unknown GOOS \"linx\"",
+		"goreleaser.yml:7: build.ldflags: template: tmpl:1: unexpected \"}\" in operand",
+		"goreleaser.yml:9: archive.format: invalid value \"rar\", must be one of tar.gz, zip, binary",
+		"goreleaser.yml:10: archive.foo: unknown field",
+		"goreleaser.yml:14: changelog.filters.exclude[0]: error parsing regexp: missing closing ): `(docs`",
+		"goreleaser.yml:16: sign.artifacts: invalid value \"some\", must be one of none, all, checksum",
+	}, problems)
+}
+
+func TestTargets(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	var flags = fakeFlags{
+		flags: map[string]string{},
+	}
+	assert.NoError(t, Targets(flags))
+	flags.flags["config"] = "nope.yml"
+	assert.Error(t, Targets(flags))
+}
+
+func TestPrintTargets(t *testing.T) {
+	var out bytes.Buffer
+	printTargets(&out, []config.Build{
+		{
+			ID:      "server",
+			Goos:    []string{"linux"},
+			Goarch:  []string{"amd64", "arm"},
+			Goarm:   []string{"6", "7"},
+			Goamd64: []string{"v1", "v3"},
+		},
+		{
+			ID:     "client",
+			Goos:   []string{"darwin", "linux"},
+			Goarch: []string{"amd64"},
+			Ignore: []config.IgnoredBuild{{Goos: "darwin"}},
+		},
+	})
+	assert.Equal(t, `server:
+  linux/amd64v1
+  linux/amd64v3
+  linux/arm6
+  linux/arm7
+client:
+  linux/amd64
+`, out.String())
+}
+
+func TestCheckConfigInvalidYaml(t *testing.T) {
+	_, err := checkConfig("goreleaser.yml", []byte("build: [\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "goreleaser.yml: yaml: line")
+}
+
+func TestReleaseOnly(t *testing.T) {
+	folder, back := setup(t)
+	defer back()
+	var flags = fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"only":         "build,archive",
+			"parallelism":  "4",
+		},
+	}
+	assert.NoError(t, Release(flags))
+	_, err := os.Stat(filepath.Join(folder, "dist", "fake_0.0.2_linux_amd64.tar.gz"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(folder, "dist", "fake_0.0.2_checksums.txt"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReleaseSkipDependency(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	var flags = fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"skip":         "build",
+			"only":         "build,archive",
+			"parallelism":  "4",
+		},
+	}
+	assert.EqualError(t, Release(flags), "archive depends on build, which was not selected")
+}
+
+func TestReleaseUnknownPipe(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	var flags = fakeFlags{
+		flags: map[string]string{
+			"skip":        "nope",
+			"parallelism": "4",
+		},
+	}
+	assert.EqualError(t, Release(flags), "unknown pipe: nope")
+}
+
+func TestPublishRequiresFromDist(t *testing.T) {
+	var flags = fakeFlags{
+		flags: map[string]string{},
+	}
+	assert.EqualError(t, Publish(flags), "publish currently only works with --from-dist")
+}
+
+func TestPublishFromDist(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	var token = os.Getenv("GITHUB_TOKEN")
+	defer func() {
+		assert.NoError(t, os.Setenv("GITHUB_TOKEN", token))
+	}()
+	assert.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
+	// the dist is loaded fine, but we can't publish without a token
+	assert.EqualError(t, Publish(fakeFlags{
+		flags: map[string]string{
+			"from-dist":   "true",
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	}), env.ErrMissingToken.Error())
+}
+
+func TestPublishFromRelocatedDist(t *testing.T) {
+	folder, back := setup(t)
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	// e.g. the dist of another CI job, downloaded somewhere else
+	assert.NoError(t, os.Mkdir(filepath.Join(folder, "downloaded"), 0755))
+	assert.NoError(t, os.Rename(filepath.Join(folder, "dist"), filepath.Join(folder, "downloaded", "dist")))
+	var token = os.Getenv("GITHUB_TOKEN")
+	defer func() {
+		assert.NoError(t, os.Setenv("GITHUB_TOKEN", token))
+	}()
+	assert.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
+	// the artifacts are found in the new location, but we can't publish
+	// without a token
+	assert.EqualError(t, Publish(fakeFlags{
+		flags: map[string]string{
+			"from-dist":   "true",
+			"dist":        filepath.Join("downloaded", "dist"),
+			"parallelism": "4",
+		},
+	}), env.ErrMissingToken.Error())
+	// a missing artifact fails before anything is published
+	assert.NoError(t, os.Remove(filepath.Join(folder, "downloaded", "dist", "fake_0.0.2_checksums.txt")))
+	var err = Publish(fakeFlags{
+		flags: map[string]string{
+			"from-dist":   "true",
+			"dist":        filepath.Join("downloaded", "dist"),
+			"parallelism": "4",
+		},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "artifact fake_0.0.2_checksums.txt is missing from downloaded/dist")
+}
+
+func TestPublishFromDistNewTag(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	testlib.GitCommit(t, "after release")
+	testlib.GitTag(t, "v0.0.3")
+	assert.EqualError(t, Publish(fakeFlags{
+		flags: map[string]string{
+			"from-dist":   "true",
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	}), "dist version 0.0.2 doesn't match the current git version 0.0.3")
+}
+
+func TestPublishFromDistNotFound(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	assert.Error(t, Publish(fakeFlags{
+		flags: map[string]string{
+			"from-dist": "true",
+			"dist":      "dist",
+		},
+	}))
+}
+
+func TestVerifyReproducible(t *testing.T) {
+	_, back := setupWithConfig(t, "reproducible: true\n")
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	assert.NoError(t, VerifyReproducible(fakeFlags{
+		flags: map[string]string{
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	}))
+}
+
+func TestVerifyReproducibleChangedSources(t *testing.T) {
+	_, back := setupWithConfig(t, "reproducible: true\n")
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	createFile(t, "main.go", "package main\nfunc main() {println(1)}")
+	var err = VerifyReproducible(fakeFlags{
+		flags: map[string]string{
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "are not reproducible")
+}
+
+func TestVerifyReproducibleNewCommit(t *testing.T) {
+	_, back := setupWithConfig(t, "reproducible: true\n")
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	testlib.GitCommit(t, "after release")
+	var err = VerifyReproducible(fakeFlags{
+		flags: map[string]string{
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't match the current git commit")
+}
+
+func TestVerifyReproducibleWithLinuxPackages(t *testing.T) {
+	folder, back := setupWithConfig(t, `reproducible: true
+fpm:
+  formats:
+    - deb
+`)
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	// the package is in dist and in the checksums file, but isn't rebuilt
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "dist", "fake_0.0.2_checksums.txt"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bts), ".deb")
+	assert.NoError(t, VerifyReproducible(fakeFlags{
+		flags: map[string]string{
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	}))
+}
+
+func TestVerifyReproducibleWithSBOM(t *testing.T) {
+	folder, back := setupWithConfig(t, `reproducible: true
+sbom:
+  formats:
+    - spdx
+`)
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	assert.NoError(t, VerifyReproducible(fakeFlags{
+		flags: map[string]string{
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	}))
+	// the bills of materials are rebuilt and compared too
+	var path = filepath.Join(folder, "dist", metadata.ArtifactsFile)
+	var artifacts []metadata.Artifact
+	assert.NoError(t, readJSON(path, &artifacts))
+	var sboms int
+	for i, a := range artifacts {
+		if a.Type == artifact.SBOM {
+			artifacts[i].SHA256 = "changed"
+			sboms++
+		}
+	}
+	assert.Equal(t, 2, sboms)
+	bts, err := json.Marshal(artifacts)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, bts, 0644))
+	assert.EqualError(t, VerifyReproducible(fakeFlags{
+		flags: map[string]string{
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	}), "2 artifact(s) are not reproducible")
+}
+
+func TestVerifyReproducibleNotEnabled(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	assert.NoError(t, Release(fakeFlags{
+		flags: map[string]string{
+			"skip-publish": "true",
+			"parallelism":  "4",
+		},
+	}))
+	assert.EqualError(t, VerifyReproducible(fakeFlags{
+		flags: map[string]string{
+			"dist":        "dist",
+			"parallelism": "4",
+		},
+	}), "reproducible is not enabled in the config of dist")
+}
+
+func TestVerifyReproducibleNotFound(t *testing.T) {
+	_, back := setup(t)
+	defer back()
+	assert.Error(t, VerifyReproducible(fakeFlags{
+		flags: map[string]string{
+			"dist": "dist",
+		},
+	}))
+}
+
 func TestConfigFileIsSetAndDontExist(t *testing.T) {
 	var flags = fakeFlags{
 		flags: map[string]string{
@@ -116,6 +593,146 @@ func TestBrokenPipe(t *testing.T) {
 	assert.Error(t, Release(flags))
 }
 
+func TestBrokenPipeWritesMetadata(t *testing.T) {
+	folder, back := setup(t)
+	defer back()
+	createFile(t, "goreleaser.yml", `build:
+  binary: fake
+  goos:
+    - linux
+  goarch:
+    - amd64
+plugins:
+  - name: broken
+    cmd: ./nope.sh
+    after: archive
+`)
+	var flags = fakeFlags{
+		flags: map[string]string{
+			"skip-publish":  "true",
+			"skip-validate": "true",
+			"parallelism":   "4",
+		},
+	}
+	assert.Error(t, Release(flags))
+	// the artifacts built before the failure are listed
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "dist", "artifacts.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(bts), "fake_0.0.2_linux_amd64.tar.gz")
+	_, err = os.Stat(filepath.Join(folder, "dist", "metadata.json"))
+	assert.NoError(t, err)
+}
+
+func TestRollback(t *testing.T) {
+	ctx, cancel := newContext(config.Project{}, 0)
+	defer cancel()
+	var undone []string
+	var pipes = []pipeline.Piper{
+		fakePipe{"publish", func(ctx *context.Context) error {
+			ctx.Undo("unpublish", func(ctx *context.Context) error {
+				// the rollback must work even if the release was cancelled
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				undone = append(undone, "unpublish")
+				return nil
+			})
+			cancel()
+			return nil
+		}},
+		fakePipe{"never", func(ctx *context.Context) error {
+			return fmt.Errorf("should not run")
+		}},
+	}
+	assert.EqualError(t, run(ctx, fakeFlags{}, pipes), "release cancelled")
+	assert.Equal(t, []string{"unpublish"}, undone)
+}
+
+func TestRollbackFailure(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	var pipes = []pipeline.Piper{
+		fakePipe{"publish", func(ctx *context.Context) error {
+			ctx.Undo("unpublish", func(ctx *context.Context) error {
+				return fmt.Errorf("nope")
+			})
+			return nil
+		}},
+		fakePipe{"fail", func(ctx *context.Context) error {
+			return fmt.Errorf("failed")
+		}},
+	}
+	assert.EqualError(t, run(ctx, fakeFlags{}, pipes), "failed")
+}
+
+func TestRollbackOncePublished(t *testing.T) {
+	for name, tt := range map[string]struct {
+		brew   error
+		after  error
+		undone []string
+	}{
+		"brew fails": {
+			brew:   fmt.Errorf("failed to push formula"),
+			undone: []string{"delete artifactory file"},
+		},
+		"after fails": {
+			after:  fmt.Errorf("failed to notify"),
+			undone: []string{"revert formula", "delete artifactory file"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{})
+			var undone []string
+			var undo = func(description string) func(ctx *context.Context) error {
+				return func(ctx *context.Context) error {
+					undone = append(undone, description)
+					return nil
+				}
+			}
+			var pipes = []pipeline.Piper{
+				fakePipe{"artifactory", func(ctx *context.Context) error {
+					ctx.Undo("delete artifactory file", undo("delete artifactory file"))
+					return nil
+				}},
+				fakePipe{"release", func(ctx *context.Context) error {
+					ctx.UndoRelease("delete release", undo("delete release"))
+					ctx.Published()
+					return nil
+				}},
+				fakePipe{"brew", func(ctx *context.Context) error {
+					if tt.brew != nil {
+						return tt.brew
+					}
+					ctx.Undo("revert formula", undo("revert formula"))
+					return nil
+				}},
+				fakePipe{"after", func(ctx *context.Context) error {
+					return tt.after
+				}},
+			}
+			var expected = tt.brew
+			if expected == nil {
+				expected = tt.after
+			}
+			assert.EqualError(t, run(ctx, fakeFlags{}, pipes), expected.Error())
+			// the published release itself is never deleted
+			assert.Equal(t, tt.undone, undone)
+		})
+	}
+}
+
+type fakePipe struct {
+	name string
+	fn   func(ctx *context.Context) error
+}
+
+func (p fakePipe) String() string {
+	return p.name
+}
+
+func (p fakePipe) Run(ctx *context.Context) error {
+	return p.fn(ctx)
+}
+
 func TestInitProject(t *testing.T) {
 	_, back := setup(t)
 	defer back()
@@ -169,13 +786,23 @@ func (f fakeFlags) Bool(s string) bool {
 	return f.flags[s] == "true"
 }
 
+func (f fakeFlags) Duration(s string) time.Duration {
+	d, _ := time.ParseDuration(f.flags[s])
+	return d
+}
+
 func setup(t *testing.T) (current string, back func()) {
+	return setupWithConfig(t, "")
+}
+
+// setupWithConfig is like setup, with the given extra config
+func setupWithConfig(t *testing.T, extra string) (current string, back func()) {
 	folder, err := ioutil.TempDir("", "goreleaser")
 	assert.NoError(t, err)
 	previous, err := os.Getwd()
 	assert.NoError(t, err)
 	assert.NoError(t, os.Chdir(folder))
-	createGoreleaserYaml(t)
+	createGoreleaserYaml(t, extra)
 	createMainGo(t)
 	testlib.GitInit(t)
 	testlib.GitAdd(t)
@@ -199,7 +826,7 @@ func createMainGo(t *testing.T) {
 	createFile(t, "main.go", "package main\nfunc main() {println(0)}")
 }
 
-func createGoreleaserYaml(t *testing.T) {
+func createGoreleaserYaml(t *testing.T, extra string) {
 	var yaml = `build:
   binary: fake
   goos:
@@ -211,5 +838,5 @@ release:
     owner: goreleaser
     name: fake
 `
-	createFile(t, "goreleaser.yml", yaml)
+	createFile(t, "goreleaser.yml", yaml+extra)
 }
@@ -0,0 +1,145 @@
+// Package archive writes files from disk to tar.gz and zip archives.
+//
+// By default, the entries keep the modification time and permissions of the
+// files on disk. Reproducible archives give all entries the same
+// modification time, owner and permissions instead, so they only change when
+// the contents of the files do.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Archive represents a compression archive files from disk can be written to
+type Archive interface {
+	Close() error
+	Add(name, path string) error
+}
+
+// New archive for the given file, in the zip format if it has the .zip
+// extension, tar.gz otherwise
+func New(file *os.File) Archive {
+	return newArchive(file, time.Time{})
+}
+
+// NewReproducible archive for the given file, like New, whose entries have
+// the given modification time, are owned by root and have either 0755 or
+// 0644 permissions
+func NewReproducible(file *os.File, mtime time.Time) Archive {
+	return newArchive(file, mtime)
+}
+
+func newArchive(file *os.File, mtime time.Time) Archive {
+	if strings.HasSuffix(file.Name(), ".zip") {
+		return zipArchive{zw: zip.NewWriter(file), mtime: mtime}
+	}
+	// the gzip header has no name nor modification time by default
+	gw := gzip.NewWriter(file)
+	return tarArchive{gw: gw, tw: tar.NewWriter(gw), mtime: mtime}
+}
+
+// mode returns the normalized permissions of the given file mode
+func mode(m os.FileMode) os.FileMode {
+	if m.IsDir() || m&0111 != 0 {
+		return 0755
+	}
+	return 0644
+}
+
+type tarArchive struct {
+	gw    *gzip.Writer
+	tw    *tar.Writer
+	mtime time.Time
+}
+
+// Close all closeables
+func (a tarArchive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gw.Close()
+}
+
+// Add file to the archive
+func (a tarArchive) Add(name, path string) error {
+	file, err := os.Open(path) // #nosec
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint: errcheck
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, name)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if !a.mtime.IsZero() {
+		header.ModTime = a.mtime
+		header.AccessTime = time.Time{}
+		header.ChangeTime = time.Time{}
+		header.Uid = 0
+		header.Gid = 0
+		header.Uname = ""
+		header.Gname = ""
+		header.Mode = int64(mode(info.Mode()))
+	}
+	if err = a.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	_, err = io.Copy(a.tw, file)
+	return err
+}
+
+type zipArchive struct {
+	zw    *zip.Writer
+	mtime time.Time
+}
+
+// Close all closeables
+func (a zipArchive) Close() error {
+	return a.zw.Close()
+}
+
+// Add a file to the zip archive
+func (a zipArchive) Add(name, path string) error {
+	file, err := os.Open(path) // #nosec
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint: errcheck
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	if !a.mtime.IsZero() {
+		header.SetModTime(a.mtime)
+		header.SetMode(mode(info.Mode()) | (info.Mode() & os.ModeDir))
+	}
+	w, err := a.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
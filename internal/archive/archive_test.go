@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createFiles(t *testing.T, folder string, mtime time.Time) {
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(folder, "README.md"), []byte("readme"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(folder, "mybin"), []byte("binary"), 0700))
+	for _, name := range []string{"README.md", "mybin"} {
+		assert.NoError(t, os.Chtimes(filepath.Join(folder, name), mtime, mtime))
+	}
+}
+
+func write(t *testing.T, folder, name string, reproducible bool) []byte {
+	file, err := os.Create(filepath.Join(folder, name))
+	assert.NoError(t, err)
+	var a = New(file)
+	if reproducible {
+		a = NewReproducible(file, time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	}
+	assert.NoError(t, a.Add("README.md", filepath.Join(folder, "README.md")))
+	assert.NoError(t, a.Add("bin/mybin", filepath.Join(folder, "mybin")))
+	assert.NoError(t, a.Close())
+	assert.NoError(t, file.Close())
+	bts, err := ioutil.ReadFile(file.Name())
+	assert.NoError(t, err)
+	return bts
+}
+
+func TestTarGz(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	var mtime = time.Date(2017, 6, 7, 8, 9, 10, 0, time.UTC)
+	createFiles(t, folder, mtime)
+	gr, err := gzip.NewReader(bytes.NewReader(write(t, folder, "test.tar.gz", false)))
+	assert.NoError(t, err)
+	var tr = tar.NewReader(gr)
+	header, err := tr.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "README.md", header.Name)
+	assert.Equal(t, int64(0600), header.Mode)
+	assert.True(t, mtime.Equal(header.ModTime))
+	header, err = tr.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "bin/mybin", header.Name)
+	bts, err := ioutil.ReadAll(tr)
+	assert.NoError(t, err)
+	assert.Equal(t, "binary", string(bts))
+}
+
+func TestTarGzReproducible(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	createFiles(t, folder, time.Date(2017, 6, 7, 8, 9, 10, 0, time.UTC))
+	var first = write(t, folder, "first.tar.gz", true)
+	createFiles(t, folder, time.Now())
+	var second = write(t, folder, "second.tar.gz", true)
+	assert.Equal(t, first, second)
+
+	gr, err := gzip.NewReader(bytes.NewReader(first))
+	assert.NoError(t, err)
+	var tr = tar.NewReader(gr)
+	for _, expected := range []struct {
+		name string
+		mode int64
+	}{
+		{"README.md", 0644},
+		{"bin/mybin", 0755},
+	} {
+		header, err := tr.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, expected.name, header.Name)
+		assert.Equal(t, expected.mode, header.Mode)
+		assert.Equal(t, 0, header.Uid)
+		assert.Equal(t, 0, header.Gid)
+		assert.Empty(t, header.Uname)
+		assert.True(t, time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC).Equal(header.ModTime))
+	}
+}
+
+func TestZipReproducible(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	createFiles(t, folder, time.Date(2017, 6, 7, 8, 9, 10, 0, time.UTC))
+	var first = write(t, folder, "first.zip", true)
+	createFiles(t, folder, time.Now())
+	var second = write(t, folder, "second.zip", true)
+	assert.Equal(t, first, second)
+
+	zr, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	assert.NoError(t, err)
+	assert.Len(t, zr.File, 2)
+	assert.Equal(t, "README.md", zr.File[0].Name)
+	assert.Equal(t, os.FileMode(0644), zr.File[0].Mode())
+	assert.Equal(t, "bin/mybin", zr.File[1].Name)
+	assert.Equal(t, os.FileMode(0755), zr.File[1].Mode())
+}
+
+func TestAddFileThatDoesntExist(t *testing.T) {
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	for _, name := range []string{"test.tar.gz", "test.zip"} {
+		file, err := os.Create(filepath.Join(folder, name))
+		assert.NoError(t, err)
+		var a = New(file)
+		assert.Error(t, a.Add("nope", "/nope/nope"))
+		assert.NoError(t, a.Close())
+	}
+}
@@ -2,18 +2,15 @@
 // based on the config
 package archiveformat
 
-import (
-	"strings"
+import "github.com/goreleaser/goreleaser/config"
 
-	"github.com/goreleaser/goreleaser/context"
-)
-
-// For return the archive format, considering overrides and all that
-func For(ctx *context.Context, platform string) string {
-	for _, override := range ctx.Config.Archive.FormatOverrides {
-		if strings.HasPrefix(platform, override.Goos) {
+// For return the archive format for the given goos, considering the
+// overrides of the given archive
+func For(archive config.Archive, goos string) string {
+	for _, override := range archive.FormatOverrides {
+		if goos == override.Goos {
 			return override.Format
 		}
 	}
-	return ctx.Config.Archive.Format
+	return archive.Format
 }
@@ -4,24 +4,19 @@ import (
 	"testing"
 
 	"github.com/goreleaser/goreleaser/config"
-	"github.com/goreleaser/goreleaser/context"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestFormatFor(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{
-			Archive: config.Archive{
-				Format: "tar.gz",
-				FormatOverrides: []config.FormatOverride{
-					{
-						Goos:   "windows",
-						Format: "zip",
-					},
-				},
+	var archive = config.Archive{
+		Format: "tar.gz",
+		FormatOverrides: []config.FormatOverride{
+			{
+				Goos:   "windows",
+				Format: "zip",
 			},
 		},
 	}
-	assert.Equal(t, "zip", For(ctx, "windowsamd64"))
-	assert.Equal(t, "tar.gz", For(ctx, "linux386"))
+	assert.Equal(t, "zip", For(archive, "windows"))
+	assert.Equal(t, "tar.gz", For(archive, "linux"))
 }
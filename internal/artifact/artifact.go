@@ -0,0 +1,308 @@
+// Package artifact provides the core artifact storage for goreleaser
+package artifact
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+)
+
+// Type defines the type of an artifact
+type Type int
+
+const (
+	// UploadableArchive a tar.gz/zip archive to be uploaded
+	UploadableArchive Type = iota
+	// UploadableBinary is a binary file to be uploaded
+	UploadableBinary
+	// Binary is a binary (output of a gobuild)
+	Binary
+	// LinuxPackage is a linux package generated natively or by fpm
+	LinuxPackage
+	// Snap is a snap package generated by snapcraft
+	Snap
+	// PublishableDockerImage is a docker image that was built but not yet
+	// pushed
+	PublishableDockerImage
+	// DockerImage is a docker image that was pushed
+	DockerImage
+	// Checksum is a checksums file
+	Checksum
+	// Signature is a signature file
+	Signature
+	// SBOM is a software bill of materials of a binary or of the release
+	SBOM
+	// Provenance is an in-toto statement of how the release was built
+	Provenance
+)
+
+func (t Type) String() string {
+	switch t {
+	case UploadableArchive:
+		return "archive"
+	case UploadableBinary:
+		return "uploadable_binary"
+	case Binary:
+		return "binary"
+	case LinuxPackage:
+		return "linux_package"
+	case Snap:
+		return "snap"
+	case PublishableDockerImage:
+		return "publishable_docker_image"
+	case DockerImage:
+		return "docker_image"
+	case Checksum:
+		return "checksum"
+	case Signature:
+		return "signature"
+	case SBOM:
+		return "sbom"
+	case Provenance:
+		return "provenance"
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the type as its name
+func (t Type) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText decodes a type from its name
+func (t *Type) UnmarshalText(text []byte) error {
+	for i := Type(0); i.String() != "unknown"; i++ {
+		if i.String() == string(text) {
+			*t = i
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid artifact type: %s", string(text))
+}
+
+// Artifact represents an artifact and its relevant info
+type Artifact struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Goos   string `json:"goos,omitempty"`
+	Goarch string `json:"goarch,omitempty"`
+	Goarm  string `json:"goarm,omitempty"`
+	// Goamd64, Go386, Gomips and Goppc64 are the sub-architecture variants
+	// of the artifact, like Goarm is for arm. Only the one of the Goarch of
+	// the artifact may be set.
+	Goamd64 string `json:"goamd64,omitempty"`
+	Go386   string `json:"go386,omitempty"`
+	Gomips  string `json:"gomips,omitempty"`
+	Goppc64 string `json:"goppc64,omitempty"`
+	Type    Type   `json:"type"`
+	// Extra holds free-form metadata about the artifact. The "Binary" key
+	// holds the name of the binary the artifact came from, if any, and the
+	// "ID" key the IDs of the builds it came from, separated by commas.
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// Variant returns the sub-architecture variant of the artifact, e.g. its
+// goarm or goamd64, if any.
+func (a Artifact) Variant() string {
+	return a.Goarm + a.Goamd64 + a.Go386 + a.Gomips + a.Goppc64
+}
+
+// Platform returns the goos, goarch and variant of the artifact joined
+// together, e.g. linuxarm6 or linuxamd64v3.
+func (a Artifact) Platform() string {
+	return a.Goos + a.Goarch + a.Variant()
+}
+
+// Artifacts is a list of artifacts, safe for concurrent use.
+// The zero value is an empty list ready to use.
+type Artifacts struct {
+	items []Artifact
+	lock  sync.Mutex
+}
+
+// New return a new list of artifacts
+func New() *Artifacts {
+	return &Artifacts{}
+}
+
+// List return the actual list of artifacts
+func (artifacts *Artifacts) List() []Artifact {
+	artifacts.lock.Lock()
+	defer artifacts.lock.Unlock()
+	var result = make([]Artifact, len(artifacts.items))
+	copy(result, artifacts.items)
+	return result
+}
+
+// GroupByPlatform groups the artifacts by their platform
+func (artifacts *Artifacts) GroupByPlatform() map[string][]Artifact {
+	var result = map[string][]Artifact{}
+	for _, a := range artifacts.List() {
+		result[a.Platform()] = append(result[a.Platform()], a)
+	}
+	return result
+}
+
+// Add safely adds a new artifact to an artifact list
+func (artifacts *Artifacts) Add(a Artifact) {
+	artifacts.lock.Lock()
+	defer artifacts.lock.Unlock()
+	log.WithField("name", a.Name).
+		WithField("path", a.Path).
+		WithField("type", a.Type).
+		Debug("added new artifact")
+	artifacts.items = append(artifacts.items, a)
+}
+
+// Filter defines an artifact filter which can be used within the Filter
+// function
+type Filter func(a Artifact) bool
+
+// ByGoos is a predefined filter that filters by the given goos
+func ByGoos(s string) Filter {
+	return func(a Artifact) bool {
+		return a.Goos == s
+	}
+}
+
+// ByGoarch is a predefined filter that filters by the given goarch
+func ByGoarch(s string) Filter {
+	return func(a Artifact) bool {
+		return a.Goarch == s
+	}
+}
+
+// ByGoarm is a predefined filter that filters by the given goarm
+func ByGoarm(s string) Filter {
+	return func(a Artifact) bool {
+		return a.Goarm == s
+	}
+}
+
+// ByVariant is a predefined filter that filters by the given
+// sub-architecture variant, e.g. a goarm or a goamd64
+func ByVariant(s string) Filter {
+	return func(a Artifact) bool {
+		return a.Variant() == s
+	}
+}
+
+// defaultVariants are the variants the go toolchain builds for when the
+// variant of a goarch isn't set
+var defaultVariants = map[string]string{
+	"amd64":    "v1",
+	"386":      "sse2",
+	"mips":     "hardfloat",
+	"mipsle":   "hardfloat",
+	"mips64":   "hardfloat",
+	"mips64le": "hardfloat",
+	"ppc64":    "power8",
+	"ppc64le":  "power8",
+}
+
+// ByVariantOrDefault is a predefined filter that filters by the given
+// goamd64, go386, gomips or goppc64 variant or, if it's empty, by the
+// default variant of the toolchain, whether it was set or not, e.g. an
+// empty goamd64 or v1. The goarm isn't considered.
+func ByVariantOrDefault(s string) Filter {
+	return func(a Artifact) bool {
+		var variant = a.Goamd64 + a.Go386 + a.Gomips + a.Goppc64
+		if s != "" {
+			return variant == s
+		}
+		return variant == "" || variant == defaultVariants[a.Goarch]
+	}
+}
+
+// ByType is a predefined filter that filters by the given type
+func ByType(t Type) Filter {
+	return func(a Artifact) bool {
+		return a.Type == t
+	}
+}
+
+// ByBinary is a predefined filter that filters by the build the artifact
+// came from
+func ByBinary(s string) Filter {
+	return func(a Artifact) bool {
+		return a.Extra["Binary"] == s
+	}
+}
+
+// ByIDs is a predefined filter that filters by the IDs of the builds the
+// artifact came from. No IDs at all matches every artifact, which is what an
+// empty `builds` list in the config means.
+func ByIDs(ids ...string) Filter {
+	return func(a Artifact) bool {
+		if len(ids) == 0 {
+			return true
+		}
+		for _, id := range strings.Split(a.Extra["ID"], ",") {
+			for _, want := range ids {
+				if id == want {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// JoinIDs returns the sorted IDs of the builds the given artifacts came
+// from, separated by commas, as expected in the "ID" extra field of an
+// artifact made out of them, e.g. an archive
+func JoinIDs(artifacts []Artifact) string {
+	var seen = map[string]bool{}
+	var result []string
+	for _, a := range artifacts {
+		for _, id := range strings.Split(a.Extra["ID"], ",") {
+			if id != "" && !seen[id] {
+				seen[id] = true
+				result = append(result, id)
+			}
+		}
+	}
+	sort.Strings(result)
+	return strings.Join(result, ",")
+}
+
+// Or performs an OR between all given filters
+func Or(filters ...Filter) Filter {
+	return func(a Artifact) bool {
+		for _, f := range filters {
+			if f(a) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// And performs an AND between all given filters
+func And(filters ...Filter) Filter {
+	return func(a Artifact) bool {
+		for _, f := range filters {
+			if !f(a) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Filter filters the artifact list, returning a new instance.
+// There are some pre-defined filters but anything of the Type Filter
+// is accepted.
+// You can compose filters by using the And and Or filters.
+func (artifacts *Artifacts) Filter(filter Filter) *Artifacts {
+	var result = New()
+	for _, a := range artifacts.List() {
+		if filter(a) {
+			result.items = append(result.items, a)
+		}
+	}
+	return result
+}
@@ -0,0 +1,245 @@
+package artifact
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/errgroup"
+)
+
+// ensure Type implements the stringer interface
+var _ fmt.Stringer = Type(0)
+
+func TestAdd(t *testing.T) {
+	var g errgroup.Group
+	var artifacts = New()
+	for _, a := range []Artifact{
+		{
+			Name: "foo",
+			Type: UploadableArchive,
+		},
+		{
+			Name: "bar",
+			Type: Binary,
+		},
+		{
+			Name: "foobar",
+			Type: DockerImage,
+		},
+		{
+			Name: "check",
+			Type: Checksum,
+		},
+	} {
+		a := a
+		g.Go(func() error {
+			artifacts.Add(a)
+			return nil
+		})
+	}
+	assert.NoError(t, g.Wait())
+	assert.Len(t, artifacts.List(), 4)
+}
+
+func TestZeroValue(t *testing.T) {
+	var artifacts Artifacts
+	assert.Empty(t, artifacts.List())
+	artifacts.Add(Artifact{Name: "foo"})
+	assert.Len(t, artifacts.List(), 1)
+}
+
+func TestFilter(t *testing.T) {
+	var data = []Artifact{
+		{
+			Name:   "foo",
+			Goos:   "linux",
+			Goarch: "arm",
+		},
+		{
+			Name:   "bar",
+			Goarch: "amd64",
+		},
+		{
+			Name:  "foobar",
+			Goarm: "6",
+		},
+		{
+			Name: "check",
+			Type: Checksum,
+		},
+		{
+			Name: "checkzumm",
+			Type: Checksum,
+		},
+		{
+			Name:  "server",
+			Type:  Binary,
+			Extra: map[string]string{"Binary": "server", "ID": "server"},
+		},
+		{
+			Name:  "bundle.tar.gz",
+			Type:  UploadableArchive,
+			Extra: map[string]string{"ID": "client,server"},
+		},
+	}
+	var artifacts = New()
+	for _, a := range data {
+		artifacts.Add(a)
+	}
+
+	assert.Len(t, artifacts.Filter(ByGoos("linux")).items, 1)
+	assert.Len(t, artifacts.Filter(ByGoos("darwin")).items, 0)
+
+	assert.Len(t, artifacts.Filter(ByGoarch("amd64")).items, 1)
+	assert.Len(t, artifacts.Filter(ByGoarch("386")).items, 0)
+
+	assert.Len(t, artifacts.Filter(ByGoarm("6")).items, 1)
+	assert.Len(t, artifacts.Filter(ByGoarm("7")).items, 0)
+
+	assert.Len(t, artifacts.Filter(ByType(Checksum)).items, 2)
+	assert.Len(t, artifacts.Filter(ByType(Binary)).items, 1)
+
+	assert.Len(t, artifacts.Filter(ByBinary("server")).items, 1)
+	assert.Len(t, artifacts.Filter(ByBinary("client")).items, 0)
+
+	assert.Len(t, artifacts.Filter(ByIDs("server")).items, 2)
+	assert.Len(t, artifacts.Filter(ByIDs("client", "other")).items, 1)
+	assert.Len(t, artifacts.Filter(ByIDs("other")).items, 0)
+	assert.Len(t, artifacts.Filter(ByIDs()).items, len(data))
+
+	assert.Len(t, artifacts.Filter(
+		And(
+			ByType(Checksum),
+			func(a Artifact) bool {
+				return a.Name == "checkzumm"
+			},
+		),
+	).List(), 1)
+
+	assert.Len(t, artifacts.Filter(
+		Or(
+			ByType(Checksum),
+			And(
+				ByGoos("linux"),
+				ByGoarch("arm"),
+			),
+		),
+	).List(), 3)
+}
+
+func TestByVariantOrDefault(t *testing.T) {
+	var artifacts = New()
+	for _, a := range []Artifact{
+		{Name: "amd64", Goarch: "amd64"},
+		{Name: "amd64v1", Goarch: "amd64", Goamd64: "v1"},
+		{Name: "amd64v3", Goarch: "amd64", Goamd64: "v3"},
+		{Name: "mips", Goarch: "mips", Gomips: "hardfloat"},
+		{Name: "mipssoftfloat", Goarch: "mips", Gomips: "softfloat"},
+		{Name: "armv6", Goarch: "arm", Goarm: "6"},
+	} {
+		artifacts.Add(a)
+	}
+	var names = func(filter Filter) []string {
+		var result []string
+		for _, a := range artifacts.Filter(filter).List() {
+			result = append(result, a.Name)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"amd64", "amd64v1", "mips", "armv6"}, names(ByVariantOrDefault("")))
+	assert.Equal(t, []string{"amd64v3"}, names(ByVariantOrDefault("v3")))
+	assert.Equal(t, []string{"mipssoftfloat"}, names(ByVariantOrDefault("softfloat")))
+	assert.Empty(t, names(ByVariantOrDefault("v4")))
+}
+
+func TestJoinIDs(t *testing.T) {
+	assert.Equal(t, "", JoinIDs(nil))
+	assert.Equal(t, "client,server", JoinIDs([]Artifact{
+		{Extra: map[string]string{"ID": "server"}},
+		{Extra: map[string]string{"ID": "client"}},
+		{Extra: map[string]string{"ID": "server"}},
+		{Name: "no id"},
+	}))
+}
+
+func TestGroupByPlatform(t *testing.T) {
+	var data = []Artifact{
+		{
+			Name:   "foo",
+			Goos:   "linux",
+			Goarch: "amd64",
+		},
+		{
+			Name:   "bar",
+			Goos:   "linux",
+			Goarch: "amd64",
+		},
+		{
+			Name:   "foobar",
+			Goos:   "linux",
+			Goarch: "arm",
+			Goarm:  "6",
+		},
+		{
+			Name:    "foobarv3",
+			Goos:    "linux",
+			Goarch:  "amd64",
+			Goamd64: "v3",
+		},
+		{
+			Name: "check",
+			Type: Checksum,
+		},
+	}
+	var artifacts = New()
+	for _, a := range data {
+		artifacts.Add(a)
+	}
+
+	var groups = artifacts.GroupByPlatform()
+	assert.Len(t, groups, 4)
+	assert.Len(t, groups["linuxamd64"], 2)
+	assert.Len(t, groups["linuxarm6"], 1)
+	assert.Len(t, groups["linuxamd64v3"], 1)
+	assert.Len(t, groups[""], 1)
+}
+
+func TestTypeString(t *testing.T) {
+	for typ, s := range map[Type]string{
+		UploadableArchive:      "archive",
+		UploadableBinary:       "uploadable_binary",
+		Binary:                 "binary",
+		LinuxPackage:           "linux_package",
+		Snap:                   "snap",
+		PublishableDockerImage: "publishable_docker_image",
+		DockerImage:            "docker_image",
+		Checksum:               "checksum",
+		Signature:              "signature",
+		SBOM:                   "sbom",
+		Provenance:             "provenance",
+		Type(999):              "unknown",
+	} {
+		assert.Equal(t, s, typ.String())
+	}
+}
+
+func TestTypeJSON(t *testing.T) {
+	var a = Artifact{
+		Name: "foo.tar.gz",
+		Path: "dist/foo.tar.gz",
+		Type: UploadableArchive,
+	}
+	bts, err := json.Marshal(a)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bts), `"type":"archive"`)
+	var b Artifact
+	assert.NoError(t, json.Unmarshal(bts, &b))
+	assert.Equal(t, a, b)
+	assert.EqualError(
+		t,
+		json.Unmarshal([]byte(`{"type":"nope"}`), &b),
+		"invalid artifact type: nope",
+	)
+}
@@ -0,0 +1,200 @@
+// Package binfmt reads the headers of the executables built by goreleaser,
+// so they can be checked against the target they were built for.
+package binfmt
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Executable formats
+const (
+	ELF   = "elf"
+	MachO = "macho"
+	PE    = "pe"
+	Wasm  = "wasm"
+)
+
+// Info is what the headers of an executable tell about it
+type Info struct {
+	// Format is the executable format, e.g. ELF
+	Format string
+	// OS is the GOOS the executable is for. It is empty for the ELF
+	// executables of the OSes that don't mark them, e.g. linux.
+	OS string
+	// Arch is the GOARCH the executable is for, or empty if unknown
+	Arch string
+	// Dynamic is true if the executable loads shared libraries
+	Dynamic bool
+	// Symbols is true if the executable has a symbol table, which the
+	// `-s` ldflag strips
+	Symbols bool
+	// DWARF is true if the executable has debug info, which the `-w`
+	// ldflag strips
+	DWARF bool
+}
+
+// Inspect reads the headers of the given executable
+func Inspect(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close() // nolint: errcheck
+	var magic = make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return Info{}, fmt.Errorf("%s: unknown executable format", path)
+	}
+	switch {
+	case bytes.Equal(magic, []byte(elf.ELFMAG)):
+		return inspectELF(f)
+	case bytes.Equal(magic[:2], []byte("MZ")):
+		return inspectPE(f)
+	case bytes.Equal(magic, []byte("\x00asm")):
+		return Info{Format: Wasm, Arch: "wasm"}, nil
+	}
+	switch binary.LittleEndian.Uint32(magic) {
+	case macho.Magic32, macho.Magic64:
+		return inspectMachO(f)
+	}
+	switch binary.BigEndian.Uint32(magic) {
+	case macho.Magic32, macho.Magic64:
+		return inspectMachO(f)
+	}
+	return Info{}, fmt.Errorf("%s: unknown executable format", path)
+}
+
+var elfOS = map[elf.OSABI]string{
+	elf.ELFOSABI_FREEBSD: "freebsd",
+	elf.ELFOSABI_NETBSD:  "netbsd",
+	elf.ELFOSABI_OPENBSD: "openbsd",
+}
+
+// ELFOS returns the OS the go linker marks the ELF executables of the given
+// GOOS with, which is empty for most of them
+func ELFOS(goos string) string {
+	for _, name := range elfOS {
+		if name == goos {
+			return name
+		}
+	}
+	return ""
+}
+
+func inspectELF(r io.ReaderAt) (Info, error) {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return Info{}, err
+	}
+	var info = Info{
+		Format:  ELF,
+		OS:      elfOS[f.OSABI],
+		Arch:    elfArch(f),
+		Symbols: f.Section(".symtab") != nil,
+		DWARF:   f.Section(".debug_info") != nil || f.Section(".zdebug_info") != nil,
+	}
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP {
+			info.Dynamic = true
+		}
+	}
+	if libs, err := f.ImportedLibraries(); err == nil && len(libs) > 0 {
+		info.Dynamic = true
+	}
+	return info, nil
+}
+
+func elfArch(f *elf.File) string {
+	var little = f.Data == elf.ELFDATA2LSB
+	switch f.Machine {
+	case elf.EM_X86_64:
+		return "amd64"
+	case elf.EM_386:
+		return "386"
+	case elf.EM_ARM:
+		return "arm"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_PPC64:
+		if little {
+			return "ppc64le"
+		}
+		return "ppc64"
+	case elf.EM_MIPS:
+		var arch = "mips"
+		if f.Class == elf.ELFCLASS64 {
+			arch = "mips64"
+		}
+		if little {
+			arch += "le"
+		}
+		return arch
+	case elf.EM_S390:
+		return "s390x"
+	case elf.EM_RISCV:
+		return "riscv64"
+	case elf.EM_LOONGARCH:
+		return "loong64"
+	}
+	return ""
+}
+
+func inspectMachO(r io.ReaderAt) (Info, error) {
+	f, err := macho.NewFile(r)
+	if err != nil {
+		return Info{}, err
+	}
+	var info = Info{
+		Format: MachO,
+		OS:     "darwin",
+		Arch:   machoArch[f.Cpu],
+		DWARF:  f.Segment("__DWARF") != nil || f.Section("__debug_info") != nil,
+	}
+	// the symbols of the dynamic libraries are always there, only the
+	// local ones are stripped
+	if f.Dysymtab != nil {
+		info.Symbols = f.Dysymtab.Nlocalsym > 0
+	}
+	if libs, err := f.ImportedLibraries(); err == nil && len(libs) > 0 {
+		info.Dynamic = true
+	}
+	return info, nil
+}
+
+var machoArch = map[macho.Cpu]string{
+	macho.CpuAmd64: "amd64",
+	macho.Cpu386:   "386",
+	macho.CpuArm:   "arm",
+	macho.CpuArm64: "arm64",
+}
+
+func inspectPE(r io.ReaderAt) (Info, error) {
+	f, err := pe.NewFile(r)
+	if err != nil {
+		return Info{}, err
+	}
+	var info = Info{
+		Format:  PE,
+		OS:      "windows",
+		Arch:    peArch[f.Machine],
+		Symbols: f.NumberOfSymbols > 0,
+		DWARF:   f.Section(".debug_info") != nil || f.Section(".zdebug_info") != nil,
+	}
+	if libs, err := f.ImportedLibraries(); err == nil && len(libs) > 0 {
+		info.Dynamic = true
+	}
+	return info, nil
+}
+
+var peArch = map[uint16]string{
+	pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+	pe.IMAGE_FILE_MACHINE_I386:  "386",
+	pe.IMAGE_FILE_MACHINE_ARMNT: "arm",
+	pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+}
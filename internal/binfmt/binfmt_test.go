@@ -0,0 +1,73 @@
+package binfmt
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "main.go"),
+		[]byte("package main\nfunc main() {println(0)}"),
+		0644,
+	))
+	for _, tt := range []struct {
+		goos, goarch, ldflags string
+		info                  Info
+	}{
+		{"linux", "amd64", "", Info{Format: ELF, Arch: "amd64", Symbols: true, DWARF: true}},
+		{"linux", "arm64", "-s -w", Info{Format: ELF, Arch: "arm64"}},
+		{"linux", "mips64le", "-w", Info{Format: ELF, Arch: "mips64le", Symbols: true}},
+		{"freebsd", "amd64", "-s -w", Info{Format: ELF, OS: "freebsd", Arch: "amd64"}},
+		{"openbsd", "amd64", "-s -w", Info{Format: ELF, OS: "openbsd", Arch: "amd64", Dynamic: true}},
+		{"darwin", "arm64", "", Info{Format: MachO, OS: "darwin", Arch: "arm64", Dynamic: true, Symbols: true, DWARF: true}},
+		{"darwin", "amd64", "-s -w", Info{Format: MachO, OS: "darwin", Arch: "amd64", Dynamic: true}},
+		{"windows", "386", "", Info{Format: PE, OS: "windows", Arch: "386", Symbols: true, DWARF: true}},
+		{"windows", "amd64", "-s -w", Info{Format: PE, OS: "windows", Arch: "amd64"}},
+		{"js", "wasm", "", Info{Format: Wasm, Arch: "wasm"}},
+	} {
+		t.Run(tt.goos+"/"+tt.goarch+" "+tt.ldflags, func(t *testing.T) {
+			var binary = filepath.Join(folder, tt.goos+tt.goarch)
+			/* #nosec */
+			var cmd = exec.Command("go", "build", "-ldflags="+tt.ldflags, "-o", binary, "main.go")
+			cmd.Env = append(os.Environ(), "GOOS="+tt.goos, "GOARCH="+tt.goarch, "CGO_ENABLED=0")
+			out, err := cmd.CombinedOutput()
+			assert.NoError(t, err, string(out))
+			info, err := Inspect(binary)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.info, info)
+		})
+	}
+}
+
+func TestInspectUnknownFormat(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var path = filepath.Join(folder, "script.sh")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\necho hi\n"), 0755))
+	_, err := Inspect(path)
+	assert.EqualError(t, err, path+": unknown executable format")
+	var empty = filepath.Join(folder, "empty")
+	assert.NoError(t, ioutil.WriteFile(empty, []byte{}, 0755))
+	_, err = Inspect(empty)
+	assert.EqualError(t, err, empty+": unknown executable format")
+}
+
+func TestInspectNotFound(t *testing.T) {
+	_, err := Inspect("/nope/nope")
+	assert.Error(t, err)
+}
+
+func TestELFOS(t *testing.T) {
+	assert.Equal(t, "freebsd", ELFOS("freebsd"))
+	assert.Equal(t, "", ELFOS("linux"))
+	assert.Equal(t, "", ELFOS("solaris"))
+}
@@ -6,32 +6,85 @@ import (
 )
 
 // Runtime is the current runtime buildTarget
-var Runtime = Target{runtime.GOOS, runtime.GOARCH, ""}
+var Runtime = Target{OS: runtime.GOOS, Arch: runtime.GOARCH}
 
 // New builtarget
 func New(goos, goarch, goarm string) Target {
-	return Target{goos, goarch, goarm}
+	return Target{OS: goos, Arch: goarch, Arm: goarm}
+}
+
+// WithVariant returns the build target of the given goos and goarch with
+// the given variant, e.g. the GOAMD64 for amd64 or the GOMIPS for mips
+func WithVariant(goos, goarch, variant string) Target {
+	var t = Target{OS: goos, Arch: goarch}
+	switch VariantEnv(goarch) {
+	case "GOARM":
+		t.Arm = variant
+	case "GOAMD64":
+		t.Amd64 = variant
+	case "GO386":
+		t.I386 = variant
+	case "GOMIPS", "GOMIPS64":
+		t.Mips = variant
+	case "GOPPC64":
+		t.Ppc64 = variant
+	}
+	return t
 }
 
 // Target is a build target
 type Target struct {
 	OS, Arch, Arm string
+	// Amd64, I386, Mips and Ppc64 are the GOAMD64, GO386, GOMIPS (or
+	// GOMIPS64) and GOPPC64 variants. Like Arm, only the one of the
+	// target arch may be set.
+	Amd64, I386, Mips, Ppc64 string
+}
+
+// Variant returns the sub-architecture variant of the target, e.g. the
+// GOARM for arm or the GOAMD64 for amd64, if any
+func (t Target) Variant() string {
+	return t.Arm + t.Amd64 + t.I386 + t.Mips + t.Ppc64
+}
+
+// VariantEnv returns the environment variable holding the variant of the
+// given goarch, or an empty string if it has no variants
+func VariantEnv(goarch string) string {
+	switch goarch {
+	case "arm":
+		return "GOARM"
+	case "amd64":
+		return "GOAMD64"
+	case "386":
+		return "GO386"
+	case "mips", "mipsle":
+		return "GOMIPS"
+	case "mips64", "mips64le":
+		return "GOMIPS64"
+	case "ppc64", "ppc64le":
+		return "GOPPC64"
+	}
+	return ""
 }
 
 // Env returns the current Target as environment variables
 func (t Target) Env() []string {
-	return []string{
+	var env = []string{
 		"GOOS=" + t.OS,
 		"GOARCH=" + t.Arch,
 		"GOARM=" + t.Arm,
 	}
+	if name := VariantEnv(t.Arch); name != "" && name != "GOARM" && t.Variant() != "" {
+		env = append(env, name+"="+t.Variant())
+	}
+	return env
 }
 
 func (t Target) String() string {
-	return fmt.Sprintf("%v%v%v", t.OS, t.Arch, t.Arm)
+	return fmt.Sprintf("%v%v%v", t.OS, t.Arch, t.Variant())
 }
 
 // PrettyString is a prettier version of the String method.
 func (t Target) PrettyString() string {
-	return fmt.Sprintf("%v/%v%v", t.OS, t.Arch, t.Arm)
+	return fmt.Sprintf("%v/%v%v", t.OS, t.Arch, t.Variant())
 }
@@ -14,6 +14,33 @@ func TestEnv(t *testing.T) {
 	)
 }
 
+func TestEnvWithVariant(t *testing.T) {
+	for _, tt := range []struct {
+		goarch, variant string
+		env             []string
+	}{
+		{"amd64", "v3", []string{"GOOS=linux", "GOARCH=amd64", "GOARM=", "GOAMD64=v3"}},
+		{"386", "softfloat", []string{"GOOS=linux", "GOARCH=386", "GOARM=", "GO386=softfloat"}},
+		{"mipsle", "softfloat", []string{"GOOS=linux", "GOARCH=mipsle", "GOARM=", "GOMIPS=softfloat"}},
+		{"mips64", "hardfloat", []string{"GOOS=linux", "GOARCH=mips64", "GOARM=", "GOMIPS64=hardfloat"}},
+		{"ppc64le", "power9", []string{"GOOS=linux", "GOARCH=ppc64le", "GOARM=", "GOPPC64=power9"}},
+		{"arm", "7", []string{"GOOS=linux", "GOARCH=arm", "GOARM=7"}},
+		{"amd64", "", []string{"GOOS=linux", "GOARCH=amd64", "GOARM="}},
+	} {
+		t.Run(tt.goarch+tt.variant, func(t *testing.T) {
+			assert.Equal(t, tt.env, WithVariant("linux", tt.goarch, tt.variant).Env())
+		})
+	}
+}
+
+func TestVariant(t *testing.T) {
+	var target = WithVariant("linux", "amd64", "v3")
+	assert.Equal(t, "v3", target.Amd64)
+	assert.Equal(t, "v3", target.Variant())
+	assert.Equal(t, "linuxamd64v3", target.String())
+	assert.Equal(t, New("linux", "arm", "7"), WithVariant("linux", "arm", "7"))
+}
+
 func TestString(t *testing.T) {
 	assert.Equal(
 		t,
@@ -1,6 +1,8 @@
 package buildtarget
 
 import (
+	"strings"
+
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/config"
 )
@@ -10,7 +12,7 @@ func All(build config.Build) (targets []Target) {
 	for _, target := range allBuildTargets(build) {
 		if !valid(target) {
 			log.WithField("target", target.PrettyString()).
-				Debug("skipped invalid build")
+				Warn("skipped build target not supported by the go toolchain")
 			continue
 		}
 		if ignored(build, target) {
@@ -32,21 +34,43 @@ func allBuildTargets(build config.Build) (targets []Target) {
 				}
 				continue
 			}
-			targets = append(targets, New(goos, goarch, ""))
+			var variants = variantsOf(build, goarch)
+			if len(variants) == 0 {
+				// the default variant of the toolchain
+				variants = []string{""}
+			}
+			for _, variant := range variants {
+				targets = append(targets, WithVariant(goos, goarch, variant))
+			}
 		}
 	}
 	return
 }
 
+// variantsOf returns the variants of the given goarch the build is made for
+func variantsOf(build config.Build, goarch string) []string {
+	switch VariantEnv(goarch) {
+	case "GOAMD64":
+		return build.Goamd64
+	case "GO386":
+		return build.Go386
+	case "GOMIPS", "GOMIPS64":
+		return build.Gomips
+	case "GOPPC64":
+		return build.Goppc64
+	}
+	return nil
+}
+
 func ignored(build config.Build, target Target) bool {
 	for _, ig := range build.Ignore {
-		if ig.Goos != "" && ig.Goos != target.OS {
-			continue
-		}
-		if ig.Goarch != "" && ig.Goarch != target.Arch {
-			continue
-		}
-		if ig.Goarm != "" && ig.Goarm != target.Arm {
+		if !matches(ig.Goos, target.OS) ||
+			!matches(ig.Goarch, target.Arch) ||
+			!matches(ig.Goarm, target.Arm) ||
+			!matches(ig.Goamd64, target.Amd64) ||
+			!matches(ig.Go386, target.I386) ||
+			!matches(ig.Gomips, target.Mips) ||
+			!matches(ig.Goppc64, target.Ppc64) {
 			continue
 		}
 		return true
@@ -54,47 +78,111 @@ func ignored(build config.Build, target Target) bool {
 	return false
 }
 
+// matches returns true if the given ignore rule field is empty or equal to
+// the value of the target
+func matches(rule, value string) bool {
+	return rule == "" || rule == value
+}
+
 func valid(target Target) bool {
-	var s = target.OS + target.Arch
-	for _, a := range validTargets {
-		if a == s {
+	return supported()[target.OS+"/"+target.Arch]
+}
+
+// ValidOS returns true if the go toolchain can build for the given GOOS
+func ValidOS(goos string) bool {
+	for platform := range supported() {
+		if strings.HasPrefix(platform, goos+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidArch returns true if the go toolchain can build for the given GOARCH
+func ValidArch(goarch string) bool {
+	for platform := range supported() {
+		if strings.HasSuffix(platform, "/"+goarch) {
 			return true
 		}
 	}
 	return false
 }
 
-// list from https://golang.org/doc/install/source#environment
-var validTargets = []string{
-	"androidarm",
-	"darwin386",
-	"darwinamd64",
-	// "darwinarm", - requires admin rights and other ios stuff
-	// "darwinarm64", - requires admin rights and other ios stuff
-	"dragonflyamd64",
-	"freebsd386",
-	"freebsdamd64",
-	"freebsdarm",
-	"linux386",
-	"linuxamd64",
-	"linuxarm",
-	"linuxarm64",
-	"linuxppc64",
-	"linuxppc64le",
-	"linuxmips",
-	"linuxmipsle",
-	"linuxmips64",
-	"linuxmips64le",
-	"linuxs390x",
-	"netbsd386",
-	"netbsdamd64",
-	"netbsdarm",
-	"openbsd386",
-	"openbsdamd64",
-	"openbsdarm",
-	"plan9386",
-	"plan9amd64",
-	"solarisamd64",
-	"windows386",
-	"windowsamd64",
+// ValidArm returns true if goreleaser can build for the given GOARM
+func ValidArm(goarm string) bool {
+	return contains(validArm, goarm)
+}
+
+// ValidAmd64 returns true if goreleaser can build for the given GOAMD64
+func ValidAmd64(goamd64 string) bool {
+	return contains(validAmd64, goamd64)
+}
+
+// Valid386 returns true if goreleaser can build for the given GO386
+func Valid386(go386 string) bool {
+	return contains(valid386, go386)
+}
+
+// ValidMips returns true if goreleaser can build for the given GOMIPS or
+// GOMIPS64
+func ValidMips(gomips string) bool {
+	return contains(validMips, gomips)
+}
+
+// ValidPpc64 returns true if goreleaser can build for the given GOPPC64
+func ValidPpc64(goppc64 string) bool {
+	return contains(validPpc64, goppc64)
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+var validArm = []string{"5", "6", "7"}
+
+var validAmd64 = []string{"v1", "v2", "v3", "v4"}
+
+var valid386 = []string{"sse2", "softfloat"}
+
+var validMips = []string{"hardfloat", "softfloat"}
+
+var validPpc64 = []string{"power8", "power9", "power10"}
+
+// fallbackTargets are the GOOS/GOARCH pairs used when the go toolchain
+// can't be queried, from https://golang.org/doc/install/source#environment
+var fallbackTargets = []string{
+	"android/arm",
+	"darwin/386",
+	"darwin/amd64",
+	"dragonfly/amd64",
+	"freebsd/386",
+	"freebsd/amd64",
+	"freebsd/arm",
+	"linux/386",
+	"linux/amd64",
+	"linux/arm",
+	"linux/arm64",
+	"linux/ppc64",
+	"linux/ppc64le",
+	"linux/mips",
+	"linux/mipsle",
+	"linux/mips64",
+	"linux/mips64le",
+	"linux/s390x",
+	"netbsd/386",
+	"netbsd/amd64",
+	"netbsd/arm",
+	"openbsd/386",
+	"openbsd/amd64",
+	"openbsd/arm",
+	"plan9/386",
+	"plan9/amd64",
+	"solaris/amd64",
+	"windows/386",
+	"windows/amd64",
 }
@@ -9,6 +9,7 @@ import (
 )
 
 func TestAllBuildTargets(t *testing.T) {
+	defer withPlatforms(fallbackTargets...)()
 	var build = config.Build{
 		Goos: []string{
 			"linux",
@@ -55,7 +56,53 @@ func TestAllBuildTargets(t *testing.T) {
 	}, All(build))
 }
 
+func TestAllBuildTargetsWithVariants(t *testing.T) {
+	var build = config.Build{
+		Goos: []string{
+			"linux",
+			"windows",
+		},
+		Goarch: []string{
+			"amd64",
+			"386",
+			"mips",
+			"ppc64le",
+		},
+		Goamd64: []string{"v1", "v3"},
+		Gomips:  []string{"hardfloat", "softfloat"},
+		Ignore: []config.IgnoredBuild{
+			{
+				Goos:    "windows",
+				Goamd64: "v3",
+			},
+		},
+	}
+	assert.Equal(t, []Target{
+		WithVariant("linux", "amd64", "v1"),
+		WithVariant("linux", "amd64", "v3"),
+		New("linux", "386", ""),
+		WithVariant("linux", "mips", "hardfloat"),
+		WithVariant("linux", "mips", "softfloat"),
+		New("linux", "ppc64le", ""),
+		WithVariant("windows", "amd64", "v1"),
+		New("windows", "386", ""),
+	}, All(build))
+}
+
+func TestAllBuildTargetsNotSupported(t *testing.T) {
+	defer withPlatforms("linux/amd64", "windows/arm64")()
+	var build = config.Build{
+		Goos:   []string{"linux", "windows"},
+		Goarch: []string{"amd64", "arm64"},
+	}
+	assert.Equal(t, []Target{
+		New("linux", "amd64", ""),
+		New("windows", "arm64", ""),
+	}, All(build))
+}
+
 func TestGoosGoarchCombos(t *testing.T) {
+	defer withPlatforms(fallbackTargets...)()
 	var platforms = []struct {
 		os    string
 		arch  string
@@ -103,3 +150,39 @@ func TestGoosGoarchCombos(t *testing.T) {
 		})
 	}
 }
+
+func TestValidOSArchArm(t *testing.T) {
+	defer withPlatforms(fallbackTargets...)()
+	assert.True(t, ValidOS("linux"))
+	assert.True(t, ValidOS("windows"))
+	assert.False(t, ValidOS("linx"))
+	assert.True(t, ValidArch("amd64"))
+	assert.True(t, ValidArch("ppc64le"))
+	assert.False(t, ValidArch("x86_64"))
+	assert.True(t, ValidArm("6"))
+	assert.False(t, ValidArm("8"))
+}
+
+func TestValidOSArchFromToolchain(t *testing.T) {
+	defer withPlatforms("js/wasm", "linux/riscv64")()
+	assert.True(t, ValidOS("js"))
+	assert.True(t, ValidArch("riscv64"))
+	assert.False(t, ValidOS("windows"))
+	assert.False(t, ValidArch("amd64"))
+	assert.True(t, valid(New("js", "wasm", "")))
+	assert.False(t, valid(New("js", "riscv64", "")))
+}
+
+// withPlatforms makes the given GOOS/GOARCH pairs the ones supported by the
+// go toolchain, and returns a function restoring the previous ones
+func withPlatforms(platforms ...string) func() {
+	toolchain.Lock()
+	defer toolchain.Unlock()
+	var previous = toolchain.platforms
+	toolchain.platforms = setOf(platforms)
+	return func() {
+		toolchain.Lock()
+		defer toolchain.Unlock()
+		toolchain.platforms = previous
+	}
+}
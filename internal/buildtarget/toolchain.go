@@ -0,0 +1,125 @@
+package buildtarget
+
+import (
+	stdctx "context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/internal/runner"
+)
+
+// platform is a GOOS/GOARCH pair as listed by `go tool dist list -json`
+type platform struct {
+	GOOS   string
+	GOARCH string
+}
+
+// toolchain holds the GOOS/GOARCH pairs the go toolchain in the $PATH can
+// build for, keyed by "goos/goarch". It is only loaded once, when the first
+// target is validated.
+var toolchain struct {
+	sync.Mutex
+	platforms map[string]bool
+}
+
+// supported returns the GOOS/GOARCH pairs the go toolchain can build for,
+// falling back to the pairs known to goreleaser if it can't be queried
+func supported() map[string]bool {
+	toolchain.Lock()
+	defer toolchain.Unlock()
+	if toolchain.platforms != nil {
+		return toolchain.platforms
+	}
+	platforms, err := load()
+	if err != nil {
+		log.WithError(err).Warn("could not list the targets of the go toolchain, using the default ones")
+		platforms = setOf(fallbackTargets)
+	}
+	toolchain.platforms = platforms
+	return platforms
+}
+
+func load() (map[string]bool, error) {
+	version, err := runner.Output(stdctx.Background(), runner.Cmd{
+		Args: []string{"go", "version"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loadCached(cacheDir(), strings.TrimSpace(string(version)), func() ([]byte, error) {
+		return runner.Output(stdctx.Background(), runner.Cmd{
+			Args: []string{"go", "tool", "dist", "list", "-json"},
+		})
+	})
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// loadCached returns the GOOS/GOARCH pairs of the given toolchain version.
+// They are read from the cache dir if a previous run already listed them,
+// otherwise they are listed and cached.
+func loadCached(dir, version string, list func() ([]byte, error)) (map[string]bool, error) {
+	var path = filepath.Join(dir, unsafeChars.ReplaceAllString(version, "_")+".json")
+	bts, err := ioutil.ReadFile(path)
+	if err == nil {
+		if platforms, err := parse(bts); err == nil {
+			return platforms, nil
+		}
+	}
+	bts, err = list()
+	if err != nil {
+		return nil, err
+	}
+	platforms, err := parse(bts)
+	if err != nil {
+		return nil, err
+	}
+	// the cache is best-effort, a failure only means listing them again
+	// next time
+	if err := os.MkdirAll(dir, 0755); err == nil {
+		if err := ioutil.WriteFile(path, bts, 0644); err != nil {
+			log.WithError(err).Debug("could not cache the targets of the go toolchain")
+		}
+	}
+	return platforms, nil
+}
+
+func parse(bts []byte) (map[string]bool, error) {
+	var platforms []platform
+	if err := json.Unmarshal(bts, &platforms); err != nil {
+		return nil, err
+	}
+	var result = map[string]bool{}
+	for _, p := range platforms {
+		result[p.GOOS+"/"+p.GOARCH] = true
+	}
+	return result, nil
+}
+
+// cacheDir returns the directory where the targets of each toolchain
+// version are cached
+func cacheDir() string {
+	var dir = os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		if home := os.Getenv("HOME"); home != "" {
+			dir = filepath.Join(home, ".cache")
+		} else {
+			dir = os.TempDir()
+		}
+	}
+	return filepath.Join(dir, "goreleaser", "targets")
+}
+
+func setOf(list []string) map[string]bool {
+	var result = map[string]bool{}
+	for _, s := range list {
+		result[s] = true
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package buildtarget
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const distList = `[
+	{"GOOS": "darwin", "GOARCH": "arm64", "CgoSupported": true, "FirstClass": true},
+	{"GOOS": "linux", "GOARCH": "riscv64", "CgoSupported": true, "FirstClass": false},
+	{"GOOS": "windows", "GOARCH": "arm64", "CgoSupported": false, "FirstClass": false}
+]`
+
+func TestSupported(t *testing.T) {
+	assert.True(t, supported()[runtime.GOOS+"/"+runtime.GOARCH])
+}
+
+func TestLoadCached(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+	var calls int
+	var list = func() ([]byte, error) {
+		calls++
+		return []byte(distList), nil
+	}
+	for _, version := range []string{"go version go1.10 linux/amd64", "go version go1.10 linux/amd64", "go version devel +abc/def"} {
+		platforms, err := loadCached(folder, version, list)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{
+			"darwin/arm64":  true,
+			"linux/riscv64": true,
+			"windows/arm64": true,
+		}, platforms)
+	}
+	assert.Equal(t, 2, calls, "the second load of the same version should be cached")
+	files, err := ioutil.ReadDir(folder)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestLoadCachedInvalidCache(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+	assert.NoError(t, ioutil.WriteFile(folder+"/go1.10.json", []byte("nope"), 0644))
+	platforms, err := loadCached(folder, "go1.10", func() ([]byte, error) {
+		return []byte(distList), nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, platforms["linux/riscv64"])
+}
+
+func TestLoadCachedListFails(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(folder)
+	_, err = loadCached(folder, "go1.10", func() ([]byte, error) {
+		return nil, errors.New("no go here")
+	})
+	assert.EqualError(t, err, "no go here")
+	_, err = loadCached(folder, "go1.10", func() ([]byte, error) {
+		return []byte("not json"), nil
+	})
+	assert.Error(t, err)
+}
+
+func TestCacheDir(t *testing.T) {
+	var previous = os.Getenv("XDG_CACHE_HOME")
+	defer os.Setenv("XDG_CACHE_HOME", previous)
+	assert.NoError(t, os.Setenv("XDG_CACHE_HOME", "/tmp/cache"))
+	assert.Equal(t, "/tmp/cache/goreleaser/targets", cacheDir())
+}
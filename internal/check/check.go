@@ -0,0 +1,298 @@
+// Package check validates the config after the defaults were applied, so
+// invalid values are reported before a release starts instead of deep
+// into it.
+package check
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/buildtarget"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+)
+
+// Problem is an invalid value in the config
+type Problem struct {
+	// Path of the invalid field, e.g. `builds[0].goos[1]`
+	Path string
+	Err  error
+}
+
+func (p Problem) Error() string {
+	if p.Path == "" {
+		return p.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", p.Path, p.Err)
+}
+
+// Config returns all the problems found in the config of the given context
+func Config(ctx *context.Context) []Problem {
+	var c = checker{ids: map[string]bool{}}
+	var cfg = ctx.Config
+	// the id defaults to the binary, which several builds may share, e.g. to
+	// build it with different flags, so only the ids set explicitly must be
+	// unique
+	var explicit = map[string]bool{}
+	for i, build := range cfg.Builds {
+		var path = buildPath(cfg, i)
+		c.build(path, build)
+		explicit[build.ID] = explicit[build.ID] || build.ID != build.Binary
+		if c.ids[build.ID] && explicit[build.ID] {
+			c.add(path+".id", fmt.Errorf("duplicate build id %q", build.ID))
+		}
+		c.ids[build.ID] = true
+	}
+	if len(cfg.Archives) == 0 {
+		c.archive("archive", cfg.Archive)
+	} else if !reflect.DeepEqual(cfg.Archive, config.Archive{}) {
+		c.add("archive", fmt.Errorf("can't be used together with archives"))
+	}
+	for i, archive := range cfg.Archives {
+		c.archive(fmt.Sprintf("archives[%d]", i), archive)
+		c.archiveName(i, cfg.Archives)
+	}
+	c.builds("fpm.builds", cfg.FPM.Builds)
+	c.oneOf("fpm.packager", cfg.FPM.Packager, "native", "fpm")
+	c.builds("snapcraft.builds", cfg.Snapcraft.Builds)
+	c.builds("brew.builds", cfg.Brew.Builds)
+	if cfg.Brew.Goamd64 != "" {
+		c.valid("brew.goamd64", "GOAMD64", cfg.Brew.Goamd64, buildtarget.ValidAmd64)
+	}
+	c.template("checksum.name_template", cfg.Checksum.NameTemplate)
+	for i, format := range cfg.SBOM.Formats {
+		c.oneOf(fmt.Sprintf("sbom.formats[%d]", i), format, "cyclonedx", "spdx")
+	}
+	c.template("sbom.name_template", cfg.SBOM.NameTemplate)
+	c.builds("sbom.builds", cfg.SBOM.Builds)
+	c.template("provenance.builder_id", cfg.Provenance.BuilderID)
+	c.template("provenance.name_template", cfg.Provenance.NameTemplate)
+	c.template("snapshot.name_template", cfg.Snapshot.NameTemplate)
+	c.template("release.name_template", cfg.Release.NameTemplate)
+	c.template("release.body_template", cfg.Release.BodyTemplate)
+	if cfg.Release.BodyTemplate != "" && cfg.Release.BodyTemplateFile != "" {
+		c.add("release.body_template_file", fmt.Errorf("can't be used together with body_template"))
+	}
+	c.oneOf("changelog.sort", cfg.Changelog.Sort, "", "asc", "desc")
+	c.oneOf("release.prerelease", cfg.Release.Prerelease, "", "true", "false", "auto")
+	for i, exclude := range cfg.Changelog.Filters.Exclude {
+		_, err := regexp.Compile(exclude)
+		c.add(fmt.Sprintf("changelog.filters.exclude[%d]", i), err)
+	}
+	c.oneOf("sign.artifacts", cfg.Sign.Artifacts, "none", "all", "checksum")
+	c.oneOf("snapcraft.grade", cfg.Snapcraft.Grade, "", "stable", "devel")
+	c.oneOf("snapcraft.confinement", cfg.Snapcraft.Confinement, "", "strict", "devmode", "classic")
+	for i, docker := range cfg.Dockers {
+		c.template(fmt.Sprintf("dockers[%d].tag_template", i), docker.TagTemplate)
+		c.builds(fmt.Sprintf("dockers[%d].builds", i), docker.Builds)
+		c.docker(fmt.Sprintf("dockers[%d]", i), docker)
+	}
+	for i, artifactory := range cfg.Artifactories {
+		var path = fmt.Sprintf("artifactories[%d]", i)
+		c.template(path+".target", artifactory.Target)
+		c.builds(path+".builds", artifactory.Builds)
+		c.oneOf(path+".mode", strings.ToLower(artifactory.Mode), "archive", "binary")
+	}
+	for i, plugin := range cfg.Plugins {
+		if plugin.Cmd == "" {
+			c.add(fmt.Sprintf("plugins[%d].cmd", i), fmt.Errorf("cmd is required"))
+		}
+	}
+	for i, hook := range cfg.Before.Hooks {
+		c.template(fmt.Sprintf("before.hooks[%d].cmd", i), hook.Cmd)
+	}
+	for i, hook := range cfg.After.Hooks {
+		c.template(fmt.Sprintf("after.hooks[%d].cmd", i), hook.Cmd)
+	}
+	c.retry(cfg.Retry)
+	return c.problems
+}
+
+type checker struct {
+	problems []Problem
+	// ids of the builds, to check the builds filters against
+	ids map[string]bool
+}
+
+func (c *checker) add(path string, err error) {
+	if err != nil {
+		c.problems = append(c.problems, Problem{Path: path, Err: err})
+	}
+}
+
+func (c *checker) template(path, s string) {
+	c.add(path, tmpl.Validate(s))
+}
+
+func (c *checker) oneOf(path, value string, valid ...string) {
+	for _, v := range valid {
+		if v == value {
+			return
+		}
+	}
+	var names []string
+	for _, v := range valid {
+		if v != "" {
+			names = append(names, v)
+		}
+	}
+	c.add(path, fmt.Errorf("invalid value %q, must be one of %s", value, strings.Join(names, ", ")))
+}
+
+func (c *checker) retry(retry config.Retry) {
+	if retry.Attempts < 0 {
+		c.add("retry.attempts", fmt.Errorf("must not be negative"))
+	}
+	if retry.Delay < 0 {
+		c.add("retry.delay", fmt.Errorf("must not be negative"))
+	}
+	if retry.MaxDelay < 0 {
+		c.add("retry.max_delay", fmt.Errorf("must not be negative"))
+	}
+	for i, code := range retry.StatusCodes {
+		if code < 100 || code > 599 {
+			c.add(fmt.Sprintf("retry.status_codes[%d]", i), fmt.Errorf("invalid HTTP status code %d", code))
+		}
+	}
+}
+
+func (c *checker) build(path string, build config.Build) {
+	c.template(path+".ldflags", build.Ldflags)
+	for i, goos := range build.Goos {
+		c.valid(fmt.Sprintf("%s.goos[%d]", path, i), "GOOS", goos, buildtarget.ValidOS)
+	}
+	for i, goarch := range build.Goarch {
+		c.valid(fmt.Sprintf("%s.goarch[%d]", path, i), "GOARCH", goarch, buildtarget.ValidArch)
+	}
+	for i, goarm := range build.Goarm {
+		c.valid(fmt.Sprintf("%s.goarm[%d]", path, i), "GOARM", goarm, buildtarget.ValidArm)
+	}
+	for i, goamd64 := range build.Goamd64 {
+		c.valid(fmt.Sprintf("%s.goamd64[%d]", path, i), "GOAMD64", goamd64, buildtarget.ValidAmd64)
+	}
+	for i, go386 := range build.Go386 {
+		c.valid(fmt.Sprintf("%s.go386[%d]", path, i), "GO386", go386, buildtarget.Valid386)
+	}
+	for i, gomips := range build.Gomips {
+		c.valid(fmt.Sprintf("%s.gomips[%d]", path, i), "GOMIPS", gomips, buildtarget.ValidMips)
+	}
+	for i, goppc64 := range build.Goppc64 {
+		c.valid(fmt.Sprintf("%s.goppc64[%d]", path, i), "GOPPC64", goppc64, buildtarget.ValidPpc64)
+	}
+	for i, ignore := range build.Ignore {
+		var ipath = fmt.Sprintf("%s.ignore[%d]", path, i)
+		if ignore.Goos != "" {
+			c.valid(ipath+".goos", "GOOS", ignore.Goos, buildtarget.ValidOS)
+		}
+		if ignore.Goarch != "" {
+			c.valid(ipath+".goarch", "GOARCH", ignore.Goarch, buildtarget.ValidArch)
+		}
+	}
+	for i, override := range build.Overrides {
+		var opath = fmt.Sprintf("%s.overrides[%d]", path, i)
+		if override.Goos != "" {
+			c.valid(opath+".goos", "GOOS", override.Goos, buildtarget.ValidOS)
+		}
+		if override.Goarch != "" {
+			c.valid(opath+".goarch", "GOARCH", override.Goarch, buildtarget.ValidArch)
+		}
+		if override.Goarm != "" {
+			c.valid(opath+".goarm", "GOARM", override.Goarm, buildtarget.ValidArm)
+		}
+		c.template(opath+".ldflags", override.Ldflags)
+	}
+	if len(buildtarget.All(build)) == 0 {
+		c.add(path, fmt.Errorf("no valid build targets"))
+	}
+}
+
+func (c *checker) builds(path string, ids []string) {
+	for i, id := range ids {
+		if !c.ids[id] {
+			c.add(fmt.Sprintf("%s[%d]", path, i), fmt.Errorf("unknown build id %q", id))
+		}
+	}
+}
+
+func (c *checker) valid(path, name, value string, valid func(string) bool) {
+	if !valid(value) {
+		c.add(path, fmt.Errorf("unknown %s %q", name, value))
+	}
+}
+
+func (c *checker) docker(path string, docker config.Docker) {
+	if docker.Goamd64 != "" {
+		c.valid(path+".goamd64", "GOAMD64", docker.Goamd64, buildtarget.ValidAmd64)
+	}
+	if docker.Go386 != "" {
+		c.valid(path+".go386", "GO386", docker.Go386, buildtarget.Valid386)
+	}
+	if docker.Gomips != "" {
+		c.valid(path+".gomips", "GOMIPS", docker.Gomips, buildtarget.ValidMips)
+	}
+	if docker.Goppc64 != "" {
+		c.valid(path+".goppc64", "GOPPC64", docker.Goppc64, buildtarget.ValidPpc64)
+	}
+}
+
+// archiveName checks that the i-th archive isn't named like a previous one
+// including the binaries of the same builds, as they would overwrite each
+// other
+func (c *checker) archiveName(i int, archives []config.Archive) {
+	for j, other := range archives[:i] {
+		if archives[i].NameTemplate == other.NameTemplate &&
+			archives[i].Format == other.Format &&
+			overlap(archives[i].Builds, other.Builds) {
+			c.add(
+				fmt.Sprintf("archives[%d].name_template", i),
+				fmt.Errorf("same name as archives[%d], which includes the same builds", j),
+			)
+			return
+		}
+	}
+}
+
+// overlap returns true if the given builds filters share a build, an empty
+// filter including all of them
+func overlap(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *checker) archive(path string, archive config.Archive) {
+	var formats = []string{"tar.gz", "zip", "binary"}
+	c.template(path+".name_template", archive.NameTemplate)
+	c.oneOf(path+".format", archive.Format, formats...)
+	for i, override := range archive.FormatOverrides {
+		var opath = fmt.Sprintf("%s.format_overrides[%d]", path, i)
+		c.valid(opath+".goos", "GOOS", override.Goos, buildtarget.ValidOS)
+		c.oneOf(opath+".format", override.Format, formats...)
+	}
+	for i, glob := range archive.Files {
+		_, err := filepath.Match(glob, "")
+		c.add(fmt.Sprintf("%s.files[%d]", path, i), err)
+	}
+	c.builds(path+".builds", archive.Builds)
+}
+
+// buildPath returns the path of the given build, which is `build` if the
+// builds were defaulted from the single build section
+func buildPath(cfg config.Project, i int) string {
+	if len(cfg.Builds) == 1 && !reflect.DeepEqual(cfg.SingleBuild, config.Build{}) {
+		return "build"
+	}
+	return fmt.Sprintf("builds[%d]", i)
+}
@@ -0,0 +1,173 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() config.Project {
+	return config.Project{
+		Builds: []config.Build{
+			{
+				Goos:    []string{"linux", "darwin"},
+				Goarch:  []string{"amd64", "arm"},
+				Goarm:   []string{"6"},
+				Ldflags: "-X main.version={{.Version}}",
+			},
+		},
+		Archive: config.Archive{
+			Format:       "tar.gz",
+			NameTemplate: "{{ .ProjectName }}_{{ .Os }}",
+			Files:        []string{"README*"},
+		},
+		FPM:  config.FPM{Packager: "native"},
+		Sign: config.Sign{Artifacts: "none"},
+	}
+}
+
+func TestValid(t *testing.T) {
+	assert.Empty(t, Config(context.New(validConfig())))
+}
+
+func TestInvalid(t *testing.T) {
+	var cfg = validConfig()
+	cfg.Builds[0].Goos = []string{"linux", "linx"}
+	cfg.Builds[0].Goarch = []string{"amd64", "x86_64"}
+	cfg.Builds[0].Goarm = []string{"9"}
+	cfg.Builds[0].Goamd64 = []string{"v3", "v5"}
+	cfg.Builds[0].Go386 = []string{"387"}
+	cfg.Builds[0].Gomips = []string{"soft"}
+	cfg.Builds[0].Goppc64 = []string{"power7"}
+	cfg.Builds[0].Ldflags = "{{ .Version }"
+	cfg.Builds[0].Ignore = []config.IgnoredBuild{{Goos: "windoze"}}
+	cfg.Builds[0].Overrides = []config.BuildOverride{{Goarch: "amd65", Ldflags: "{{ .Tag }"}}
+	cfg.Archive.Format = "rar"
+	cfg.Archive.FormatOverrides = []config.FormatOverride{{Goos: "windows", Format: "7z"}}
+	cfg.Archive.Files = []string{"[x-]"}
+	cfg.FPM.Packager = "alien"
+	cfg.Checksum.NameTemplate = "{{ .Nope }"
+	cfg.Changelog.Sort = "random"
+	cfg.Release.Prerelease = "maybe"
+	cfg.Release.BodyTemplate = "{{ .ReleaseNotes }"
+	cfg.Release.BodyTemplateFile = "body.md"
+	cfg.Changelog.Filters.Exclude = []string{"^docs:", "(foo"}
+	cfg.Sign.Artifacts = "some"
+	cfg.Brew.Goamd64 = "v0"
+	cfg.Dockers = []config.Docker{{TagTemplate: "{{ .Tag }", Goamd64: "3", Gomips: "soft"}}
+	cfg.Artifactories = []config.Artifactory{{Mode: "tarball", Target: "http://{{ .Os }"}}
+	cfg.Plugins = []config.Plugin{{Name: "cdn"}}
+	cfg.Before.Hooks = []config.Hook{{Cmd: "echo {{ .Tag }"}}
+	cfg.Retry = config.Retry{Attempts: -1, StatusCodes: []int{502, 42}}
+	var paths []string
+	for _, problem := range Config(context.New(cfg)) {
+		paths = append(paths, problem.Path)
+	}
+	assert.Equal(t, []string{
+		"builds[0].ldflags",
+		"builds[0].goos[1]",
+		"builds[0].goarch[1]",
+		"builds[0].goarm[0]",
+		"builds[0].goamd64[1]",
+		"builds[0].go386[0]",
+		"builds[0].gomips[0]",
+		"builds[0].goppc64[0]",
+		"builds[0].ignore[0].goos",
+		"builds[0].overrides[0].goarch",
+		"builds[0].overrides[0].ldflags",
+		"archive.format",
+		"archive.format_overrides[0].format",
+		"archive.files[0]",
+		"fpm.packager",
+		"brew.goamd64",
+		"checksum.name_template",
+		"release.body_template",
+		"release.body_template_file",
+		"changelog.sort",
+		"release.prerelease",
+		"changelog.filters.exclude[1]",
+		"sign.artifacts",
+		"dockers[0].tag_template",
+		"dockers[0].goamd64",
+		"dockers[0].gomips",
+		"artifactories[0].target",
+		"artifactories[0].mode",
+		"plugins[0].cmd",
+		"before.hooks[0].cmd",
+		"retry.attempts",
+		"retry.status_codes[1]",
+	}, paths)
+}
+
+func TestBuildIDs(t *testing.T) {
+	var cfg = validConfig()
+	cfg.Builds[0].ID = "server"
+	cfg.Builds = append(cfg.Builds, cfg.Builds[0], cfg.Builds[0])
+	cfg.Builds[1].ID = "client"
+	cfg.Archives = []config.Archive{cfg.Archive, cfg.Archive}
+	cfg.Archives[0].Builds = []string{"server"}
+	cfg.Archives[1].Builds = []string{"client", "agent"}
+	cfg.Brew.Builds = []string{"cli"}
+	cfg.Dockers = []config.Docker{{Builds: []string{"server"}}}
+	var problems = Config(context.New(cfg))
+	assert.Len(t, problems, 4)
+	assert.EqualError(t, problems[0], `builds[2].id: duplicate build id "server"`)
+	assert.EqualError(t, problems[1], `archive: can't be used together with archives`)
+	assert.EqualError(t, problems[2], `archives[1].builds[1]: unknown build id "agent"`)
+	assert.EqualError(t, problems[3], `brew.builds[0]: unknown build id "cli"`)
+}
+
+func TestBuildIDsDefaultToBinary(t *testing.T) {
+	var cfg = validConfig()
+	cfg.Builds[0].Binary = "app"
+	cfg.Builds[0].ID = "app"
+	cfg.Builds = append(cfg.Builds, cfg.Builds[0])
+	assert.Empty(t, Config(context.New(cfg)))
+
+	cfg.Builds = append(cfg.Builds, cfg.Builds[0])
+	cfg.Builds[2].Binary = "other"
+	var problems = Config(context.New(cfg))
+	assert.Len(t, problems, 1)
+	assert.EqualError(t, problems[0], `builds[2].id: duplicate build id "app"`)
+}
+
+func TestArchiveNames(t *testing.T) {
+	var cfg = validConfig()
+	cfg.Builds[0].ID = "server"
+	cfg.Builds = append(cfg.Builds, cfg.Builds[0])
+	cfg.Builds[1].ID = "client"
+	cfg.Archives = []config.Archive{cfg.Archive, cfg.Archive, cfg.Archive, cfg.Archive}
+	cfg.Archive = config.Archive{}
+	cfg.Archives[0].Builds = []string{"server"}
+	cfg.Archives[1].Builds = []string{"client"}
+	cfg.Archives[2].Format = "zip"
+	var problems = Config(context.New(cfg))
+	assert.Len(t, problems, 1)
+	assert.EqualError(t, problems[0], `archives[3].name_template: same name as archives[0], which includes the same builds`)
+}
+
+func TestProblemMessages(t *testing.T) {
+	var cfg = validConfig()
+	cfg.Builds[0].Goos = []string{"linx"}
+	cfg.Changelog.Sort = "random"
+	var problems = Config(context.New(cfg))
+	assert.Len(t, problems, 3)
+	assert.EqualError(t, problems[0], `builds[0].goos[0]: unknown GOOS "linx"`)
+	assert.EqualError(t, problems[1], `builds[0]: no valid build targets`)
+	assert.EqualError(t, problems[2], `changelog.sort: invalid value "random", must be one of asc, desc`)
+}
+
+func TestSingleBuildPath(t *testing.T) {
+	var cfg = validConfig()
+	cfg.SingleBuild = cfg.Builds[0]
+	cfg.Builds[0].Goos = []string{"linx", "linux"}
+	var problems = Config(context.New(cfg))
+	assert.Len(t, problems, 1)
+	assert.Equal(t, "build.goos[0]", problems[0].Path)
+}
+
+func TestProblemWithoutPath(t *testing.T) {
+	assert.EqualError(t, Problem{Err: assert.AnError}, assert.AnError.Error())
+}
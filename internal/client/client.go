@@ -15,9 +15,30 @@ type Info struct {
 	URL         string
 }
 
+// Release created or updated by a client
+type Release struct {
+	ID int
+	// Draft is true if the release was created as a draft, so it is only
+	// visible once published with PublishRelease
+	Draft bool
+}
+
+// Asset of a release
+type Asset struct {
+	ID   int
+	Name string
+	Size int64
+}
+
 // Client interface
 type Client interface {
-	CreateRelease(ctx *context.Context, body string) (releaseID int, err error)
+	CreateRelease(ctx *context.Context, body string) (release Release, err error)
+	PublishRelease(ctx *context.Context, releaseID int) (err error)
+	DeleteRelease(ctx *context.Context, releaseID int) (err error)
+	GetFile(ctx *context.Context, path string) (content []byte, exists bool, err error)
 	CreateFile(ctx *context.Context, content bytes.Buffer, path string) (err error)
-	Upload(ctx *context.Context, releaseID int, name string, file *os.File) (err error)
+	DeleteFile(ctx *context.Context, path string) (err error)
+	Upload(ctx *context.Context, releaseID int, name string, file *os.File) (assetID int, err error)
+	ListAssets(ctx *context.Context, releaseID int) (assets []Asset, err error)
+	DeleteAsset(ctx *context.Context, assetID int) (err error)
 }
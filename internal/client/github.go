@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/apex/log"
 	"github.com/google/go-github/github"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/retry"
 	"golang.org/x/oauth2"
 )
 
 type githubClient struct {
 	client *github.Client
+	retry  retry.Policy
 }
 
 // NewGitHub returns a github client implementation
@@ -34,14 +37,87 @@ func NewGitHub(ctx *context.Context) (Client, error) {
 		client.UploadURL = upload
 	}
 
-	return &githubClient{client}, nil
+	return &githubClient{client: client, retry: retry.New(ctx.Config.Retry)}, nil
+}
+
+// temporary marks the errors worth retrying: the ones of the retry policy
+// and the GitHub rate limits, which say when to retry
+func (c *githubClient) temporary(res *github.Response, err error) error {
+	switch e := err.(type) {
+	case *github.RateLimitError:
+		return retry.Temporary(err, time.Until(e.Rate.Reset.Time))
+	case *github.AbuseRateLimitError:
+		return retry.Temporary(err, e.GetRetryAfter())
+	}
+	if res == nil {
+		return c.retry.HTTP(nil, err)
+	}
+	return c.retry.HTTP(res.Response, err)
+}
+
+func (c *githubClient) GetFile(ctx *context.Context, path string) ([]byte, bool, error) {
+	file, _, res, err := c.client.Repositories.GetContents(
+		ctx,
+		ctx.Config.Brew.GitHub.Owner,
+		ctx.Config.Brew.GitHub.Name,
+		path,
+		&github.RepositoryContentGetOptions{},
+	)
+	if err != nil && res != nil && res.StatusCode == 404 {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	content, err := file.GetContent()
+	return []byte(content), true, err
+}
+
+func (c *githubClient) DeleteFile(ctx *context.Context, path string) error {
+	file, _, _, err := c.client.Repositories.GetContents(
+		ctx,
+		ctx.Config.Brew.GitHub.Owner,
+		ctx.Config.Brew.GitHub.Name,
+		path,
+		&github.RepositoryContentGetOptions{},
+	)
+	if err != nil {
+		return err
+	}
+	_, _, err = c.client.Repositories.DeleteFile(
+		ctx,
+		ctx.Config.Brew.GitHub.Owner,
+		ctx.Config.Brew.GitHub.Name,
+		path,
+		&github.RepositoryContentFileOptions{
+			Committer: &github.CommitAuthor{
+				Name:  github.String(ctx.Config.Brew.CommitAuthor.Name),
+				Email: github.String(ctx.Config.Brew.CommitAuthor.Email),
+			},
+			Message: github.String(
+				ctx.Config.ProjectName + " version " + ctx.Git.CurrentTag + " rolled back",
+			),
+			SHA: file.SHA,
+		},
+	)
+	return err
 }
 
 func (c *githubClient) CreateFile(
 	ctx *context.Context,
 	content bytes.Buffer,
 	path string,
-) (err error) {
+) error {
+	return c.retry.Do(ctx, func() error {
+		return c.temporary(c.createFile(ctx, content, path))
+	})
+}
+
+func (c *githubClient) createFile(
+	ctx *context.Context,
+	content bytes.Buffer,
+	path string,
+) (*github.Response, error) {
 	options := &github.RepositoryContentFileOptions{
 		Committer: &github.CommitAuthor{
 			Name:  github.String(ctx.Config.Brew.CommitAuthor.Name),
@@ -60,39 +136,44 @@ func (c *githubClient) CreateFile(
 		path,
 		&github.RepositoryContentGetOptions{},
 	)
-	if err != nil && res.StatusCode == 404 {
-		_, _, err = c.client.Repositories.CreateFile(
+	if err != nil && res != nil && res.StatusCode == 404 {
+		_, res, err = c.client.Repositories.CreateFile(
 			ctx,
 			ctx.Config.Brew.GitHub.Owner,
 			ctx.Config.Brew.GitHub.Name,
 			path,
 			options,
 		)
-		return
+		return res, err
+	}
+	if err != nil {
+		return res, err
 	}
 	options.SHA = file.SHA
-	_, _, err = c.client.Repositories.UpdateFile(
+	_, res, err = c.client.Repositories.UpdateFile(
 		ctx,
 		ctx.Config.Brew.GitHub.Owner,
 		ctx.Config.Brew.GitHub.Name,
 		path,
 		options,
 	)
-	return
+	return res, err
 }
 
-func (c *githubClient) CreateRelease(ctx *context.Context, body string) (releaseID int, err error) {
+// CreateRelease creates a draft release for the current tag, or updates the
+// existing release of the tag, if any
+func (c *githubClient) CreateRelease(ctx *context.Context, body string) (Release, error) {
 	var release *github.RepositoryRelease
 	title, err := releaseTitle(ctx)
 	if err != nil {
-		return 0, err
+		return Release{}, err
 	}
 	var data = &github.RepositoryRelease{
 		Name:       github.String(title),
 		TagName:    github.String(ctx.Git.CurrentTag),
 		Body:       github.String(body),
 		Draft:      github.Bool(ctx.Config.Release.Draft),
-		Prerelease: github.Bool(ctx.Config.Release.Prerelease),
+		Prerelease: github.Bool(ctx.PreRelease()),
 	}
 	release, _, err = c.client.Repositories.GetReleaseByTag(
 		ctx,
@@ -101,23 +182,47 @@ func (c *githubClient) CreateRelease(ctx *context.Context, body string) (release
 		ctx.Git.CurrentTag,
 	)
 	if err != nil {
+		data.Draft = github.Bool(true)
 		release, _, err = c.client.Repositories.CreateRelease(
 			ctx,
 			ctx.Config.Release.GitHub.Owner,
 			ctx.Config.Release.GitHub.Name,
 			data,
 		)
-	} else {
-		release, _, err = c.client.Repositories.EditRelease(
-			ctx,
-			ctx.Config.Release.GitHub.Owner,
-			ctx.Config.Release.GitHub.Name,
-			release.GetID(),
-			data,
-		)
+		log.WithField("url", release.GetHTMLURL()).Info("draft release created")
+		return Release{ID: release.GetID(), Draft: true}, err
 	}
+	release, _, err = c.client.Repositories.EditRelease(
+		ctx,
+		ctx.Config.Release.GitHub.Owner,
+		ctx.Config.Release.GitHub.Name,
+		release.GetID(),
+		data,
+	)
 	log.WithField("url", release.GetHTMLURL()).Info("release updated")
-	return release.GetID(), err
+	return Release{ID: release.GetID()}, err
+}
+
+func (c *githubClient) PublishRelease(ctx *context.Context, releaseID int) error {
+	release, _, err := c.client.Repositories.EditRelease(
+		ctx,
+		ctx.Config.Release.GitHub.Owner,
+		ctx.Config.Release.GitHub.Name,
+		releaseID,
+		&github.RepositoryRelease{Draft: github.Bool(false)},
+	)
+	log.WithField("url", release.GetHTMLURL()).Info("release published")
+	return err
+}
+
+func (c *githubClient) DeleteRelease(ctx *context.Context, releaseID int) error {
+	_, err := c.client.Repositories.DeleteRelease(
+		ctx,
+		ctx.Config.Release.GitHub.Owner,
+		ctx.Config.Release.GitHub.Name,
+		releaseID,
+	)
+	return err
 }
 
 func (c *githubClient) Upload(
@@ -125,16 +230,65 @@ func (c *githubClient) Upload(
 	releaseID int,
 	name string,
 	file *os.File,
-) (err error) {
-	_, _, err = c.client.Repositories.UploadReleaseAsset(
+) (int, error) {
+	var assetID int
+	var err = c.retry.Do(ctx, func() error {
+		// the file is closed once uploaded, so each attempt reopens it
+		file, err := os.Open(file.Name())
+		if err != nil {
+			return err
+		}
+		defer file.Close() // nolint: errcheck
+		asset, res, err := c.client.Repositories.UploadReleaseAsset(
+			ctx,
+			ctx.Config.Release.GitHub.Owner,
+			ctx.Config.Release.GitHub.Name,
+			releaseID,
+			&github.UploadOptions{
+				Name: name,
+			},
+			file,
+		)
+		assetID = asset.GetID()
+		return c.temporary(res, err)
+	})
+	return assetID, err
+}
+
+func (c *githubClient) ListAssets(ctx *context.Context, releaseID int) ([]Asset, error) {
+	var result []Asset
+	var opts = &github.ListOptions{PerPage: 100}
+	for {
+		assets, res, err := c.client.Repositories.ListReleaseAssets(
+			ctx,
+			ctx.Config.Release.GitHub.Owner,
+			ctx.Config.Release.GitHub.Name,
+			releaseID,
+			opts,
+		)
+		if err != nil {
+			return result, err
+		}
+		for _, asset := range assets {
+			result = append(result, Asset{
+				ID:   asset.GetID(),
+				Name: asset.GetName(),
+				Size: int64(asset.GetSize()),
+			})
+		}
+		if res.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+func (c *githubClient) DeleteAsset(ctx *context.Context, assetID int) error {
+	_, err := c.client.Repositories.DeleteReleaseAsset(
 		ctx,
 		ctx.Config.Release.GitHub.Owner,
 		ctx.Config.Release.GitHub.Name,
-		releaseID,
-		&github.UploadOptions{
-			Name: name,
-		},
-		file,
+		assetID,
 	)
-	return
+	return err
 }
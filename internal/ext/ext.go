@@ -7,5 +7,8 @@ func For(target buildtarget.Target) (ext string) {
 	if target.OS == "windows" {
 		ext = ".exe"
 	}
+	if target.Arch == "wasm" {
+		ext = ".wasm"
+	}
 	return
 }
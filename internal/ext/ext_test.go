@@ -17,3 +17,8 @@ func TestExtOthers(t *testing.T) {
 	assert.Empty(t, "", For(buildtarget.New("linuxwin", "", "")))
 	assert.Empty(t, "", For(buildtarget.New("winasdasd", "sad", "6")))
 }
+
+func TestExtWasm(t *testing.T) {
+	assert.Equal(t, ".wasm", For(buildtarget.New("js", "wasm", "")))
+	assert.Equal(t, ".wasm", For(buildtarget.New("wasip1", "wasm", "")))
+}
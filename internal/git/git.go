@@ -2,28 +2,48 @@
 package git
 
 import (
+	"context"
 	"errors"
-	"os/exec"
 	"strings"
+
+	"github.com/goreleaser/goreleaser/internal/runner"
 )
 
 // IsRepo returns true if current folder is a git repository
-func IsRepo() bool {
-	out, err := Run("rev-parse", "--is-inside-work-tree")
+func IsRepo(ctx context.Context) bool {
+	out, err := Run(ctx, "rev-parse", "--is-inside-work-tree")
 	return err == nil && strings.TrimSpace(out) == "true"
 }
 
-// Run runs a git command and returns its output or errors
-func Run(args ...string) (output string, err error) {
-	/* #nosec */
-	var cmd = exec.Command("git", args...)
-	bts, err := cmd.CombinedOutput()
+// Run runs a git command and returns its output or errors.
+// The command is killed if the given context is done.
+func Run(ctx context.Context, args ...string) (output string, err error) {
+	bts, err := runner.Query(ctx, runner.Cmd{
+		Args: append([]string{"git"}, args...),
+	})
 	if err != nil {
 		return "", errors.New(string(bts))
 	}
 	return string(bts), err
 }
 
+// LatestTag returns the latest tag reachable from the given refs, or from
+// HEAD if none are given. If prefix is not empty, only the tags starting
+// with it are considered.
+func LatestTag(ctx context.Context, prefix string, refs ...string) (string, error) {
+	var args = []string{"describe", "--tags", "--abbrev=0"}
+	if prefix != "" {
+		args = append(args, "--match", prefix+"*")
+	}
+	return Clean(Run(ctx, append(args, refs...)...))
+}
+
+// VersionOf returns the version of the given tag, which is the tag without
+// the given prefix and the usual `v` prefix
+func VersionOf(tag, prefix string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(tag, prefix), "v")
+}
+
 // Clean the output
 func Clean(output string, err error) (string, error) {
 	return strings.Replace(strings.Split(output, "\n")[0], "'", "", -1), err
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -8,11 +9,11 @@ import (
 )
 
 func TestGit(t *testing.T) {
-	out, err := Run("status")
+	out, err := Run(context.Background(), "status")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, out)
 
-	out, err = Run("command-that-dont-exist")
+	out, err = Run(context.Background(), "command-that-dont-exist")
 	assert.Error(t, err)
 	assert.Empty(t, out)
 	assert.Equal(
@@ -23,10 +24,10 @@ func TestGit(t *testing.T) {
 }
 
 func TestRepo(t *testing.T) {
-	assert.True(t, IsRepo(), "goreleaser folder should be a git repo")
+	assert.True(t, IsRepo(context.Background()), "goreleaser folder should be a git repo")
 
 	assert.NoError(t, os.Chdir(os.TempDir()))
-	assert.False(t, IsRepo(), os.TempDir()+" folder should be a git repo")
+	assert.False(t, IsRepo(context.Background()), os.TempDir()+" folder should be a git repo")
 }
 
 func TestClean(t *testing.T) {
@@ -35,3 +36,10 @@ func TestClean(t *testing.T) {
 	assert.Equal(t, "asdasd ssadas", out)
 
 }
+
+func TestVersionOf(t *testing.T) {
+	assert.Equal(t, "1.4.0", VersionOf("v1.4.0", ""))
+	assert.Equal(t, "1.4.0", VersionOf("1.4.0", ""))
+	assert.Equal(t, "1.4.0", VersionOf("cli/v1.4.0", "cli/"))
+	assert.Equal(t, "cli/v1.4.0", VersionOf("cli/v1.4.0", "agent/"))
+}
@@ -0,0 +1,153 @@
+package linux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1" // #nosec
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// apkArchs are the alpine names of the linux archs that differ from the
+// debian ones
+var apkArchs = map[string]string{
+	"amd64":   "x86_64",
+	"i386":    "x86",
+	"arm64":   "aarch64",
+	"ppc64el": "ppc64le",
+	"loong64": "loongarch64",
+}
+
+// APKArch converts a goarch and its variant to an alpine arch
+func APKArch(goarch, variant string) string {
+	if goarch == "arm" {
+		if variant == "7" || variant == "" {
+			return "armv7"
+		}
+		return "armhf"
+	}
+	var arch = Arch(goarch, variant)
+	if apk, ok := apkArchs[arch]; ok {
+		return apk
+	}
+	return arch
+}
+
+// apkVersion returns the given version as an alpine one, whose suffixes
+// start with an underscore, and which ends with the release number,
+// e.g. 1.0.0-rc1 becomes 1.0.0_rc1-r0
+func apkVersion(version string) string {
+	return strings.Replace(version, "-", "_", -1) + "-r0"
+}
+
+// WriteAPK writes the given package as an unsigned .apk, which is the
+// concatenation of the gzipped control and data tarballs. The control
+// tarball has the .PKGINFO file, with the checksum of the data tarball.
+func WriteAPK(w io.Writer, p Package) error {
+	files, err := p.contents()
+	if err != nil {
+		return err
+	}
+	data, err := apkData(p, files)
+	if err != nil {
+		return err
+	}
+	control, err := apkControl(p, files, data)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(control); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func apkControl(p Package, files contents, data []byte) ([]byte, error) {
+	var info bytes.Buffer
+	var field = func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&info, "%s = %s\n", name, value)
+		}
+	}
+	deps, err := parseDependencies(p.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	conflicts, err := parseDependencies(p.Conflicts)
+	if err != nil {
+		return nil, err
+	}
+	var summary, _ = p.description()
+	fmt.Fprintln(&info, "# Generated by goreleaser")
+	field("pkgname", p.Name)
+	field("pkgver", apkVersion(p.Version))
+	field("pkgdesc", summary)
+	field("url", p.Homepage)
+	field("builddate", fmt.Sprint(p.Date.Unix()))
+	field("packager", p.Maintainer)
+	field("size", fmt.Sprint(files.size()))
+	field("arch", APKArch(p.Goarch, p.Variant))
+	field("origin", p.Name)
+	field("maintainer", p.Maintainer)
+	field("license", p.License)
+	for _, dep := range deps {
+		field("depend", dep.Name+dep.Op+dep.Version)
+	}
+	for _, conflict := range conflicts {
+		field("depend", "!"+conflict.Name+conflict.Op+conflict.Version)
+	}
+	field("datahash", fmt.Sprintf("%x", sha256.Sum256(data)))
+
+	var buf bytes.Buffer
+	var gw = gzip.NewWriter(&buf)
+	var tw = tar.NewWriter(gw)
+	if err := writeTarFile(tw, ".PKGINFO", 0644, info.Bytes(), p.Date); err != nil {
+		return nil, err
+	}
+	// the control tarball has no end of archive marker, so it is followed
+	// by the data one once they are concatenated
+	if err := tw.Flush(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func apkData(p Package, files contents) ([]byte, error) {
+	return targz(func(tw *tar.Writer) error {
+		for _, f := range files.tree() {
+			if f.Mode.IsDir() {
+				if err := writeTarDir(tw, f.Destination[1:]+"/", p.Date); err != nil {
+					return err
+				}
+				continue
+			}
+			// apk checks the files against these checksums
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     f.Destination[1:],
+				Mode:     int64(f.Mode),
+				Size:     int64(len(f.Data)),
+				ModTime:  p.Date,
+				Uname:    "root",
+				Gname:    "root",
+				PAXRecords: map[string]string{
+					"APK-TOOLS.checksum.SHA1": fmt.Sprintf("%x", sha1.Sum(f.Data)), // #nosec
+				},
+				Format: tar.FormatPAX,
+			}); err != nil {
+				return err
+			}
+			if _, err := tw.Write(f.Data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
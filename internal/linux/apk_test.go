@@ -0,0 +1,100 @@
+package linux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAPK(t *testing.T) {
+	folder, err := ioutil.TempDir("", "linuxpackage")
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, WriteAPK(&buf, testPackage(t, folder)))
+
+	// the package is two gzip streams, the second one being the data
+	var r = bytes.NewReader(buf.Bytes())
+	gr, err := gzip.NewReader(r)
+	assert.NoError(t, err)
+	gr.Multistream(false)
+	control, err := ioutil.ReadAll(gr)
+	assert.NoError(t, err)
+	var data = buf.Bytes()[buf.Len()-r.Len():]
+
+	// the control tarball has no end of archive marker
+	assert.Zero(t, len(control)%512)
+	var tr = tar.NewReader(bytes.NewReader(append(control, make([]byte, 1024)...)))
+	header, err := tr.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, ".PKGINFO", header.Name)
+	info, err := ioutil.ReadAll(tr)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"# Generated by goreleaser",
+		"pkgname = mybin",
+		"pkgver = 1.0.0_rc1-r0",
+		"pkgdesc = Fast drum rolls.",
+		"url = https://example.com",
+		"builddate = 1514862245",
+		"packager = Drummer <drum-roll@example.com>",
+		"size = 22",
+		"arch = x86_64",
+		"origin = mybin",
+		"maintainer = Drummer <drum-roll@example.com>",
+		"license = MIT",
+		"depend = git",
+		"depend = zsh>=5.0",
+		"depend = !svn",
+		fmt.Sprintf("datahash = %x", sha256.Sum256(data)),
+	}, "\n")+"\n", string(info))
+	_, err = tr.Next()
+	assert.Equal(t, io.EOF, err)
+
+	gr, err = gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	tr = tar.NewReader(gr)
+	var entries []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		entries = append(entries, fmt.Sprintf(
+			"%s %o %s", header.Name, header.Mode, header.PAXRecords["APK-TOOLS.checksum.SHA1"],
+		))
+	}
+	assert.Equal(t, []string{
+		"etc/ 755 ",
+		"etc/mybin/ 755 ",
+		"etc/mybin/mybin.conf 644 488068fe1e9468cd95a5f4812ed98b24d25fa997",
+		"usr/ 755 ",
+		"usr/local/ 755 ",
+		"usr/local/bin/ 755 ",
+		"usr/local/bin/mybin 755 b2b62c101a156f5f12dd7197cf7ae9424164b115",
+	}, entries)
+}
+
+func TestAPKArch(t *testing.T) {
+	for _, tt := range []struct {
+		goarch, variant, to string
+	}{
+		{"amd64", "v3", "x86_64"},
+		{"386", "sse2", "x86"},
+		{"arm64", "", "aarch64"},
+		{"arm", "6", "armhf"},
+		{"arm", "7", "armv7"},
+		{"ppc64le", "", "ppc64le"},
+		{"s390x", "", "s390x"},
+	} {
+		assert.Equal(t, tt.to, APKArch(tt.goarch, tt.variant), tt.goarch+tt.variant)
+	}
+}
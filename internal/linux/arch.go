@@ -1,19 +1,23 @@
 // Package linux contains functions that are useful to generate linux packages.
 package linux
 
-import "strings"
-
-// Arch converts a goarch to a linux-compatible arch
-func Arch(key string) string {
-	switch {
-	case strings.Contains(key, "amd64"):
-		return "amd64"
-	case strings.Contains(key, "386"):
+// Arch converts a goarch and its variant, e.g. the goarm, to a
+// linux-compatible arch
+func Arch(goarch, variant string) string {
+	switch goarch {
+	case "386":
 		return "i386"
-	case strings.Contains(key, "arm64"):
-		return "arm64"
-	case strings.Contains(key, "arm6"):
+	case "arm":
+		if variant == "5" {
+			return "armel"
+		}
 		return "armhf"
+	case "mipsle":
+		return "mipsel"
+	case "mips64le":
+		return "mips64el"
+	case "ppc64le":
+		return "ppc64el"
 	}
-	return key
+	return goarch
 }
@@ -8,15 +8,23 @@ import (
 )
 
 func TestArch(t *testing.T) {
-	for from, to := range map[string]string{
-		"amd64": "amd64",
-		"386":   "i386",
-		"arm64": "arm64",
-		"arm6":  "armhf",
-		"what":  "what",
+	for _, tt := range []struct {
+		goarch, variant, to string
+	}{
+		{"amd64", "", "amd64"},
+		{"386", "", "i386"},
+		{"arm64", "", "arm64"},
+		{"arm", "6", "armhf"},
+		{"arm", "7", "armhf"},
+		{"arm", "5", "armel"},
+		{"mipsle", "softfloat", "mipsel"},
+		{"mips64le", "", "mips64el"},
+		{"ppc64le", "power9", "ppc64el"},
+		{"amd64", "v3", "amd64"},
+		{"what", "", "what"},
 	} {
-		t.Run(fmt.Sprintf("%s to %s", from, to), func(t *testing.T) {
-			assert.Equal(t, to, Arch(from))
+		t.Run(fmt.Sprintf("%s%s to %s", tt.goarch, tt.variant, tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.to, Arch(tt.goarch, tt.variant))
 		})
 	}
 }
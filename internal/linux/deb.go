@@ -0,0 +1,221 @@
+package linux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5" // #nosec
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteDeb writes the given package as a .deb, which is an ar archive of the
+// debian-binary version file, and of the control and data tarballs
+func WriteDeb(w io.Writer, p Package) error {
+	files, err := p.contents()
+	if err != nil {
+		return err
+	}
+	control, err := debControl(p, files)
+	if err != nil {
+		return err
+	}
+	data, err := debData(p, files)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "!<arch>\n"); err != nil {
+		return err
+	}
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{"debian-binary", []byte("2.0\n")},
+		{"control.tar.gz", control},
+		{"data.tar.gz", data},
+	} {
+		if err := writeAr(w, entry.name, entry.data, p.Date); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAr writes an entry of an ar archive, whose header is a fixed-width
+// text record
+func writeAr(w io.Writer, name string, data []byte, date time.Time) error {
+	var header = fmt.Sprintf(
+		"%-16s%-12d%-6d%-6d%-8s%-10d`\n",
+		name, date.Unix(), 0, 0, "100644", len(data),
+	)
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	// the entries are aligned on 2 bytes
+	if len(data)%2 != 0 {
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	return nil
+}
+
+func debControl(p Package, files contents) ([]byte, error) {
+	var control bytes.Buffer
+	var field = func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&control, "%s: %s\n", name, value)
+		}
+	}
+	depends, err := debDependencies(p.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	conflicts, err := debDependencies(p.Conflicts)
+	if err != nil {
+		return nil, err
+	}
+	field("Package", p.Name)
+	field("Version", p.Version)
+	field("Section", "default")
+	field("Priority", "optional")
+	field("Architecture", Arch(p.Goarch, p.Variant))
+	field("Maintainer", p.Maintainer)
+	field("Vendor", p.Vendor)
+	field("License", p.License)
+	field("Installed-Size", fmt.Sprint((files.size()+1023)/1024))
+	field("Depends", depends)
+	field("Conflicts", conflicts)
+	field("Homepage", p.Homepage)
+	var summary, details = p.description()
+	field("Description", summary)
+	for _, line := range strings.Split(details, "\n") {
+		if details == "" {
+			break
+		}
+		if strings.TrimSpace(line) == "" {
+			line = "."
+		}
+		fmt.Fprintf(&control, " %s\n", line)
+	}
+
+	var md5sums bytes.Buffer
+	var conffiles bytes.Buffer
+	for _, f := range files {
+		fmt.Fprintf(&md5sums, "%x  %s\n", md5.Sum(f.Data), f.Destination[1:]) // #nosec
+		// like the ones of dpkg-deb, the files in /etc are not overwritten
+		// when changed locally
+		if strings.HasPrefix(f.Destination, "/etc/") {
+			fmt.Fprintln(&conffiles, f.Destination)
+		}
+	}
+
+	return targz(func(tw *tar.Writer) error {
+		if err := writeTarDir(tw, "./", p.Date); err != nil {
+			return err
+		}
+		if err := writeTarFile(tw, "./control", 0644, control.Bytes(), p.Date); err != nil {
+			return err
+		}
+		if conffiles.Len() > 0 {
+			if err := writeTarFile(tw, "./conffiles", 0644, conffiles.Bytes(), p.Date); err != nil {
+				return err
+			}
+		}
+		return writeTarFile(tw, "./md5sums", 0644, md5sums.Bytes(), p.Date)
+	})
+}
+
+// debDependencies returns the given dependencies as a Depends field, e.g.
+// `git (>= 2.0), zsh`
+func debDependencies(list []string) (string, error) {
+	deps, err := parseDependencies(list)
+	if err != nil {
+		return "", err
+	}
+	var result []string
+	for _, dep := range deps {
+		var op = dep.Op
+		switch op {
+		case "":
+			result = append(result, dep.Name)
+			continue
+		case "<":
+			op = "<<"
+		case ">":
+			op = ">>"
+		}
+		result = append(result, fmt.Sprintf("%s (%s %s)", dep.Name, op, dep.Version))
+	}
+	return strings.Join(result, ", "), nil
+}
+
+func debData(p Package, files contents) ([]byte, error) {
+	return targz(func(tw *tar.Writer) error {
+		if err := writeTarDir(tw, "./", p.Date); err != nil {
+			return err
+		}
+		for _, f := range files.tree() {
+			var err error
+			if f.Mode.IsDir() {
+				err = writeTarDir(tw, "."+f.Destination+"/", p.Date)
+			} else {
+				err = writeTarFile(tw, "."+f.Destination, int64(f.Mode), f.Data, p.Date)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// targz returns the tar.gz archive of the entries the given function writes
+func targz(write func(*tar.Writer) error) ([]byte, error) {
+	var buf bytes.Buffer
+	// the gzip header has no name nor modification time by default
+	var gw = gzip.NewWriter(&buf)
+	var tw = tar.NewWriter(gw)
+	if err := write(tw); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeTarDir(tw *tar.Writer, name string, date time.Time) error {
+	return tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name,
+		Mode:     0755,
+		ModTime:  date,
+		Uname:    "root",
+		Gname:    "root",
+	})
+}
+
+func writeTarFile(tw *tar.Writer, name string, mode int64, data []byte, date time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     mode,
+		Size:     int64(len(data)),
+		ModTime:  date,
+		Uname:    "root",
+		Gname:    "root",
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
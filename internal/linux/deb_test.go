@@ -0,0 +1,118 @@
+package linux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readAr returns the entries of the given ar archive, by name
+func readAr(t *testing.T, bts []byte) ([]string, map[string][]byte) {
+	assert.Equal(t, "!<arch>\n", string(bts[:8]))
+	bts = bts[8:]
+	var names []string
+	var entries = map[string][]byte{}
+	for len(bts) > 0 {
+		var header = string(bts[:60])
+		assert.Equal(t, "`\n", header[58:])
+		size, err := strconv.Atoi(strings.TrimSpace(header[48:58]))
+		assert.NoError(t, err)
+		var name = strings.TrimSpace(header[:16])
+		names = append(names, name)
+		entries[name] = bts[60 : 60+size]
+		bts = bts[60+size+size%2:]
+	}
+	return names, entries
+}
+
+// readTarGz returns the entries of the given tar.gz archive, as their name,
+// mode and contents
+func readTarGz(t *testing.T, bts []byte) map[string]string {
+	gr, err := gzip.NewReader(bytes.NewReader(bts))
+	assert.NoError(t, err)
+	var tr = tar.NewReader(gr)
+	var result = map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		data, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		result[header.Name] = fmt.Sprintf("%o %s", header.Mode, data)
+	}
+	return result
+}
+
+func TestWriteDeb(t *testing.T) {
+	folder, err := ioutil.TempDir("", "linuxpackage")
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, WriteDeb(&buf, testPackage(t, folder)))
+
+	names, entries := readAr(t, buf.Bytes())
+	assert.Equal(t, []string{"debian-binary", "control.tar.gz", "data.tar.gz"}, names)
+	assert.Equal(t, "2.0\n", string(entries["debian-binary"]))
+
+	var control = readTarGz(t, entries["control.tar.gz"])
+	assert.Equal(t, "644 "+strings.Join([]string{
+		"Package: mybin",
+		"Version: 1.0.0-rc1",
+		"Section: default",
+		"Priority: optional",
+		"Architecture: amd64",
+		"Maintainer: Drummer <drum-roll@example.com>",
+		"Vendor: Drum Roll Inc.",
+		"License: MIT",
+		"Installed-Size: 1",
+		"Depends: git, zsh (>= 5.0)",
+		"Conflicts: svn",
+		"Homepage: https://example.com",
+		"Description: Fast drum rolls.",
+		" Really fast.",
+	}, "\n")+"\n", control["./control"])
+	assert.Equal(t, "644 /etc/mybin/mybin.conf\n", control["./conffiles"])
+	assert.Equal(
+		t,
+		"644 6aea67367311873a8a1383e4373a0e3c  etc/mybin/mybin.conf\n"+
+			"46bbbe8aa98cc0714426e948474eaaf4  usr/local/bin/mybin\n",
+		control["./md5sums"],
+	)
+
+	assert.Equal(t, map[string]string{
+		"./":                     "755 ",
+		"./etc/":                 "755 ",
+		"./etc/mybin/":           "755 ",
+		"./etc/mybin/mybin.conf": "644 a=b\n",
+		"./usr/":                 "755 ",
+		"./usr/local/":           "755 ",
+		"./usr/local/bin/":       "755 ",
+		"./usr/local/bin/mybin":  "755 #!/bin/sh\necho hi\n",
+	}, readTarGz(t, entries["data.tar.gz"]))
+}
+
+func TestWriteDebNoDescription(t *testing.T) {
+	folder, err := ioutil.TempDir("", "linuxpackage")
+	assert.NoError(t, err)
+	var p = testPackage(t, folder)
+	p.Description = ""
+	p.Maintainer = ""
+	p.Goarch = "arm"
+	p.Variant = "7"
+	var buf bytes.Buffer
+	assert.NoError(t, WriteDeb(&buf, p))
+	_, entries := readAr(t, buf.Bytes())
+	var control = readTarGz(t, entries["control.tar.gz"])["./control"]
+	assert.Contains(t, control, "\nDescription: no description given\n")
+	assert.Contains(t, control, "\nArchitecture: armhf\n")
+	assert.NotContains(t, control, "Maintainer")
+}
@@ -0,0 +1,232 @@
+package linux
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Package is a linux package to write
+type Package struct {
+	Name    string
+	Version string
+	// Goarch and Variant are the target of the binaries, which each format
+	// converts to its own arch names
+	Goarch      string
+	Variant     string
+	Vendor      string
+	Homepage    string
+	Maintainer  string
+	Description string
+	License     string
+	// Dependencies and Conflicts are package names, optionally followed by
+	// a version constraint, e.g. `git >= 2.0`
+	Dependencies []string
+	Conflicts    []string
+	// Date is the build date and the modification time of all the files,
+	// so the same package is always written the same way
+	Date  time.Time
+	Files []File
+}
+
+// File is a file of a package
+type File struct {
+	// Source is the path of the file on disk
+	Source string
+	// Destination is the absolute path of the file once installed
+	Destination string
+	// Mode holds the permissions of the installed file
+	Mode os.FileMode
+}
+
+// writers of the formats that can be written without fpm, by format
+var writers = map[string]func(io.Writer, Package) error{
+	"deb": WriteDeb,
+	"rpm": WriteRPM,
+	"apk": WriteAPK,
+}
+
+// Supports returns true if packages of the given format can be written
+func Supports(format string) bool {
+	_, ok := writers[format]
+	return ok
+}
+
+// Write writes the given package in the given format
+func Write(format string, w io.Writer, p Package) error {
+	write, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("unsupported package format: %s", format)
+	}
+	return write(w, p)
+}
+
+// Files returns the files to install from the given source: the file itself
+// or, if it is a folder, all the files inside of it
+func Files(source, destination string) ([]File, error) {
+	var result []File
+	err := filepath.Walk(source, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(source, file)
+		if err != nil {
+			return err
+		}
+		var mode os.FileMode = 0644
+		if info.Mode()&0111 != 0 {
+			mode = 0755
+		}
+		result = append(result, File{
+			Source:      file,
+			Destination: path.Join(destination, filepath.ToSlash(rel)),
+			Mode:        mode,
+		})
+		return nil
+	})
+	return result, err
+}
+
+// contents are the files of a package as they are written: sorted, with
+// their contents read
+type contents []content
+
+type content struct {
+	File
+	Data []byte
+}
+
+func (p Package) contents() (contents, error) {
+	var files = append([]File{}, p.Files...)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Destination < files[j].Destination
+	})
+	var result contents
+	for _, f := range files {
+		if !path.IsAbs(f.Destination) {
+			return nil, fmt.Errorf("%s: destination must be an absolute path", f.Destination)
+		}
+		bts, err := ioutil.ReadFile(f.Source)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, content{File: f, Data: bts})
+	}
+	return result, nil
+}
+
+// size returns the installed size of the files
+func (c contents) size() int64 {
+	var size int64
+	for _, f := range c {
+		size += int64(len(f.Data))
+	}
+	return size
+}
+
+// dirs returns the folders the files are in, and their parents, sorted
+func (c contents) dirs() []string {
+	var seen = map[string]bool{}
+	var result []string
+	for _, f := range c {
+		for dir := path.Dir(f.Destination); dir != "/" && !seen[dir]; dir = path.Dir(dir) {
+			seen[dir] = true
+			result = append(result, dir)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// tree returns the folders and the files, each folder being right before
+// what is inside of it
+func (c contents) tree() contents {
+	var result = append(contents{}, c...)
+	for _, dir := range c.dirs() {
+		result = append(result, content{File: File{
+			Destination: dir,
+			Mode:        os.ModeDir | 0755,
+		}})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return lessPath(result[i].Destination, result[j].Destination)
+	})
+	return result
+}
+
+// lessPath compares paths component by component, so /etc/a is before
+// /etc-x
+func lessPath(a, b string) bool {
+	var as, bs = strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i] < bs[i]
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// description returns the first line of the description, and the others
+func (p Package) description() (string, string) {
+	var lines = strings.SplitN(strings.TrimSpace(p.Description), "\n", 2)
+	if lines[0] == "" {
+		return "no description given", ""
+	}
+	if len(lines) == 1 {
+		return lines[0], ""
+	}
+	return lines[0], strings.TrimSpace(lines[1])
+}
+
+// dependency is a package name with an optional version constraint
+type dependency struct {
+	Name    string
+	Op      string
+	Version string
+}
+
+// parseDependency parses dependencies such as `git`, `git >= 2.0` and the
+// debian style `git (>= 2.0)`
+func parseDependency(s string) (dependency, error) {
+	var fields = strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(s))
+	switch len(fields) {
+	case 1:
+		return dependency{Name: fields[0]}, nil
+	case 3:
+		var op = fields[1]
+		switch op {
+		case "<<":
+			op = "<"
+		case ">>":
+			op = ">"
+		case "==":
+			op = "="
+		}
+		switch op {
+		case "<", "<=", "=", ">=", ">":
+			return dependency{Name: fields[0], Op: op, Version: fields[2]}, nil
+		}
+	}
+	return dependency{}, fmt.Errorf("invalid dependency: %s", s)
+}
+
+func parseDependencies(list []string) ([]dependency, error) {
+	var result []dependency
+	for _, s := range list {
+		dep, err := parseDependency(s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dep)
+	}
+	return result, nil
+}
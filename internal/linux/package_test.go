@@ -0,0 +1,171 @@
+package linux
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPackage returns a package of a binary and a config file, written in
+// the given folder
+func testPackage(t *testing.T, folder string) Package {
+	var bin = filepath.Join(folder, "mybin")
+	assert.NoError(t, ioutil.WriteFile(bin, []byte("#!/bin/sh\necho hi\n"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(folder, "etc"), 0755))
+	var conf = filepath.Join(folder, "etc", "mybin.conf")
+	assert.NoError(t, ioutil.WriteFile(conf, []byte("a=b\n"), 0644))
+	return Package{
+		Name:         "mybin",
+		Version:      "1.0.0-rc1",
+		Goarch:       "amd64",
+		Vendor:       "Drum Roll Inc.",
+		Homepage:     "https://example.com",
+		Maintainer:   "Drummer <drum-roll@example.com>",
+		Description:  "Fast drum rolls.\n\nReally fast.",
+		License:      "MIT",
+		Dependencies: []string{"git", "zsh >= 5.0"},
+		Conflicts:    []string{"svn"},
+		Date:         time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+		Files: []File{
+			{Source: conf, Destination: "/etc/mybin/mybin.conf", Mode: 0644},
+			{Source: bin, Destination: "/usr/local/bin/mybin", Mode: 0755},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	folder, err := ioutil.TempDir("", "linuxpackage")
+	assert.NoError(t, err)
+	var p = testPackage(t, folder)
+	for _, format := range []string{"deb", "rpm", "apk"} {
+		t.Run(format, func(t *testing.T) {
+			assert.True(t, Supports(format))
+			var first, second bytes.Buffer
+			assert.NoError(t, Write(format, &first, p))
+			assert.NotEmpty(t, first.Bytes())
+			// the files are sorted, so the order they are given in doesn't
+			// matter, and the package is always the same
+			p.Files[0], p.Files[1] = p.Files[1], p.Files[0]
+			assert.NoError(t, Write(format, &second, p))
+			assert.Equal(t, first.Bytes(), second.Bytes())
+		})
+	}
+}
+
+func TestWriteUnsupported(t *testing.T) {
+	assert.False(t, Supports("pacman"))
+	assert.EqualError(t, Write("pacman", &bytes.Buffer{}, Package{}), "unsupported package format: pacman")
+}
+
+func TestWriteFileDoesntExist(t *testing.T) {
+	for _, format := range []string{"deb", "rpm", "apk"} {
+		var p = Package{
+			Name:  "mybin",
+			Files: []File{{Source: "/nope/mybin", Destination: "/usr/bin/mybin"}},
+		}
+		assert.Error(t, Write(format, &bytes.Buffer{}, p), format)
+	}
+}
+
+func TestWriteRelativeDestination(t *testing.T) {
+	var p = Package{
+		Name:  "mybin",
+		Files: []File{{Source: "package.go", Destination: "usr/bin/mybin"}},
+	}
+	assert.EqualError(t, WriteDeb(&bytes.Buffer{}, p), "usr/bin/mybin: destination must be an absolute path")
+}
+
+func TestWriteInvalidDependency(t *testing.T) {
+	folder, err := ioutil.TempDir("", "linuxpackage")
+	assert.NoError(t, err)
+	var p = testPackage(t, folder)
+	p.Dependencies = []string{"git >= "}
+	for _, format := range []string{"deb", "rpm", "apk"} {
+		assert.EqualError(t, Write(format, &bytes.Buffer{}, p), "invalid dependency: git >= ", format)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	folder, err := ioutil.TempDir("", "linuxpackage")
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Join(folder, "init.d", "sub"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(folder, "init.d", "mybin"), []byte("x"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(folder, "init.d", "sub", "conf"), []byte("x"), 0600))
+
+	files, err := Files(filepath.Join(folder, "init.d"), "/etc/init.d")
+	assert.NoError(t, err)
+	assert.Equal(t, []File{
+		{Source: filepath.Join(folder, "init.d", "mybin"), Destination: "/etc/init.d/mybin", Mode: 0755},
+		{Source: filepath.Join(folder, "init.d", "sub", "conf"), Destination: "/etc/init.d/sub/conf", Mode: 0644},
+	}, files)
+
+	files, err = Files(filepath.Join(folder, "init.d", "mybin"), "/usr/bin/other")
+	assert.NoError(t, err)
+	assert.Equal(t, []File{
+		{Source: filepath.Join(folder, "init.d", "mybin"), Destination: "/usr/bin/other", Mode: 0755},
+	}, files)
+
+	_, err = Files(filepath.Join(folder, "nope"), "/nope")
+	assert.Error(t, err)
+}
+
+func TestDirs(t *testing.T) {
+	var c = contents{
+		{File: File{Destination: "/etc/mybin/mybin.conf"}},
+		{File: File{Destination: "/usr/local/bin/a"}},
+		{File: File{Destination: "/usr/local/bin/b"}},
+	}
+	assert.Equal(t, []string{"/etc", "/etc/mybin", "/usr", "/usr/local", "/usr/local/bin"}, c.dirs())
+}
+
+func TestTree(t *testing.T) {
+	var c = contents{
+		{File: File{Destination: "/etc-x/b", Mode: 0644}},
+		{File: File{Destination: "/etc/a", Mode: 0644}},
+	}
+	var paths []string
+	for _, f := range c.tree() {
+		paths = append(paths, fmt.Sprintf("%s %v", f.Destination, f.Mode))
+	}
+	assert.Equal(t, []string{
+		"/etc drwxr-xr-x",
+		"/etc/a -rw-r--r--",
+		"/etc-x drwxr-xr-x",
+		"/etc-x/b -rw-r--r--",
+	}, paths)
+}
+
+func TestDescription(t *testing.T) {
+	for description, expected := range map[string][2]string{
+		"":                            {"no description given", ""},
+		"One line.":                   {"One line.", ""},
+		"Summary.\n\nDetails.\nMore.": {"Summary.", "Details.\nMore."},
+	} {
+		summary, details := Package{Description: description}.description()
+		assert.Equal(t, expected, [2]string{summary, details}, description)
+	}
+}
+
+func TestParseDependency(t *testing.T) {
+	for s, expected := range map[string]dependency{
+		"git":          {Name: "git"},
+		"git >= 2.0":   {Name: "git", Op: ">=", Version: "2.0"},
+		"git (<< 2.0)": {Name: "git", Op: "<", Version: "2.0"},
+		"git (>> 2.0)": {Name: "git", Op: ">", Version: "2.0"},
+		"git == 2.0":   {Name: "git", Op: "=", Version: "2.0"},
+	} {
+		dep, err := parseDependency(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, dep, s)
+	}
+	for _, s := range []string{"", "git >=", "git ~ 2.0", "git >= 2.0 3.0"} {
+		_, err := parseDependency(s)
+		assert.EqualError(t, err, "invalid dependency: "+s)
+	}
+}
@@ -0,0 +1,446 @@
+package linux
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"  // #nosec
+	"crypto/sha1" // #nosec
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// the types of the values of the rpm header entries
+const (
+	rpmInt16       = 3
+	rpmInt32       = 4
+	rpmString      = 6
+	rpmBin         = 7
+	rpmStringArray = 8
+	rpmI18NString  = 9
+)
+
+// the tags of the rpm signature and header entries, see
+// https://github.com/rpm-software-management/rpm/blob/master/include/rpm/rpmtag.h
+const (
+	sigTagSize        = 1000
+	sigTagMD5         = 1004
+	sigTagPayloadSize = 1007
+	sigTagSHA1        = 269
+	sigTagSHA256      = 273
+	rpmTagSignatures  = 62
+	rpmTagImmutable   = 63
+	rpmTagI18NTable   = 100
+
+	rpmTagName              = 1000
+	rpmTagVersion           = 1001
+	rpmTagRelease           = 1002
+	rpmTagSummary           = 1004
+	rpmTagDescription       = 1005
+	rpmTagBuildTime         = 1006
+	rpmTagBuildHost         = 1007
+	rpmTagSize              = 1009
+	rpmTagVendor            = 1011
+	rpmTagLicense           = 1014
+	rpmTagPackager          = 1015
+	rpmTagGroup             = 1016
+	rpmTagURL               = 1020
+	rpmTagOS                = 1021
+	rpmTagArch              = 1022
+	rpmTagFileSizes         = 1028
+	rpmTagFileModes         = 1030
+	rpmTagFileRdevs         = 1033
+	rpmTagFileMtimes        = 1034
+	rpmTagFileDigests       = 1035
+	rpmTagFileLinkTos       = 1036
+	rpmTagFileFlags         = 1037
+	rpmTagFileUserName      = 1039
+	rpmTagFileGroupName     = 1040
+	rpmTagSourceRPM         = 1044
+	rpmTagProvideName       = 1047
+	rpmTagRequireFlags      = 1048
+	rpmTagRequireName       = 1049
+	rpmTagRequireVersion    = 1050
+	rpmTagConflictFlags     = 1053
+	rpmTagConflictName      = 1054
+	rpmTagConflictVersion   = 1055
+	rpmTagFileDevices       = 1095
+	rpmTagFileInodes        = 1096
+	rpmTagFileLangs         = 1097
+	rpmTagProvideFlags      = 1112
+	rpmTagProvideVersion    = 1113
+	rpmTagDirIndexes        = 1116
+	rpmTagBaseNames         = 1117
+	rpmTagDirNames          = 1118
+	rpmTagPayloadFormat     = 1124
+	rpmTagPayloadCompressor = 1125
+	rpmTagPayloadFlags      = 1126
+	rpmTagFileDigestAlgo    = 5011
+	rpmTagPayloadDigest     = 5092
+	rpmTagPayloadDigestAlgo = 5093
+)
+
+// the flags of the dependencies
+const (
+	rpmSenseLess    = 1 << 1
+	rpmSenseGreater = 1 << 2
+	rpmSenseEqual   = 1 << 3
+	rpmSenseRPMLib  = 1 << 24
+)
+
+const rpmSHA256 = 8
+
+// rpmArchs are the rpm names of the linux archs that differ from the
+// debian ones
+var rpmArchs = map[string]string{
+	"amd64":   "x86_64",
+	"arm64":   "aarch64",
+	"ppc64el": "ppc64le",
+	"loong64": "loongarch64",
+}
+
+// RPMArch converts a goarch and its variant to an rpm arch
+func RPMArch(goarch, variant string) string {
+	if goarch == "arm" {
+		switch variant {
+		case "5":
+			return "armv5tel"
+		case "6":
+			return "armv6hl"
+		}
+		return "armv7hl"
+	}
+	var arch = Arch(goarch, variant)
+	if rpm, ok := rpmArchs[arch]; ok {
+		return rpm
+	}
+	return arch
+}
+
+// rpmVersion returns the given version without dashes, which rpm versions
+// can't have, e.g. 1.0.0-rc1 becomes 1.0.0_rc1
+func rpmVersion(version string) string {
+	return strings.Replace(version, "-", "_", -1)
+}
+
+// WriteRPM writes the given package as an .rpm, which is a lead, a
+// signature header, a header and a gzipped cpio payload of the files
+func WriteRPM(w io.Writer, p Package) error {
+	files, err := p.contents()
+	if err != nil {
+		return err
+	}
+	var cpio bytes.Buffer
+	if err := writeCpio(&cpio, files, uint32(p.Date.Unix())); err != nil {
+		return err
+	}
+	var payload bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&payload, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := gw.Write(cpio.Bytes()); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	h, err := rpmHeader(p, files, payload.Bytes())
+	if err != nil {
+		return err
+	}
+	var header = h.bytes(rpmTagImmutable)
+
+	var sig = rpmIndex{}
+	var md5sum = md5.New()        // #nosec
+	md5sum.Write(header)          // nolint: errcheck
+	md5sum.Write(payload.Bytes()) // nolint: errcheck
+	sig.int32s(sigTagSize, int32(len(header)+payload.Len()))
+	sig.add(sigTagMD5, rpmBin, 16, md5sum.Sum(nil))
+	sig.int32s(sigTagPayloadSize, int32(cpio.Len()))
+	sig.string(sigTagSHA1, fmt.Sprintf("%x", sha1.Sum(header))) // #nosec
+	sig.string(sigTagSHA256, fmt.Sprintf("%x", sha256.Sum256(header)))
+	var signature = sig.bytes(rpmTagSignatures)
+	// the header is aligned on 8 bytes
+	signature = append(signature, make([]byte, (8-len(signature)%8)%8)...)
+
+	for _, part := range [][]byte{rpmLead(p), signature, header, payload.Bytes()} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCpio writes the files as a cpio archive in the new ascii format,
+// whose names start with ./ like the ones of rpmbuild
+func writeCpio(w io.Writer, files contents, mtime uint32) error {
+	var entry = func(ino int, name string, mode uint32, data []byte) error {
+		name += "\x00"
+		var header = fmt.Sprintf(
+			"070701%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+			ino, mode, 0, 0, 1, mtime, len(data), 0, 0, 0, 0, len(name), 0,
+		)
+		var buf = []byte(header + name)
+		buf = append(buf, make([]byte, (4-len(buf)%4)%4)...)
+		buf = append(buf, data...)
+		buf = append(buf, make([]byte, (4-len(data)%4)%4)...)
+		_, err := w.Write(buf)
+		return err
+	}
+	for i, f := range files {
+		if err := entry(i+1, "."+f.Destination, 0100000|uint32(f.Mode), f.Data); err != nil {
+			return err
+		}
+	}
+	return entry(0, "TRAILER!!!", 0, nil)
+}
+
+func rpmLead(p Package) []byte {
+	var lead = make([]byte, 96)
+	copy(lead, []byte{0xed, 0xab, 0xee, 0xdb, 3, 0})
+	// binary package, for the arch and os number 1
+	binary.BigEndian.PutUint16(lead[6:], 0)
+	binary.BigEndian.PutUint16(lead[8:], 1)
+	copy(lead[10:75], p.Name+"-"+rpmVersion(p.Version)+"-1")
+	binary.BigEndian.PutUint16(lead[76:], 1)
+	// header style signature
+	binary.BigEndian.PutUint16(lead[78:], 5)
+	return lead
+}
+
+func rpmHeader(p Package, files contents, payload []byte) (rpmIndex, error) {
+	var h = rpmIndex{}
+	var version = rpmVersion(p.Version)
+	var summary, details = p.description()
+	if details == "" {
+		details = summary
+	}
+	h.strings(rpmTagI18NTable, "C")
+	h.string(rpmTagName, p.Name)
+	h.string(rpmTagVersion, version)
+	h.string(rpmTagRelease, "1")
+	h.i18n(rpmTagSummary, summary)
+	h.i18n(rpmTagDescription, details)
+	h.int32s(rpmTagBuildTime, int32(p.Date.Unix()))
+	h.string(rpmTagBuildHost, "localhost")
+	h.int32s(rpmTagSize, int32(files.size()))
+	h.optionalString(rpmTagVendor, p.Vendor)
+	h.optionalString(rpmTagLicense, p.License)
+	h.optionalString(rpmTagPackager, p.Maintainer)
+	h.i18n(rpmTagGroup, "default")
+	h.optionalString(rpmTagURL, p.Homepage)
+	h.string(rpmTagOS, "linux")
+	h.string(rpmTagArch, RPMArch(p.Goarch, p.Variant))
+	h.string(rpmTagSourceRPM, p.Name+"-"+version+"-1.src.rpm")
+	h.string(rpmTagPayloadFormat, "cpio")
+	h.string(rpmTagPayloadCompressor, "gzip")
+	h.string(rpmTagPayloadFlags, "9")
+	h.strings(rpmTagPayloadDigest, fmt.Sprintf("%x", sha256.Sum256(payload)))
+	h.int32s(rpmTagPayloadDigestAlgo, rpmSHA256)
+
+	h.strings(rpmTagProvideName, p.Name)
+	h.int32s(rpmTagProvideFlags, rpmSenseEqual)
+	h.strings(rpmTagProvideVersion, version+"-1")
+
+	requires, err := parseDependencies(p.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+	// the features of rpm the package needs
+	requires = append(
+		requires,
+		dependency{"rpmlib(CompressedFileNames)", "<=", "3.0.4-1"},
+		dependency{"rpmlib(FileDigests)", "<=", "4.6.0-1"},
+		dependency{"rpmlib(PayloadFilesHavePrefix)", "<=", "4.0-1"},
+	)
+	h.dependencies(rpmTagRequireName, rpmTagRequireFlags, rpmTagRequireVersion, requires)
+	conflicts, err := parseDependencies(p.Conflicts)
+	if err != nil {
+		return nil, err
+	}
+	h.dependencies(rpmTagConflictName, rpmTagConflictFlags, rpmTagConflictVersion, conflicts)
+
+	var dirs []string
+	var dirIndex = map[string]int32{}
+	for _, f := range files {
+		var dir = path.Dir(f.Destination) + "/"
+		if _, ok := dirIndex[dir]; !ok {
+			dirIndex[dir] = int32(len(dirs))
+			dirs = append(dirs, dir)
+		}
+	}
+	var (
+		sizes, mtimes, flags, devices, inodes, indexes []int32
+		modes, rdevs                                   []int16
+		digests, links, users, groups, langs, names    []string
+	)
+	for i, f := range files {
+		sizes = append(sizes, int32(len(f.Data)))
+		modes = append(modes, int16(0100000|uint16(f.Mode)))
+		rdevs = append(rdevs, 0)
+		mtimes = append(mtimes, int32(p.Date.Unix()))
+		digests = append(digests, fmt.Sprintf("%x", sha256.Sum256(f.Data)))
+		links = append(links, "")
+		flags = append(flags, 0)
+		users = append(users, "root")
+		groups = append(groups, "root")
+		devices = append(devices, 1)
+		inodes = append(inodes, int32(i+1))
+		langs = append(langs, "")
+		indexes = append(indexes, dirIndex[path.Dir(f.Destination)+"/"])
+		names = append(names, path.Base(f.Destination))
+	}
+	h.int32s(rpmTagFileSizes, sizes...)
+	h.int16s(rpmTagFileModes, modes...)
+	h.int16s(rpmTagFileRdevs, rdevs...)
+	h.int32s(rpmTagFileMtimes, mtimes...)
+	h.strings(rpmTagFileDigests, digests...)
+	h.strings(rpmTagFileLinkTos, links...)
+	h.int32s(rpmTagFileFlags, flags...)
+	h.strings(rpmTagFileUserName, users...)
+	h.strings(rpmTagFileGroupName, groups...)
+	h.int32s(rpmTagFileDevices, devices...)
+	h.int32s(rpmTagFileInodes, inodes...)
+	h.strings(rpmTagFileLangs, langs...)
+	h.int32s(rpmTagDirIndexes, indexes...)
+	h.strings(rpmTagBaseNames, names...)
+	h.strings(rpmTagDirNames, dirs...)
+	h.int32s(rpmTagFileDigestAlgo, rpmSHA256)
+	return h, nil
+}
+
+// rpmEntry is an entry of an rpm header
+type rpmEntry struct {
+	typ   uint32
+	count uint32
+	data  []byte
+}
+
+// rpmIndex holds the entries of an rpm header, by tag
+type rpmIndex map[uint32]rpmEntry
+
+func (h rpmIndex) add(tag, typ uint32, count int, data []byte) {
+	h[tag] = rpmEntry{typ: typ, count: uint32(count), data: data}
+}
+
+func (h rpmIndex) string(tag uint32, s string) {
+	h.add(tag, rpmString, 1, []byte(s+"\x00"))
+}
+
+func (h rpmIndex) optionalString(tag uint32, s string) {
+	if s != "" {
+		h.string(tag, s)
+	}
+}
+
+func (h rpmIndex) i18n(tag uint32, s string) {
+	h.add(tag, rpmI18NString, 1, []byte(s+"\x00"))
+}
+
+func (h rpmIndex) strings(tag uint32, list ...string) {
+	var data []byte
+	for _, s := range list {
+		data = append(data, []byte(s+"\x00")...)
+	}
+	h.add(tag, rpmStringArray, len(list), data)
+}
+
+func (h rpmIndex) int32s(tag uint32, list ...int32) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, list) // nolint: errcheck
+	h.add(tag, rpmInt32, len(list), buf.Bytes())
+}
+
+func (h rpmIndex) int16s(tag uint32, list ...int16) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, list) // nolint: errcheck
+	h.add(tag, rpmInt16, len(list), buf.Bytes())
+}
+
+func (h rpmIndex) dependencies(nameTag, flagsTag, versionTag uint32, deps []dependency) {
+	if len(deps) == 0 {
+		return
+	}
+	var names, versions []string
+	var flags []int32
+	for _, dep := range deps {
+		var flag int32
+		if strings.HasPrefix(dep.Name, "rpmlib(") {
+			flag = rpmSenseRPMLib
+		}
+		if strings.Contains(dep.Op, "<") {
+			flag |= rpmSenseLess
+		}
+		if strings.Contains(dep.Op, ">") {
+			flag |= rpmSenseGreater
+		}
+		if strings.Contains(dep.Op, "=") {
+			flag |= rpmSenseEqual
+		}
+		names = append(names, dep.Name)
+		flags = append(flags, flag)
+		versions = append(versions, dep.Version)
+	}
+	h.strings(nameTag, names...)
+	h.int32s(flagsTag, flags...)
+	h.strings(versionTag, versions...)
+}
+
+// alignment returns the alignment of the values of the given type
+func alignment(typ uint32) int {
+	switch typ {
+	case rpmInt16:
+		return 2
+	case rpmInt32:
+		return 4
+	}
+	return 1
+}
+
+// bytes returns the header: its magic, the number of entries and the size
+// of their data, the index of the entries and their data. The first entry
+// is the region, whose data is last: an entry whose negative offset is the
+// size of the index.
+func (h rpmIndex) bytes(region uint32) []byte {
+	var tags []uint32
+	for tag := range h {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i] < tags[j]
+	})
+	var data bytes.Buffer
+	var offsets = make([]int, len(tags))
+	for i, tag := range tags {
+		var e = h[tag]
+		var align = alignment(e.typ)
+		data.Write(make([]byte, (align-data.Len()%align)%align))
+		offsets[i] = data.Len()
+		data.Write(e.data)
+	}
+	var entry = func(tag, typ uint32, offset int, count uint32) []byte {
+		var b = make([]byte, 16)
+		binary.BigEndian.PutUint32(b, tag)
+		binary.BigEndian.PutUint32(b[4:], typ)
+		binary.BigEndian.PutUint32(b[8:], uint32(int32(offset)))
+		binary.BigEndian.PutUint32(b[12:], count)
+		return b
+	}
+	var regionOffset = data.Len()
+	data.Write(entry(region, rpmBin, -16*(len(tags)+1), 16))
+
+	var result bytes.Buffer
+	result.Write([]byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0})
+	binary.Write(&result, binary.BigEndian, []int32{int32(len(tags) + 1), int32(data.Len())}) // nolint: errcheck
+	result.Write(entry(region, rpmBin, regionOffset, 16))
+	for i, tag := range tags {
+		result.Write(entry(tag, h[tag].typ, offsets[i], h[tag].count))
+	}
+	result.Write(data.Bytes())
+	return result.Bytes()
+}
@@ -0,0 +1,186 @@
+package linux
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5" // #nosec
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rpmHeaderValue is the value of an entry of a header read back: strings
+// and ints, or bytes
+type rpmHeaderValue struct {
+	Strings []string
+	Ints    []int64
+	Bytes   []byte
+}
+
+// readRPMHeader reads the header at the start of the given bytes, and
+// returns its values by tag and its size
+func readRPMHeader(t *testing.T, bts []byte, region uint32) (map[uint32]rpmHeaderValue, int) {
+	assert.Equal(t, []byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0}, bts[:8])
+	var count = int(binary.BigEndian.Uint32(bts[8:]))
+	var size = int(binary.BigEndian.Uint32(bts[12:]))
+	var store = bts[16+count*16 : 16+count*16+size]
+	var values = map[uint32]rpmHeaderValue{}
+	for i := 0; i < count; i++ {
+		var entry = bts[16+i*16:]
+		var tag = binary.BigEndian.Uint32(entry)
+		var typ = binary.BigEndian.Uint32(entry[4:])
+		var offset = int(int32(binary.BigEndian.Uint32(entry[8:])))
+		var n = int(binary.BigEndian.Uint32(entry[12:]))
+		if i == 0 {
+			// the region is first, and its data is the trailer, an entry
+			// whose offset is minus the size of the index
+			assert.Equal(t, region, tag)
+			assert.Equal(t, uint32(rpmBin), typ)
+			assert.Equal(t, size-16, offset)
+			assert.Equal(t, 16, n)
+			var trailer = store[offset:]
+			assert.Equal(t, region, binary.BigEndian.Uint32(trailer))
+			assert.Equal(t, -16*count, int(int32(binary.BigEndian.Uint32(trailer[8:]))))
+			continue
+		}
+		var value rpmHeaderValue
+		switch typ {
+		case rpmString, rpmI18NString, rpmStringArray:
+			value.Strings = strings.SplitN(string(store[offset:]), "\x00", n+1)[:n]
+		case rpmInt16:
+			assert.Zero(t, offset%2)
+			for j := 0; j < n; j++ {
+				value.Ints = append(value.Ints, int64(binary.BigEndian.Uint16(store[offset+j*2:])))
+			}
+		case rpmInt32:
+			assert.Zero(t, offset%4)
+			for j := 0; j < n; j++ {
+				value.Ints = append(value.Ints, int64(binary.BigEndian.Uint32(store[offset+j*4:])))
+			}
+		case rpmBin:
+			value.Bytes = store[offset : offset+n]
+		default:
+			t.Fatalf("unexpected type %d of tag %d", typ, tag)
+		}
+		values[tag] = value
+	}
+	return values, 16 + count*16 + size
+}
+
+// readCpio returns the entries of the given cpio archive as their name,
+// mode and contents
+func readCpio(t *testing.T, bts []byte) []string {
+	var result []string
+	for {
+		assert.Equal(t, "070701", string(bts[:6]))
+		var field = func(i int) int {
+			n, err := strconv.ParseInt(string(bts[6+i*8:14+i*8]), 16, 64)
+			assert.NoError(t, err)
+			return int(n)
+		}
+		var mode, size, namesize = field(1), field(6), field(11)
+		var name = string(bts[110 : 110+namesize-1])
+		if name == "TRAILER!!!" {
+			return result
+		}
+		var start = (110 + namesize + 3) / 4 * 4
+		result = append(result, fmt.Sprintf("%s %o %s", name, mode, bts[start:start+size]))
+		bts = bts[(start+size+3)/4*4:]
+	}
+}
+
+func TestWriteRPM(t *testing.T) {
+	folder, err := ioutil.TempDir("", "linuxpackage")
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, WriteRPM(&buf, testPackage(t, folder)))
+	var bts = buf.Bytes()
+
+	assert.Equal(t, []byte{0xed, 0xab, 0xee, 0xdb, 3, 0}, bts[:6])
+	assert.Equal(t, "mybin-1.0.0_rc1-1", strings.TrimRight(string(bts[10:76]), "\x00"))
+	bts = bts[96:]
+
+	sig, size := readRPMHeader(t, bts, rpmTagSignatures)
+	// the signature is padded to 8 bytes
+	var padded = (size + 7) / 8 * 8
+	assert.Equal(t, make([]byte, padded-size), bts[size:padded])
+	bts = bts[padded:]
+	_, size = readRPMHeader(t, bts, rpmTagImmutable)
+	var header, payload = bts[:size], bts[size:]
+	h, _ := readRPMHeader(t, header, rpmTagImmutable)
+
+	var md5sum = md5.New() // #nosec
+	md5sum.Write(bts)      // nolint: errcheck
+	assert.Equal(t, md5sum.Sum(nil), sig[sigTagMD5].Bytes)
+	assert.Equal(t, []int64{int64(len(bts))}, sig[sigTagSize].Ints)
+	assert.Equal(t, []string{fmt.Sprintf("%x", sha256.Sum256(header))}, sig[sigTagSHA256].Strings)
+	assert.Equal(t, []string{fmt.Sprintf("%x", sha256.Sum256(payload))}, h[rpmTagPayloadDigest].Strings)
+
+	for tag, expected := range map[uint32][]string{
+		rpmTagName:           {"mybin"},
+		rpmTagVersion:        {"1.0.0_rc1"},
+		rpmTagRelease:        {"1"},
+		rpmTagSummary:        {"Fast drum rolls."},
+		rpmTagDescription:    {"Really fast."},
+		rpmTagVendor:         {"Drum Roll Inc."},
+		rpmTagLicense:        {"MIT"},
+		rpmTagPackager:       {"Drummer <drum-roll@example.com>"},
+		rpmTagURL:            {"https://example.com"},
+		rpmTagOS:             {"linux"},
+		rpmTagArch:           {"x86_64"},
+		rpmTagSourceRPM:      {"mybin-1.0.0_rc1-1.src.rpm"},
+		rpmTagBaseNames:      {"mybin.conf", "mybin"},
+		rpmTagDirNames:       {"/etc/mybin/", "/usr/local/bin/"},
+		rpmTagFileUserName:   {"root", "root"},
+		rpmTagConflictName:   {"svn"},
+		rpmTagRequireName:    {"git", "zsh", "rpmlib(CompressedFileNames)", "rpmlib(FileDigests)", "rpmlib(PayloadFilesHavePrefix)"},
+		rpmTagRequireVersion: {"", "5.0", "3.0.4-1", "4.6.0-1", "4.0-1"},
+	} {
+		assert.Equal(t, expected, h[tag].Strings, "tag %d", tag)
+	}
+	for tag, expected := range map[uint32][]int64{
+		rpmTagBuildTime:    {1514862245},
+		rpmTagSize:         {22},
+		rpmTagFileSizes:    {4, 18},
+		rpmTagFileModes:    {0100644, 0100755},
+		rpmTagDirIndexes:   {0, 1},
+		rpmTagRequireFlags: {0, rpmSenseGreater | rpmSenseEqual, rpmSenseRPMLib | rpmSenseLess | rpmSenseEqual, rpmSenseRPMLib | rpmSenseLess | rpmSenseEqual, rpmSenseRPMLib | rpmSenseLess | rpmSenseEqual},
+	} {
+		assert.Equal(t, expected, h[tag].Ints, "tag %d", tag)
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(payload))
+	assert.NoError(t, err)
+	cpio, err := ioutil.ReadAll(gr)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{int64(len(cpio))}, sig[sigTagPayloadSize].Ints)
+	assert.Equal(t, []string{
+		"./etc/mybin/mybin.conf 100644 a=b\n",
+		"./usr/local/bin/mybin 100755 #!/bin/sh\necho hi\n",
+	}, readCpio(t, cpio))
+}
+
+func TestRPMArch(t *testing.T) {
+	for _, tt := range []struct {
+		goarch, variant, to string
+	}{
+		{"amd64", "v3", "x86_64"},
+		{"386", "sse2", "i386"},
+		{"arm64", "", "aarch64"},
+		{"arm", "5", "armv5tel"},
+		{"arm", "6", "armv6hl"},
+		{"arm", "7", "armv7hl"},
+		{"ppc64le", "", "ppc64le"},
+		{"mips64le", "", "mips64el"},
+		{"loong64", "", "loongarch64"},
+		{"s390x", "", "s390x"},
+	} {
+		assert.Equal(t, tt.to, RPMArch(tt.goarch, tt.variant), tt.goarch+tt.variant)
+	}
+}
@@ -0,0 +1,22 @@
+// Package nametemplate provides generic means to apply the archive name
+// template to an artifact.
+package nametemplate
+
+import (
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+)
+
+// Apply applies the name template of the given archive to the given
+// artifact and name
+func Apply(ctx *context.Context, archive config.Archive, a artifact.Artifact, name string) (string, error) {
+	return tmpl.New(ctx).
+		WithArtifact(a, archive.Replacements).
+		WithFields(tmpl.Fields{
+			"Binary":      name, // TODO: deprecated: remove this sometime
+			"ProjectName": name,
+		}).
+		Apply(archive.NameTemplate)
+}
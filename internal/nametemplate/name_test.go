@@ -0,0 +1,53 @@
+package nametemplate
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "proj",
+		Archive: config.Archive{
+			NameTemplate: "{{.Binary}}_{{.ProjectName}}_{{.Version}}_{{.Tag}}_{{.Os}}_{{.Arch}}_{{.Arm}}_{{.Env.FOO}}",
+			Replacements: map[string]string{
+				"darwin": "Darwin",
+				"amd64":  "x86_64",
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.Env = map[string]string{"FOO": "bar"}
+	for expected, a := range map[string]artifact.Artifact{
+		"proj_proj_1.0.0_v1.0.0_Darwin_x86_64__bar": {
+			Goos:   "darwin",
+			Goarch: "amd64",
+		},
+		"proj_proj_1.0.0_v1.0.0_linux_arm_6_bar": {
+			Goos:   "linux",
+			Goarch: "arm",
+			Goarm:  "6",
+		},
+	} {
+		t.Run(expected, func(t *testing.T) {
+			name, err := Apply(ctx, ctx.Config.Archive, a, "proj")
+			assert.NoError(t, err)
+			assert.Equal(t, expected, name)
+		})
+	}
+}
+
+func TestInvalidNameTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Archive: config.Archive{
+			NameTemplate: "{{.Binary}",
+		},
+	})
+	_, err := Apply(ctx, ctx.Config.Archive, artifact.Artifact{}, "proj")
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+// Package retry retries the network calls that may fail temporarily, e.g.
+// uploads getting a 502 or a connection reset, with exponential backoff.
+package retry
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/config"
+)
+
+// Defaults used when the retry config is not set
+const (
+	DefaultAttempts = 3
+	DefaultDelay    = time.Second
+	DefaultMaxDelay = 30 * time.Second
+)
+
+// DefaultStatusCodes are the HTTP status codes retried by default
+var DefaultStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Policy defines how many times and how often to retry
+type Policy struct {
+	Attempts    int
+	Delay       time.Duration
+	MaxDelay    time.Duration
+	StatusCodes []int
+}
+
+// New policy from the given config, with the defaults for the fields that
+// are not set
+func New(cfg config.Retry) Policy {
+	var policy = Policy{
+		Attempts:    cfg.Attempts,
+		Delay:       cfg.Delay,
+		MaxDelay:    cfg.MaxDelay,
+		StatusCodes: cfg.StatusCodes,
+	}
+	if policy.Attempts == 0 {
+		policy.Attempts = DefaultAttempts
+	}
+	if policy.Delay == 0 {
+		policy.Delay = DefaultDelay
+	}
+	if policy.MaxDelay == 0 {
+		policy.MaxDelay = DefaultMaxDelay
+	}
+	if len(policy.StatusCodes) == 0 {
+		policy.StatusCodes = DefaultStatusCodes
+	}
+	return policy
+}
+
+// TemporaryError is an error that is worth retrying
+type TemporaryError struct {
+	Err error
+	// After is how long the server asked to wait before retrying, if it did
+	After time.Duration
+}
+
+func (e TemporaryError) Error() string {
+	return e.Err.Error()
+}
+
+// Temporary marks the given error as worth retrying, after the given delay
+// or after the backoff delay if it is zero
+func Temporary(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return TemporaryError{Err: err, After: after}
+}
+
+// HTTP marks the error of a HTTP request as temporary if the request failed
+// without a response, e.g. a connection reset, or if the response status
+// code is one of the policy ones. The Retry-After header is respected.
+func (p Policy) HTTP(resp *http.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+	if resp == nil {
+		return Temporary(err, 0)
+	}
+	for _, code := range p.StatusCodes {
+		if resp.StatusCode == code {
+			return Temporary(err, retryAfter(resp.Header.Get("Retry-After")))
+		}
+	}
+	return err
+}
+
+// Do calls fn until it succeeds or returns an error that is not temporary,
+// up to the policy attempts, waiting between attempts.
+// The wait starts at the policy delay and doubles on each attempt, up to the
+// policy max delay. When the error asks for a specific delay, e.g. until a
+// rate limit resets, that delay is used instead, also capped at the policy
+// max delay so a long reset doesn't stall the release.
+// The error of the last attempt is returned, unwrapped.
+func (p Policy) Do(ctx context.Context, fn func() error) error {
+	var delay = p.Delay
+	for attempt := 1; ; attempt++ {
+		var err = fn()
+		temp, ok := err.(TemporaryError)
+		if !ok {
+			return err
+		}
+		if attempt >= p.Attempts || ctx.Err() != nil {
+			return temp.Err
+		}
+		var wait = delay
+		if temp.After > 0 {
+			wait = temp.After
+		}
+		if wait > p.MaxDelay {
+			wait = p.MaxDelay
+		}
+		log.WithError(temp.Err).
+			WithField("attempt", attempt).
+			WithField("wait", wait).
+			Warn("retrying")
+		select {
+		case <-ctx.Done():
+			return temp.Err
+		case <-time.After(wait):
+		}
+		if delay *= 2; delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header, which is either a number of
+// seconds or a HTTP date
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,137 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, Policy{
+		Attempts:    DefaultAttempts,
+		Delay:       DefaultDelay,
+		MaxDelay:    DefaultMaxDelay,
+		StatusCodes: DefaultStatusCodes,
+	}, New(config.Retry{}))
+	var cfg = config.Retry{
+		Attempts:    5,
+		Delay:       time.Millisecond,
+		MaxDelay:    time.Second,
+		StatusCodes: []int{500},
+	}
+	assert.Equal(t, Policy{
+		Attempts:    5,
+		Delay:       time.Millisecond,
+		MaxDelay:    time.Second,
+		StatusCodes: []int{500},
+	}, New(cfg))
+}
+
+func TestDo(t *testing.T) {
+	var policy = New(config.Retry{Delay: time.Millisecond})
+	var calls int
+	assert.NoError(t, policy.Do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return Temporary(errors.New("502"), 0)
+		}
+		return nil
+	}))
+	assert.Equal(t, 3, calls)
+}
+
+func TestDoGivesUp(t *testing.T) {
+	var policy = New(config.Retry{Attempts: 2, Delay: time.Millisecond})
+	var calls int
+	assert.EqualError(t, policy.Do(context.Background(), func() error {
+		calls++
+		return Temporary(errors.New("502"), 0)
+	}), "502")
+	assert.Equal(t, 2, calls)
+}
+
+func TestDoPermanentError(t *testing.T) {
+	var policy = New(config.Retry{Delay: time.Millisecond})
+	var calls int
+	assert.EqualError(t, policy.Do(context.Background(), func() error {
+		calls++
+		return errors.New("401")
+	}), "401")
+	assert.Equal(t, 1, calls)
+}
+
+func TestDoCancelled(t *testing.T) {
+	var policy = New(config.Retry{Delay: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	assert.EqualError(t, policy.Do(ctx, func() error {
+		calls++
+		cancel()
+		return Temporary(errors.New("502"), 0)
+	}), "502")
+	assert.Equal(t, 1, calls)
+}
+
+func TestDoWaitsAfter(t *testing.T) {
+	var policy = New(config.Retry{Delay: time.Hour})
+	var calls int
+	var start = time.Now()
+	assert.NoError(t, policy.Do(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			return Temporary(errors.New("429"), time.Millisecond)
+		}
+		return nil
+	}))
+	assert.Equal(t, 2, calls)
+	assert.True(t, time.Since(start) < time.Minute)
+}
+
+func TestDoCapsAfter(t *testing.T) {
+	var policy = New(config.Retry{MaxDelay: time.Millisecond})
+	var calls int
+	var start = time.Now()
+	assert.NoError(t, policy.Do(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			return Temporary(errors.New("rate limited"), time.Hour)
+		}
+		return nil
+	}))
+	assert.Equal(t, 2, calls)
+	assert.True(t, time.Since(start) < time.Minute)
+}
+
+func TestHTTP(t *testing.T) {
+	var policy = New(config.Retry{})
+	var err = errors.New("failed")
+	var response = func(code int, retryAfter string) *http.Response {
+		var header = http.Header{}
+		if retryAfter != "" {
+			header.Set("Retry-After", retryAfter)
+		}
+		return &http.Response{StatusCode: code, Header: header}
+	}
+	assert.NoError(t, policy.HTTP(response(200, ""), nil))
+	assert.Equal(t, TemporaryError{Err: err}, policy.HTTP(nil, err))
+	assert.Equal(t, TemporaryError{Err: err}, policy.HTTP(response(502, ""), err))
+	assert.Equal(t, TemporaryError{Err: err, After: 2 * time.Second}, policy.HTTP(response(429, "2"), err))
+	assert.Equal(t, err, policy.HTTP(response(401, "2"), err))
+
+	temp, ok := policy.HTTP(response(503, time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)), err).(TemporaryError)
+	assert.True(t, ok)
+	assert.True(t, temp.After > 59*time.Minute, temp.After.String())
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), retryAfter(""))
+	assert.Equal(t, time.Duration(0), retryAfter("nope"))
+	assert.Equal(t, time.Duration(0), retryAfter("-1"))
+	assert.Equal(t, time.Duration(0), retryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+	assert.Equal(t, 120*time.Second, retryAfter("120"))
+}
@@ -0,0 +1,113 @@
+// Package runner is the single place where goreleaser runs external
+// commands, so they can be logged instead of run in dry-run mode.
+package runner
+
+import (
+	"bytes"
+	stdctx "context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/context"
+)
+
+// Cmd is an external command
+type Cmd struct {
+	// Args holds the command line, starting with the program name
+	Args []string
+	// Env holds extra environment variables, added to the current ones
+	Env []string
+	// Dir is the working directory, defaults to the current one
+	Dir string
+	// Stdin is the standard input of the command, if any
+	Stdin io.Reader
+}
+
+func (c Cmd) String() string {
+	return strings.Join(c.Args, " ")
+}
+
+// Run runs the given command and returns its combined output.
+// In dry-run mode, the command is logged instead.
+func Run(ctx *context.Context, cmd Cmd) ([]byte, error) {
+	if ctx.DryRun {
+		log.WithField("cmd", resolve(cmd.Args)).
+			WithField("env", cmd.Env).
+			WithField("dir", dir(cmd)).
+			Info("dry-run: would run")
+		return nil, nil
+	}
+	return Query(ctx, cmd)
+}
+
+// Query runs a command that doesn't change anything, e.g. `git describe`,
+// and returns its combined output. Queries run in dry-run mode as well.
+// The command is killed if the given context is cancelled.
+func Query(ctx stdctx.Context, cmd Cmd) ([]byte, error) {
+	return command(ctx, cmd).CombinedOutput()
+}
+
+// Output runs a command and returns its standard output. If the command
+// fails, its standard error is added to the returned error.
+// Like Query, it runs in dry-run mode as well.
+func Output(ctx stdctx.Context, cmd Cmd) ([]byte, error) {
+	var c = command(ctx, cmd)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return out, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func command(ctx stdctx.Context, cmd Cmd) *exec.Cmd {
+	/* #nosec */
+	var c = exec.CommandContext(ctx, cmd.Args[0], cmd.Args[1:]...)
+	if len(cmd.Env) > 0 {
+		c.Env = append(os.Environ(), cmd.Env...)
+	}
+	c.Dir = cmd.Dir
+	c.Stdin = cmd.Stdin
+	log.WithField("cmd", cmd.Args).
+		WithField("env", cmd.Env).
+		WithField("dir", cmd.Dir).
+		Debug("running")
+	return c
+}
+
+// LookPath checks if the given program is in the $PATH.
+// In dry-run mode, a missing program is only warned about.
+func LookPath(ctx *context.Context, program string) error {
+	_, err := exec.LookPath(program)
+	if err != nil && ctx.DryRun {
+		log.WithField("program", program).Warn("dry-run: not found in $PATH")
+		return nil
+	}
+	return err
+}
+
+func resolve(args []string) []string {
+	var result = append([]string{}, args...)
+	if path, err := exec.LookPath(result[0]); err == nil {
+		result[0] = path
+	}
+	return result
+}
+
+func dir(cmd Cmd) string {
+	var dir = cmd.Dir
+	if dir == "" {
+		dir = "."
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	return abs
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	stdctx "context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	out, err := Run(context.New(config.Project{}), Cmd{
+		Args: []string{"sh", "-c", "echo $FOO"},
+		Env:  []string{"FOO=bar"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "bar\n", string(out))
+}
+
+func TestRunDir(t *testing.T) {
+	folder, err := filepath.EvalSymlinks(os.TempDir())
+	assert.NoError(t, err)
+	out, err := Run(context.New(config.Project{}), Cmd{
+		Args: []string{"pwd"},
+		Dir:  folder,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, folder+"\n", string(out))
+}
+
+func TestRunFails(t *testing.T) {
+	_, err := Run(context.New(config.Project{}), Cmd{
+		Args: []string{"sh", "-c", "exit 1"},
+	})
+	assert.Error(t, err)
+}
+
+func TestRunDryRun(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var file = filepath.Join(folder, "file")
+	var ctx = context.New(config.Project{})
+	ctx.DryRun = true
+	out, err := Run(ctx, Cmd{
+		Args: []string{"touch", file},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestQuery(t *testing.T) {
+	out, err := Query(stdctx.Background(), Cmd{
+		Args: []string{"echo", "foo"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo\n", string(out))
+}
+
+func TestOutput(t *testing.T) {
+	out, err := Output(stdctx.Background(), Cmd{
+		Args:  []string{"sh", "-c", "cat; echo err >&2"},
+		Stdin: strings.NewReader("foo"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(out))
+}
+
+func TestOutputFails(t *testing.T) {
+	_, err := Output(stdctx.Background(), Cmd{
+		Args: []string{"sh", "-c", "echo oops >&2; exit 1"},
+	})
+	assert.EqualError(t, err, "exit status 1: oops")
+}
+
+func TestQueryCancelled(t *testing.T) {
+	cctx, cancel := stdctx.WithCancel(stdctx.Background())
+	cancel()
+	_, err := Query(cctx, Cmd{
+		Args: []string{"sleep", "10"},
+	})
+	assert.Error(t, err)
+}
+
+func TestRunTimeout(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	var cancel stdctx.CancelFunc
+	ctx.Context, cancel = stdctx.WithTimeout(ctx.Context, 10*time.Millisecond)
+	defer cancel()
+	var start = time.Now()
+	_, err := Run(ctx, Cmd{
+		Args: []string{"sleep", "10"},
+	})
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestLookPath(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	assert.NoError(t, LookPath(ctx, "sh"))
+	assert.Error(t, LookPath(ctx, "nope-not-a-program"))
+	ctx.DryRun = true
+	assert.NoError(t, LookPath(ctx, "nope-not-a-program"))
+}
+
+func TestCmdString(t *testing.T) {
+	assert.Equal(t, "docker push foo/bar", Cmd{
+		Args: []string{"docker", "push", "foo/bar"},
+	}.String())
+}
@@ -0,0 +1,74 @@
+// Package semver parses semantic versions, as described in
+// https://semver.org.
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version
+type Version struct {
+	Major      uint64 `json:"major"`
+	Minor      uint64 `json:"minor"`
+	Patch      uint64 `json:"patch"`
+	Prerelease string `json:"prerelease,omitempty"`
+	Metadata   string `json:"metadata,omitempty"`
+}
+
+var re = regexp.MustCompile(
+	`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`,
+)
+
+// Parse parses the given version, which must not have a `v` prefix
+func Parse(s string) (Version, error) {
+	var match = re.FindStringSubmatch(s)
+	if match == nil {
+		return Version{}, fmt.Errorf("%s is not a valid semantic version", s)
+	}
+	var v = Version{
+		Prerelease: match[4],
+		Metadata:   match[5],
+	}
+	var err error
+	for i, n := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if *n, err = strconv.ParseUint(match[i+1], 10, 64); err != nil {
+			return Version{}, fmt.Errorf("%s is not a valid semantic version: %v", s, err)
+		}
+	}
+	return v, nil
+}
+
+// ParseTolerant parses the given version like Parse, but also accepts a
+// version without the patch number, e.g. 1.2 is 1.2.0
+func ParseTolerant(s string) (Version, error) {
+	var core = s
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		core = s[:i]
+	}
+	if strings.Count(core, ".") == 1 {
+		s = core + ".0" + s[len(core):]
+	}
+	return Parse(s)
+}
+
+// IsPrerelease returns true if the version has a prerelease part, e.g.
+// 1.2.0-rc.1
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+func (v Version) String() string {
+	var s = fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Metadata != "" {
+		s += "+" + v.Metadata
+	}
+	return s
+}
@@ -0,0 +1,73 @@
+package semver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for s, expected := range map[string]Version{
+		"1.2.3":                {Major: 1, Minor: 2, Patch: 3},
+		"0.0.1":                {Patch: 1},
+		"1.2.0-rc.1":           {Major: 1, Minor: 2, Prerelease: "rc.1"},
+		"1.2.0-beta+build.5":   {Major: 1, Minor: 2, Prerelease: "beta", Metadata: "build.5"},
+		"10.20.30+20180101":    {Major: 10, Minor: 20, Patch: 30, Metadata: "20180101"},
+		"1.0.0-alpha.beta-1.2": {Major: 1, Prerelease: "alpha.beta-1.2"},
+	} {
+		t.Run(s, func(t *testing.T) {
+			v, err := Parse(s)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, v)
+			assert.Equal(t, s, v.String())
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"v1.2.3",
+		"1.2",
+		"1.2.3.4",
+		"01.2.3",
+		"1.2.3-",
+		"1.2.3-01",
+		"1.2.3+",
+		"sadasd",
+		"1.2.3-rc.1 ",
+		"99999999999999999999.0.0",
+	} {
+		t.Run(s, func(t *testing.T) {
+			_, err := Parse(s)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseTolerant(t *testing.T) {
+	for s, expected := range map[string]Version{
+		"1.2.3":        {Major: 1, Minor: 2, Patch: 3},
+		"1.2":          {Major: 1, Minor: 2},
+		"1.2-rc.1":     {Major: 1, Minor: 2, Prerelease: "rc.1"},
+		"1.2+build.5":  {Major: 1, Minor: 2, Metadata: "build.5"},
+		"1.2.0-rc.1.2": {Major: 1, Minor: 2, Prerelease: "rc.1.2"},
+	} {
+		t.Run(s, func(t *testing.T) {
+			v, err := ParseTolerant(s)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, v)
+		})
+	}
+	for _, s := range []string{"1", "1.2.3.4", "v1.2", "1.2-"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseTolerant(s)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	assert.True(t, Version{Prerelease: "rc.1"}.IsPrerelease())
+	assert.False(t, Version{Metadata: "build"}.IsPrerelease())
+}
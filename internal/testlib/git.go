@@ -1,6 +1,7 @@
 package testlib
 
 import (
+	"context"
 	"testing"
 
 	"github.com/goreleaser/goreleaser/internal/git"
@@ -50,5 +51,5 @@ func fakeGit(args ...string) (string, error) {
 		"-c", "commit.gpgSign=false",
 	}
 	allArgs = append(allArgs, args...)
-	return git.Run(allArgs...)
+	return git.Run(context.Background(), allArgs...)
 }
@@ -0,0 +1,237 @@
+// Package tmpl provides the template engine used by all the configurable
+// names, tags, targets and flags, so they all get the same fields and
+// functions.
+package tmpl
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+)
+
+// Template holds the fields available to a template
+type Template struct {
+	fields Fields
+	date   time.Time
+	env    map[string]string
+}
+
+// Fields that will be available to the template engine
+type Fields map[string]interface{}
+
+const (
+	projectName = "ProjectName"
+	version     = "Version"
+	tag         = "Tag"
+	commit      = "Commit"
+	shortCommit = "ShortCommit"
+	date        = "Date"
+	timestamp   = "Timestamp"
+	env         = "Env"
+	major       = "Major"
+	minor       = "Minor"
+	patch       = "Patch"
+	prerelease  = "Prerelease"
+	metadata    = "Metadata"
+
+	// artifact-only keys
+	osKey  = "Os"
+	arch   = "Arch"
+	arm    = "Arm"
+	amd64  = "Amd64"
+	i386   = "I386"
+	mips   = "Mips"
+	ppc64  = "Ppc64"
+	binary = "Binary"
+)
+
+// New Template with the common fields of the given context. In reproducible
+// mode, the date is the source date of the context rather than the current
+// time.
+func New(ctx *context.Context) *Template {
+	var short = ctx.Git.Commit
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	var t = &Template{
+		env: ctx.Env,
+		fields: Fields{
+			projectName: ctx.Config.ProjectName,
+			version:     ctx.Version,
+			tag:         ctx.Git.CurrentTag,
+			commit:      ctx.Git.Commit,
+			shortCommit: short,
+			env:         ctx.Env,
+			major:       ctx.Semver.Major,
+			minor:       ctx.Semver.Minor,
+			patch:       ctx.Semver.Patch,
+			prerelease:  ctx.Semver.Prerelease,
+			metadata:    ctx.Semver.Metadata,
+			osKey:       "",
+			arch:        "",
+			arm:         "",
+			amd64:       "",
+			i386:        "",
+			mips:        "",
+			ppc64:       "",
+			binary:      "",
+		},
+	}
+	if ctx.Config.Reproducible {
+		return t.WithDate(ctx.SourceDate())
+	}
+	return t.WithDate(ctx.Date)
+}
+
+// WithEnv replaces the environment variables of the .Env field and of the
+// env function
+func (t *Template) WithEnv(e map[string]string) *Template {
+	t.env = e
+	t.fields[env] = e
+	return t
+}
+
+// WithDate sets the date of the .Date and .Timestamp fields and of the time
+// function
+func (t *Template) WithDate(d time.Time) *Template {
+	t.date = d
+	t.fields[date] = d.UTC().Format(time.RFC3339)
+	t.fields[timestamp] = d.Unix()
+	return t
+}
+
+// WithArtifact adds the os, arch and arm (or other variant) of the given
+// artifact, replaced by the given replacements, and the name of the build
+// it came from
+func (t *Template) WithArtifact(a artifact.Artifact, replacements map[string]string) *Template {
+	t.fields[osKey] = replace(replacements, a.Goos)
+	t.fields[arch] = replace(replacements, a.Goarch)
+	t.fields[arm] = replace(replacements, a.Goarm)
+	t.fields[amd64] = replace(replacements, a.Goamd64)
+	t.fields[i386] = replace(replacements, a.Go386)
+	t.fields[mips] = replace(replacements, a.Gomips)
+	t.fields[ppc64] = replace(replacements, a.Goppc64)
+	t.fields[binary] = a.Extra["Binary"]
+	return t
+}
+
+// WithFields adds the given fields, overriding the existing ones
+func (t *Template) WithFields(fields Fields) *Template {
+	for k, v := range fields {
+		t.fields[k] = v
+	}
+	return t
+}
+
+// Apply applies the given string against the fields
+func (t *Template) Apply(s string) (string, error) {
+	var out bytes.Buffer
+	tmpl, err := t.parse(s)
+	if err != nil {
+		return "", err
+	}
+	if err := t.checkFields(tmpl); err != nil {
+		return "", err
+	}
+	err = tmpl.Execute(&out, t.fields)
+	return out.String(), err
+}
+
+// Validate checks if the given string is a valid template, without
+// applying it
+func Validate(s string) error {
+	_, err := (&Template{}).parse(s)
+	return err
+}
+
+func (t *Template) parse(s string) (*template.Template, error) {
+	return template.New("tmpl").
+		Funcs(template.FuncMap{
+			"replace": func(s, old, new string) string {
+				return strings.Replace(s, old, new, -1)
+			},
+			"toupper":    strings.ToUpper,
+			"tolower":    strings.ToLower,
+			"trimprefix": strings.TrimPrefix,
+			"trimsuffix": strings.TrimSuffix,
+			"time": func(layout string) string {
+				return t.date.UTC().Format(layout)
+			},
+			"env": func(key string) string {
+				return t.env[key]
+			},
+			"default": func(def, value string) string {
+				if value == "" {
+					return def
+				}
+				return value
+			},
+		}).
+		Parse(s)
+}
+
+// checkFields fails if the template uses a field that doesn't exist, e.g.
+// a typo. The environment variables that are not set are not checked, and
+// give <no value> as usual.
+func (t *Template) checkFields(tmpl *template.Template) error {
+	var err error
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			// the fields inside of it are the ones of the elements
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.ElseList)
+		case *parse.FieldNode:
+			if _, ok := t.fields[n.Ident[0]]; !ok && err == nil {
+				location, _ := tmpl.ErrorContext(n)
+				err = fmt.Errorf("template: %s: unknown field .%s", location, n.Ident[0])
+			}
+		}
+	}
+	walk(tmpl.Root)
+	return err
+}
+
+func replace(replacements map[string]string, original string) string {
+	result := replacements[original]
+	if result == "" {
+		return original
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+package tmpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func testContext() *context.Context {
+	var ctx = context.New(config.Project{ProjectName: "proj"})
+	ctx.Version = "1.0.0"
+	ctx.Semver = semver.Version{Major: 1, Metadata: "build.1"}
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		Commit:     "6a4f0b1c2d3e4f5a",
+	}
+	ctx.Date = time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx.Env = map[string]string{"FOO": "bar"}
+	return ctx
+}
+
+func TestCommonFields(t *testing.T) {
+	var ctx = testContext()
+	for expected, s := range map[string]string{
+		"proj":                 "{{.ProjectName}}",
+		"1.0.0":                "{{.Version}}",
+		"v1.0.0":               "{{.Tag}}",
+		"6a4f0b1c2d3e4f5a":     "{{.Commit}}",
+		"6a4f0b1":              "{{.ShortCommit}}",
+		"2018-01-02T03:04:05Z": "{{.Date}}",
+		"1514862245":           "{{.Timestamp}}",
+		"bar":                  "{{.Env.FOO}}",
+		"__":                   "{{.Os}}_{{.Arch}}_{{.Arm}}",
+		"1.0.0+build.1":        "{{.Major}}.{{.Minor}}.{{.Patch}}{{if .Prerelease}}-{{.Prerelease}}{{end}}+{{.Metadata}}",
+	} {
+		t.Run(expected, func(t *testing.T) {
+			result, err := New(ctx).Apply(s)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestWithArtifact(t *testing.T) {
+	var ctx = testContext()
+	result, err := New(ctx).WithArtifact(
+		artifact.Artifact{
+			Goos:   "darwin",
+			Goarch: "arm",
+			Goarm:  "7",
+			Extra:  map[string]string{"Binary": "mybin"},
+		},
+		map[string]string{"darwin": "macOS"},
+	).Apply("{{.Binary}}_{{.Os}}_{{.Arch}}_{{.Arm}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "mybin_macOS_arm_7", result)
+}
+
+func TestWithArtifactVariants(t *testing.T) {
+	var ctx = testContext()
+	result, err := New(ctx).WithArtifact(
+		artifact.Artifact{
+			Goos:   "linux",
+			Goarch: "mips",
+			Gomips: "softfloat",
+		},
+		map[string]string{"softfloat": "sf"},
+	).Apply("{{.Os}}_{{.Arch}}_{{.Mips}}{{.Arm}}{{.Amd64}}{{.I386}}{{.Ppc64}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "linux_mips_sf", result)
+}
+
+func TestWithFields(t *testing.T) {
+	result, err := New(testContext()).
+		WithFields(Fields{"ProjectName": "other", "Foo": "foo"}).
+		Apply("{{.ProjectName}}-{{.Foo}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "other-foo", result)
+}
+
+func TestFuncs(t *testing.T) {
+	var ctx = testContext()
+	for expected, s := range map[string]string{
+		"1_0_0":      `{{ replace .Version "." "_" }}`,
+		"V1.0.0":     `{{ toupper .Tag }}`,
+		"proj":       `{{ tolower "PROJ" }}`,
+		"1.0.0":      `{{ trimprefix .Tag "v" }}`,
+		"v1.0":       `{{ trimsuffix .Tag ".0" }}`,
+		"2018-01-02": `{{ time "2006-01-02" }}`,
+		"bar":        `{{ env "FOO" }}`,
+		"y":          `{{ env "NOPE" | default "y" }}`,
+	} {
+		t.Run(expected, func(t *testing.T) {
+			result, err := New(ctx).Apply(s)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestReproducibleDate(t *testing.T) {
+	var ctx = testContext()
+	ctx.Config.Reproducible = true
+	ctx.Git.CommitDate = time.Date(2017, 6, 7, 8, 9, 10, 0, time.UTC)
+	for expected, s := range map[string]string{
+		"2017-06-07T08:09:10Z": "{{.Date}}",
+		"1496822950":           "{{.Timestamp}}",
+		"2017-06-07":           `{{ time "2006-01-02" }}`,
+	} {
+		t.Run(expected, func(t *testing.T) {
+			result, err := New(ctx).Apply(s)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestWithDate(t *testing.T) {
+	var date = time.Date(2017, 6, 7, 8, 9, 10, 0, time.UTC)
+	result, err := New(testContext()).WithDate(date).Apply(`{{.Date}} {{ time "2006" }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "2017-06-07T08:09:10Z 2017", result)
+}
+
+func TestWithEnv(t *testing.T) {
+	result, err := New(testContext()).
+		WithEnv(map[string]string{"FOO": "baz"}).
+		Apply(`{{ .Env.FOO }} {{ env "FOO" }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "baz baz", result)
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	_, err := New(testContext()).Apply("{{ .Version }")
+	assert.EqualError(t, err, `template: tmpl:1: unexpected "}" in operand`)
+}
+
+func TestMissingField(t *testing.T) {
+	for _, s := range []string{
+		"{{.Versoin}}",
+		"{{ if .Versoin }}{{ end }}",
+		`{{ replace .Versoin "." "_" }}`,
+		"{{ .Env.FOO }}{{ .Versoin.Major }}",
+	} {
+		_, err := New(testContext()).Apply(s)
+		assert.Error(t, err, s)
+		assert.Contains(t, err.Error(), "unknown field .Versoin", s)
+	}
+}
+
+func TestMissingEnv(t *testing.T) {
+	result, err := New(testContext()).Apply("{{.Env.NOPE}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "<no value>", result)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(`{{ .Version }}_{{ env "FOO" | default "bar" }}`))
+	assert.EqualError(t, Validate("{{ .Version }"), `template: tmpl:1: unexpected "}" in operand`)
+	assert.Error(t, Validate(`{{ nope .Version }}`))
+}
@@ -46,10 +46,22 @@ func main() {
 			Name:  "skip-publish",
 			Usage: "Skip all publishing pipes of the release",
 		},
+		cli.StringFlag{
+			Name:  "skip",
+			Usage: "Skip the given comma separated list of pipes, e.g. docker,snapcraft,sign",
+		},
+		cli.StringFlag{
+			Name:  "only",
+			Usage: "Run only the given comma separated list of pipes, e.g. build,archive",
+		},
 		cli.BoolFlag{
 			Name:  "snapshot",
 			Usage: "Generate an unversioned snapshot release",
 		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Log external commands and uploads instead of running them",
+		},
 		cli.BoolFlag{
 			Name:  "rm-dist",
 			Usage: "Remove ./dist before building",
@@ -59,6 +71,11 @@ func main() {
 			Usage: "Amount of builds launch in parallel",
 			Value: 4,
 		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Timeout to the entire release process",
+			Value: 30 * time.Minute,
+		},
 		cli.BoolFlag{
 			Name:  "debug",
 			Usage: "Enable debug mode",
@@ -91,6 +108,132 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:  "check",
+			Usage: "check the config file for problems",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "config, file, c, f",
+					Usage: "Load configuration from `FILE`",
+					Value: ".goreleaser.yml",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if err := goreleaserlib.Check(c); err != nil {
+					log.WithError(err).Error("check failed")
+					return cli.NewExitError("\n", 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "targets",
+			Usage: "print the targets of each build",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "config, file, c, f",
+					Usage: "Load configuration from `FILE`",
+					Value: ".goreleaser.yml",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if err := goreleaserlib.Targets(c); err != nil {
+					log.WithError(err).Error("failed to list targets")
+					return cli.NewExitError("\n", 1)
+				}
+				return nil
+			},
+		},
+		{
+			Name:  "publish",
+			Usage: "publish the artifacts of a previous run",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "from-dist",
+					Usage: "Publish the artifacts and config found in the dist folder, without building them again",
+				},
+				cli.StringFlag{
+					Name:  "dist",
+					Usage: "Load artifacts from `DIR`",
+					Value: "dist",
+				},
+				cli.StringFlag{
+					Name:  "release-notes",
+					Usage: "Load custom release notes from a markdown `FILE`",
+				},
+				cli.StringFlag{
+					Name:  "skip",
+					Usage: "Skip the given comma separated list of pipes, e.g. docker,brew",
+				},
+				cli.StringFlag{
+					Name:  "only",
+					Usage: "Run only the given comma separated list of pipes, e.g. release",
+				},
+				cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Log external commands and uploads instead of running them",
+				},
+				cli.IntFlag{
+					Name:  "parallelism, p",
+					Usage: "Amount of uploads launch in parallel",
+					Value: 4,
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Timeout to the entire publish process",
+					Value: 30 * time.Minute,
+				},
+				cli.BoolFlag{
+					Name:  "debug",
+					Usage: "Enable debug mode",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				start := time.Now()
+				log.Infof("\033[1mpublishing...\033[0m")
+				if err := goreleaserlib.Publish(c); err != nil {
+					log.WithError(err).Errorf("\033[1mpublish failed after %0.2fs\033[0m", time.Since(start).Seconds())
+					return cli.NewExitError("\n", 1)
+				}
+				log.Infof("\033[1mpublish succeeded after %0.2fs\033[0m", time.Since(start).Seconds())
+				return nil
+			},
+		},
+		{
+			Name:  "verify-reproducible",
+			Usage: "build the release in the dist folder again and compare the artifacts",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "dist",
+					Usage: "Compare with the artifacts in `DIR`",
+					Value: "dist",
+				},
+				cli.IntFlag{
+					Name:  "parallelism, p",
+					Usage: "Amount of builds launch in parallel",
+					Value: 4,
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Timeout to the entire verification",
+					Value: 30 * time.Minute,
+				},
+				cli.BoolFlag{
+					Name:  "debug",
+					Usage: "Enable debug mode",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				start := time.Now()
+				log.Infof("\033[1mverifying...\033[0m")
+				if err := goreleaserlib.VerifyReproducible(c); err != nil {
+					log.WithError(err).Errorf("\033[1mverification failed after %0.2fs\033[0m", time.Since(start).Seconds())
+					return cli.NewExitError("\n", 1)
+				}
+				log.Infof("\033[1mrelease is reproducible, verified after %0.2fs\033[0m", time.Since(start).Seconds())
+				return nil
+			},
+		},
 	}
 	if err := app.Run(os.Args); err != nil {
 		log.WithError(err).Fatal("failed")
@@ -7,15 +7,24 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/apex/log"
-	"github.com/goreleaser/archive"
+	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/archive"
 	"github.com/goreleaser/goreleaser/internal/archiveformat"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/nametemplate"
 	"github.com/mattn/go-zglob"
 	"golang.org/x/sync/errgroup"
 )
 
+// platformTemplate is the end of the default name template of the archives,
+// after the name of the binary or the IDs of the builds
+const platformTemplate = "_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ .Amd64 }}{{ if .I386 }}_{{ .I386 }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}{{ if .Ppc64 }}_{{ .Ppc64 }}{{ end }}"
+
 // Pipe for archive
 type Pipe struct{}
 
@@ -23,32 +32,66 @@ func (Pipe) String() string {
 	return "creating archives"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "archive"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"build"}
+}
+
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
 	var g errgroup.Group
-	for platform, binaries := range ctx.Binaries {
-		platform := platform
-		binaries := binaries
-		g.Go(func() error {
-			if ctx.Config.Archive.Format == "binary" {
-				return skip(ctx, platform, binaries)
-			}
-			return create(ctx, platform, binaries)
-		})
+	for _, archive := range ctx.Config.AllArchives() {
+		archive := archive
+		var filtered = ctx.Artifacts.Filter(
+			artifact.And(
+				artifact.ByType(artifact.Binary),
+				artifact.ByIDs(archive.Builds...),
+			),
+		)
+		for _, artifacts := range filtered.GroupByPlatform() {
+			artifacts := artifacts
+			g.Go(func() error {
+				if archive.Format == "binary" {
+					return skip(ctx, artifacts)
+				}
+				return create(ctx, archive, artifacts)
+			})
+		}
 	}
 	return g.Wait()
 }
 
 // Default sets the pipe defaults
 func (Pipe) Default(ctx *context.Context) error {
-	if ctx.Config.Archive.NameTemplate == "" {
-		ctx.Config.Archive.NameTemplate = "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}"
+	if len(ctx.Config.Archives) == 0 {
+		ctx.Config.Archive = archiveWithDefaults(ctx.Config.Archive, false)
+	}
+	for i, archive := range ctx.Config.Archives {
+		ctx.Config.Archives[i] = archiveWithDefaults(archive, len(ctx.Config.Archives) > 1)
 	}
-	if ctx.Config.Archive.Format == "" {
-		ctx.Config.Archive.Format = "tar.gz"
+	return nil
+}
+
+// archiveWithDefaults sets the defaults of the given archive. If there are
+// several archives, the default name starts with the IDs of the builds of
+// the archive, so the archives of builds of the same binary don't collide.
+func archiveWithDefaults(archive config.Archive, several bool) config.Archive {
+	if archive.NameTemplate == "" {
+		archive.NameTemplate = "{{ .Binary }}" + platformTemplate
+		if several && len(archive.Builds) > 0 {
+			archive.NameTemplate = strings.Join(archive.Builds, "_") + platformTemplate
+		}
 	}
-	if len(ctx.Config.Archive.Files) == 0 {
-		ctx.Config.Archive.Files = []string{
+	if archive.Format == "" {
+		archive.Format = "tar.gz"
+	}
+	if len(archive.Files) == 0 {
+		archive.Files = []string{
 			"licence*",
 			"LICENCE*",
 			"license*",
@@ -59,73 +102,120 @@ func (Pipe) Default(ctx *context.Context) error {
 			"CHANGELOG*",
 		}
 	}
-	return nil
+	return archive
 }
 
-func create(ctx *context.Context, platform string, groups map[string][]context.Binary) error {
-	for folder, binaries := range groups {
-		var format = archiveformat.For(ctx, platform)
-		archivePath := filepath.Join(ctx.Config.Dist, folder+"."+format)
-		archiveFile, err := os.Create(archivePath)
-		if err != nil {
-			return fmt.Errorf("failed to create directory %s: %s", archivePath, err.Error())
+func create(ctx *context.Context, archiveCfg config.Archive, binaries []artifact.Artifact) error {
+	var format = archiveformat.For(archiveCfg, binaries[0].Goos)
+	folder, err := nametemplate.Apply(ctx, archiveCfg, binaries[0], ctx.Config.ProjectName)
+	if err != nil {
+		return err
+	}
+	archivePath := filepath.Join(ctx.Config.Dist, folder+"."+format)
+	var result = artifact.Artifact{
+		Type:    artifact.UploadableArchive,
+		Name:    folder + "." + format,
+		Path:    archivePath,
+		Goos:    binaries[0].Goos,
+		Goarch:  binaries[0].Goarch,
+		Goarm:   binaries[0].Goarm,
+		Goamd64: binaries[0].Goamd64,
+		Go386:   binaries[0].Go386,
+		Gomips:  binaries[0].Gomips,
+		Goppc64: binaries[0].Goppc64,
+		Extra: map[string]string{
+			"ID": artifact.JoinIDs(binaries),
+		},
+	}
+	if ctx.DryRun {
+		log.WithField("archive", archivePath).Info("dry-run: would create")
+		ctx.Artifacts.Add(result)
+		return nil
+	}
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create directory %s: %s", archivePath, err.Error())
+	}
+	defer func() {
+		if e := archiveFile.Close(); e != nil {
+			log.WithField("archive", archivePath).Errorf("failed to close file: %v", e)
 		}
-		defer func() {
-			if e := archiveFile.Close(); e != nil {
-				log.WithField("archive", archivePath).Errorf("failed to close file: %v", e)
-			}
-		}()
-		log.WithField("archive", archivePath).Info("creating")
-		var a = archive.New(archiveFile)
-		defer func() {
-			if e := a.Close(); e != nil {
-				log.WithField("archive", archivePath).Errorf("failed to close archive: %v", e)
-			}
-		}()
+	}()
+	log.WithField("archive", archivePath).Info("creating")
+	var a = archive.New(archiveFile)
+	if ctx.Config.Reproducible {
+		a = archive.NewReproducible(archiveFile, ctx.SourceDate())
+	}
+	defer func() {
+		if e := a.Close(); e != nil {
+			log.WithField("archive", archivePath).Errorf("failed to close archive: %v", e)
+		}
+	}()
 
-		files, err := findFiles(ctx)
+	files, err := findFiles(archiveCfg)
+	if err != nil {
+		return fmt.Errorf("failed to find files to archive: %s", err.Error())
+	}
+	for _, f := range files {
+		if err = a.Add(wrap(archiveCfg, f, folder), f); err != nil {
+			return fmt.Errorf("failed to add %s to the archive: %s", f, err.Error())
+		}
+	}
+	if archiveCfg.WasmExec && binaries[0].Goos == "js" && binaries[0].Goarch == "wasm" {
+		path, err := wasmExec(ctx)
 		if err != nil {
-			return fmt.Errorf("failed to find files to archive: %s", err.Error())
+			return err
 		}
-		for _, f := range files {
-			if err = a.Add(wrap(ctx, f, folder), f); err != nil {
-				return fmt.Errorf("failed to add %s to the archive: %s", f, err.Error())
-			}
+		if err := a.Add(wrap(archiveCfg, filepath.Base(path), folder), path); err != nil {
+			return fmt.Errorf("failed to add %s to the archive: %s", path, err.Error())
 		}
-		for _, binary := range binaries {
-			if err := a.Add(wrap(ctx, binary.Name, folder), binary.Path); err != nil {
-				return fmt.Errorf("failed to add %s -> %s to the archive: %s", binary.Path, binary.Name, err.Error())
-			}
+	}
+	sort.Slice(binaries, func(i, j int) bool {
+		return binaries[i].Name < binaries[j].Name
+	})
+	for _, binary := range binaries {
+		if err := a.Add(wrap(archiveCfg, binary.Name, folder), binary.Path); err != nil {
+			return fmt.Errorf("failed to add %s -> %s to the archive: %s", binary.Path, binary.Name, err.Error())
 		}
-		ctx.AddArtifact(archivePath)
 	}
+	ctx.Artifacts.Add(result)
 	return nil
 }
 
-func skip(ctx *context.Context, platform string, groups map[string][]context.Binary) error {
-	for _, binaries := range groups {
-		for _, binary := range binaries {
-			log.WithField("binary", binary.Name).Info("skip archiving")
-			ctx.AddArtifact(binary.Path)
-		}
+func skip(ctx *context.Context, binaries []artifact.Artifact) error {
+	for _, binary := range binaries {
+		log.WithField("binary", binary.Name).Info("skip archiving")
+		var uploadable = binary
+		uploadable.Type = artifact.UploadableBinary
+		uploadable.Name = filepath.Base(binary.Path)
+		ctx.Artifacts.Add(uploadable)
 	}
 	return nil
 }
 
-func findFiles(ctx *context.Context) (result []string, err error) {
-	for _, glob := range ctx.Config.Archive.Files {
+// findFiles returns the files matching the archive globs, sorted and
+// without duplicates, so the archive entries are always in the same order
+func findFiles(archive config.Archive) (result []string, err error) {
+	var seen = map[string]bool{}
+	for _, glob := range archive.Files {
 		files, err := zglob.Glob(glob)
 		if err != nil {
 			return result, fmt.Errorf("globbing failed for pattern %s: %s", glob, err.Error())
 		}
-		result = append(result, files...)
+		for _, file := range files {
+			if !seen[file] {
+				seen[file] = true
+				result = append(result, file)
+			}
+		}
 	}
+	sort.Strings(result)
 	return
 }
 
 // Wrap archive files with folder if set in config.
-func wrap(ctx *context.Context, name, folder string) string {
-	if ctx.Config.Archive.WrapInDirectory {
+func wrap(archive config.Archive, name, folder string) string {
+	if archive.WrapInDirectory {
 		return filepath.Join(folder, name)
 	}
 	return name
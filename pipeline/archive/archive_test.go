@@ -4,12 +4,16 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/stretchr/testify/assert"
 )
@@ -33,8 +37,10 @@ func TestRunPipe(t *testing.T) {
 	assert.NoError(t, err)
 	var ctx = &context.Context{
 		Config: config.Project{
-			Dist: dist,
+			Dist:        dist,
+			ProjectName: "mybin",
 			Archive: config.Archive{
+				NameTemplate: "{{.ProjectName}}_{{.Os}}_{{.Arch}}",
 				Files: []string{
 					"README.*",
 				},
@@ -47,14 +53,35 @@ func TestRunPipe(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddBinary("darwinamd64", "mybin_darwin_amd64", "mybin", filepath.Join(dist, "mybin_darwin_amd64", "mybin"))
-	ctx.AddBinary("windowsamd64", "mybin_windows_amd64", "mybin.exe", filepath.Join(dist, "mybin_windows_amd64", "mybin.exe"))
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "mybin_darwin_amd64", "mybin"),
+		Type:   artifact.Binary,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "windows",
+		Goarch: "amd64",
+		Name:   "mybin.exe",
+		Path:   filepath.Join(dist, "mybin_windows_amd64", "mybin.exe"),
+		Type:   artifact.Binary,
+	})
 	for _, format := range []string{"tar.gz", "zip"} {
 		t.Run("Archive format "+format, func(t *testing.T) {
 			ctx.Config.Archive.Format = format
 			assert.NoError(t, Pipe{}.Run(ctx))
 		})
 	}
+	var archives = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	assert.Len(t, archives, 4)
+	for _, archive := range archives {
+		if archive.Goos == "windows" {
+			assert.Equal(t, "mybin_windows_amd64.zip", archive.Name)
+		} else {
+			assert.Contains(t, []string{"mybin_darwin_amd64.tar.gz", "mybin_darwin_amd64.zip"}, archive.Name)
+		}
+	}
 
 	// Check archive contents
 	f, err := os.Open(filepath.Join(dist, "mybin_darwin_amd64.tar.gz"))
@@ -74,6 +101,123 @@ func TestRunPipe(t *testing.T) {
 	}
 }
 
+func TestRunPipeWasm(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	assert.NoError(t, os.Mkdir(dist, 0755))
+	assert.NoError(t, os.Mkdir(filepath.Join(dist, "mybin_js_wasm"), 0755))
+	assert.NoError(t, os.Mkdir(filepath.Join(dist, "mybin_wasip1_wasm"), 0755))
+	_, err := os.Create(filepath.Join(dist, "mybin_js_wasm", "mybin.wasm"))
+	assert.NoError(t, err)
+	_, err = os.Create(filepath.Join(dist, "mybin_wasip1_wasm", "mybin.wasm"))
+	assert.NoError(t, err)
+	var ctx = context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "mybin",
+		Archive: config.Archive{
+			Format:       "tar.gz",
+			NameTemplate: "{{.ProjectName}}_{{.Os}}_{{.Arch}}",
+			WasmExec:     true,
+			Replacements: map[string]string{
+				"js":   "browser",
+				"wasm": "WebAssembly",
+			},
+		},
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "js",
+		Goarch: "wasm",
+		Name:   "mybin.wasm",
+		Path:   filepath.Join(dist, "mybin_js_wasm", "mybin.wasm"),
+		Type:   artifact.Binary,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "wasip1",
+		Goarch: "wasm",
+		Name:   "mybin.wasm",
+		Path:   filepath.Join(dist, "mybin_wasip1_wasm", "mybin.wasm"),
+		Type:   artifact.Binary,
+	})
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var names []string
+	for _, archive := range ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List() {
+		names = append(names, archive.Name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"mybin_browser_WebAssembly.tar.gz", "mybin_wasip1_WebAssembly.tar.gz"}, names)
+	assert.Equal(t, []string{"wasm_exec.js", "mybin.wasm"}, tarNames(t, filepath.Join(dist, "mybin_browser_WebAssembly.tar.gz")))
+	// wasm_exec.js is only needed to run js/wasm binaries in a browser
+	assert.Equal(t, []string{"mybin.wasm"}, tarNames(t, filepath.Join(dist, "mybin_wasip1_WebAssembly.tar.gz")))
+}
+
+func tarNames(t *testing.T, path string) (names []string) {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, f.Close()) }()
+	gr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	defer func() { assert.NoError(t, gr.Close()) }()
+	r := tar.NewReader(gr)
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			return
+		}
+		assert.NoError(t, err)
+		names = append(names, h.Name)
+	}
+}
+
+func TestRunPipeReproducible(t *testing.T) {
+	for name, reproducible := range map[string]bool{
+		"default":      false,
+		"reproducible": true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			folder, back := testlib.Mktmp(t)
+			defer back()
+			var dist = filepath.Join(folder, "dist")
+			assert.NoError(t, os.MkdirAll(filepath.Join(dist, "mybin_linux_amd64"), 0755))
+			var binary = filepath.Join(dist, "mybin_linux_amd64", "mybin")
+			assert.NoError(t, ioutil.WriteFile(binary, []byte("bin"), 0700))
+			var ctx = context.New(config.Project{
+				Dist:         dist,
+				ProjectName:  "mybin",
+				Reproducible: reproducible,
+				Archive: config.Archive{
+					NameTemplate: "{{.ProjectName}}_{{.Os}}_{{.Arch}}",
+					Format:       "tar.gz",
+				},
+			})
+			ctx.Git.CommitDate = time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+			ctx.Artifacts.Add(artifact.Artifact{
+				Goos:   "linux",
+				Goarch: "amd64",
+				Name:   "mybin",
+				Path:   binary,
+				Type:   artifact.Binary,
+			})
+			assert.NoError(t, Pipe{}.Run(ctx))
+			f, err := os.Open(filepath.Join(dist, "mybin_linux_amd64.tar.gz"))
+			assert.NoError(t, err)
+			defer f.Close() // nolint: errcheck
+			gr, err := gzip.NewReader(f)
+			assert.NoError(t, err)
+			h, err := tar.NewReader(gr).Next()
+			assert.NoError(t, err)
+			if reproducible {
+				assert.True(t, ctx.Git.CommitDate.Equal(h.ModTime))
+				assert.Equal(t, int64(0755), h.Mode)
+				return
+			}
+			// the file keeps what it has on disk
+			assert.False(t, ctx.Git.CommitDate.Equal(h.ModTime))
+			assert.Equal(t, int64(0700), h.Mode&0777)
+		})
+	}
+}
+
 func TestRunPipeBinary(t *testing.T) {
 	folder, back := testlib.Mktmp(t)
 	defer back()
@@ -98,12 +242,27 @@ func TestRunPipeBinary(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddBinary("darwinamd64", "mybin_darwin", "mybin", filepath.Join(dist, "mybin_darwin", "mybin"))
-	ctx.AddBinary("windowsamd64", "mybin_win", "mybin.exe", filepath.Join(dist, "mybin_win", "mybin.exe"))
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "mybin_darwin", "mybin"),
+		Type:   artifact.Binary,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "windows",
+		Goarch: "amd64",
+		Name:   "mybin.exe",
+		Path:   filepath.Join(dist, "mybin_win", "mybin.exe"),
+		Type:   artifact.Binary,
+	})
 	assert.NoError(t, Pipe{}.Run(ctx))
-	assert.Contains(t, ctx.Artifacts, "mybin_darwin/mybin")
-	assert.Contains(t, ctx.Artifacts, "mybin_win/mybin.exe")
-	assert.Len(t, ctx.Artifacts, 2)
+	var binaries = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableBinary))
+	var darwin = binaries.Filter(artifact.ByGoos("darwin")).List()[0]
+	var windows = binaries.Filter(artifact.ByGoos("windows")).List()[0]
+	assert.Equal(t, "mybin", darwin.Name)
+	assert.Equal(t, "mybin.exe", windows.Name)
+	assert.Len(t, binaries.List(), 2)
 }
 
 func TestRunPipeDistRemoved(t *testing.T) {
@@ -115,7 +274,13 @@ func TestRunPipeDistRemoved(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddBinary("windowsamd64", "nope", "no", "blah")
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "windows",
+		Goarch: "amd64",
+		Name:   "mybin.exe",
+		Path:   filepath.Join("dist", "mybin_windows_amd64", "mybin.exe"),
+		Type:   artifact.Binary,
+	})
 	assert.Error(t, Pipe{}.Run(ctx))
 }
 
@@ -130,7 +295,13 @@ func TestRunPipeInvalidGlob(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddBinary("windowsamd64", "whatever", "foo", "bar")
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "windows",
+		Goarch: "amd64",
+		Name:   "foo",
+		Path:   "bar",
+		Type:   artifact.Binary,
+	})
 	assert.Error(t, Pipe{}.Run(ctx))
 }
 
@@ -149,7 +320,13 @@ func TestRunPipeGlobFailsToAdd(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddBinary("windows386", "mybin", "mybin", "dist/mybin")
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "windows",
+		Goarch: "386",
+		Name:   "mybin",
+		Path:   "dist/mybin",
+		Type:   artifact.Binary,
+	})
 	assert.Error(t, Pipe{}.Run(ctx))
 }
 
@@ -165,8 +342,10 @@ func TestRunPipeWrap(t *testing.T) {
 	assert.NoError(t, err)
 	var ctx = &context.Context{
 		Config: config.Project{
-			Dist: dist,
+			Dist:        dist,
+			ProjectName: "mybin",
 			Archive: config.Archive{
+				NameTemplate:    "{{.ProjectName}}_{{.Os}}_{{.Arch}}",
 				WrapInDirectory: true,
 				Format:          "tar.gz",
 				Files: []string{
@@ -175,7 +354,13 @@ func TestRunPipeWrap(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddBinary("darwinamd64", "mybin_darwin_amd64", "mybin", filepath.Join(dist, "mybin_darwin_amd64", "mybin"))
+	ctx.Artifacts.Add(artifact.Artifact{
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "mybin_darwin_amd64", "mybin"),
+		Type:   artifact.Binary,
+	})
 	assert.NoError(t, Pipe{}.Run(ctx))
 
 	// Check archive contents
@@ -225,3 +410,118 @@ func TestDefaultSet(t *testing.T) {
 	assert.Equal(t, "zip", ctx.Config.Archive.Format)
 	assert.Equal(t, "foo", ctx.Config.Archive.Files[0])
 }
+
+func TestDefaultArchives(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Archives: []config.Archive{
+				{Format: "zip"},
+				{},
+			},
+		},
+	}
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, config.Archive{}, ctx.Config.Archive)
+	assert.Equal(t, "zip", ctx.Config.Archives[0].Format)
+	assert.Equal(t, "tar.gz", ctx.Config.Archives[1].Format)
+	for _, archive := range ctx.Config.Archives {
+		assert.NotEmpty(t, archive.NameTemplate)
+		assert.NotEmpty(t, archive.Files)
+	}
+}
+
+func TestDefaultArchivesNameTemplates(t *testing.T) {
+	var ctx = &context.Context{
+		Config: config.Project{
+			Archives: []config.Archive{
+				{Builds: []string{"server"}},
+				{Builds: []string{"server-static", "client"}},
+				{},
+			},
+		},
+	}
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, "server"+platformTemplate, ctx.Config.Archives[0].NameTemplate)
+	assert.Equal(t, "server-static_client"+platformTemplate, ctx.Config.Archives[1].NameTemplate)
+	assert.Equal(t, "{{ .Binary }}"+platformTemplate, ctx.Config.Archives[2].NameTemplate)
+
+	// a single archive is named after the binary, like the archive section
+	ctx.Config.Archives = []config.Archive{{Builds: []string{"server"}}}
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(t, "{{ .Binary }}"+platformTemplate, ctx.Config.Archives[0].NameTemplate)
+}
+
+func TestRunPipeMultipleArchives(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	assert.NoError(t, os.Mkdir(dist, 0755))
+	var ctx = context.New(config.Project{
+		Dist:        dist,
+		ProjectName: "myproj",
+		Archives: []config.Archive{
+			{
+				NameTemplate: "server_{{.Os}}",
+				Format:       "tar.gz",
+				Builds:       []string{"server"},
+			},
+			{
+				NameTemplate: "client_{{.Os}}",
+				Format:       "tar.gz",
+				Builds:       []string{"client"},
+			},
+			{
+				NameTemplate: "all_{{.Os}}",
+				Format:       "tar.gz",
+			},
+		},
+	})
+	for _, name := range []string{"server", "client"} {
+		var path = filepath.Join(dist, name)
+		_, err := os.Create(path)
+		assert.NoError(t, err)
+		ctx.Artifacts.Add(artifact.Artifact{
+			Goos:   "linux",
+			Goarch: "amd64",
+			Name:   name,
+			Path:   path,
+			Type:   artifact.Binary,
+			Extra:  map[string]string{"ID": name},
+		})
+	}
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var contents = map[string][]string{}
+	for _, archive := range ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List() {
+		contents[archive.Name] = tarFiles(t, archive.Path)
+		switch archive.Name {
+		case "all_linux.tar.gz":
+			assert.Equal(t, "client,server", archive.Extra["ID"])
+		default:
+			assert.Contains(t, archive.Name, archive.Extra["ID"])
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"server_linux.tar.gz": {"server"},
+		"client_linux.tar.gz": {"client"},
+		"all_linux.tar.gz":    {"client", "server"},
+	}, contents)
+}
+
+func tarFiles(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+	gr, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+	var r = tar.NewReader(gr)
+	var result []string
+	for {
+		h, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		result = append(result, h.Name)
+	}
+	return result
+}
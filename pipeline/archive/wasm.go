@@ -0,0 +1,32 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/runner"
+)
+
+// wasmExec returns the path of the wasm_exec.js of the go toolchain the
+// binaries were built with, which is needed to run js/wasm binaries in a
+// browser
+func wasmExec(ctx *context.Context) (string, error) {
+	out, err := runner.Output(ctx, runner.Cmd{
+		Args: []string{"go", "env", "GOROOT"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to find the GOROOT: %s", err.Error())
+	}
+	var goroot = strings.TrimSpace(string(out))
+	// it moved from misc/wasm to lib/wasm in go 1.24
+	for _, dir := range []string{"lib", "misc"} {
+		var path = filepath.Join(goroot, dir, "wasm", "wasm_exec.js")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("wasm_exec.js not found in GOROOT %s", goroot)
+}
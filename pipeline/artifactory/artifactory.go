@@ -2,10 +2,8 @@
 package artifactory
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"html/template"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -16,7 +14,10 @@ import (
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/buildtarget"
+	"github.com/goreleaser/goreleaser/internal/retry"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pipeline"
 
 	"github.com/apex/log"
@@ -60,6 +61,16 @@ func (Pipe) String() string {
 	return "releasing to Artifactory"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "artifactory"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"build"}
+}
+
 // Default sets the pipe defaults
 func (Pipe) Default(ctx *context.Context) error {
 	if len(ctx.Config.Artifactories) == 0 {
@@ -100,7 +111,7 @@ func (Pipe) Run(ctx *context.Context) error {
 		}
 
 		envName := fmt.Sprintf("ARTIFACTORY_%s_SECRET", strings.ToUpper(instance.Name))
-		if _, ok := ctx.Env[envName]; !ok {
+		if _, ok := ctx.Env[envName]; !ok && !ctx.DryRun {
 			return pipeline.Skip(fmt.Sprintf("missing secret for artifactory instance %s", instance.Name))
 		}
 	}
@@ -126,6 +137,9 @@ func doRun(ctx *context.Context) error {
 		case modeBinary:
 			// Loop over all builds, because we want to publish every build to Artifactory
 			for _, build := range ctx.Config.Builds {
+				if !selects(instance, build) {
+					continue
+				}
 				if err = runPipeForModeBinary(ctx, instance, build); err != nil {
 					return err
 				}
@@ -147,21 +161,39 @@ func doRun(ctx *context.Context) error {
 	return nil
 }
 
-// runPipeForModeArchive uploads all ctx.Artifacts to instance
+// runPipeForModeArchive uploads all uploadable artifacts to instance
 func runPipeForModeArchive(ctx *context.Context, instance config.Artifactory) error {
 	sem := make(chan bool, ctx.Parallelism)
 	var g errgroup.Group
 
 	// Get all artifacts and upload them
-	for _, artifact := range ctx.Artifacts {
+	// checksums, signatures and the provenance are not tied to a build, so
+	// they are always uploaded
+	for _, a := range ctx.Artifacts.Filter(
+		artifact.Or(
+			artifact.And(
+				artifact.Or(
+					artifact.ByType(artifact.UploadableArchive),
+					artifact.ByType(artifact.UploadableBinary),
+					artifact.ByType(artifact.LinuxPackage),
+					artifact.ByType(artifact.Snap),
+					artifact.ByType(artifact.SBOM),
+				),
+				artifact.ByIDs(instance.Builds...),
+			),
+			artifact.ByType(artifact.Checksum),
+			artifact.ByType(artifact.Signature),
+			artifact.ByType(artifact.Provenance),
+		),
+	).List() {
 		sem <- true
-		artifact := artifact
+		a := a
 		g.Go(func() error {
 			defer func() {
 				<-sem
 			}()
 
-			return uploadArchive(ctx, instance, artifact)
+			return uploadArchive(ctx, instance, a)
 		})
 	}
 
@@ -169,40 +201,47 @@ func runPipeForModeArchive(ctx *context.Context, instance config.Artifactory) er
 }
 
 // uploadArchive will upload artifact in mode archive
-func uploadArchive(ctx *context.Context, instance config.Artifactory, artifact string) error {
-	var path = filepath.Join(ctx.Config.Dist, artifact)
-	return uploadAssetAndLog(ctx, instance, path, nil)
+func uploadArchive(ctx *context.Context, instance config.Artifactory, a artifact.Artifact) error {
+	return uploadAssetAndLog(ctx, instance, a.Path, nil)
 }
 
-// uploadBinary will upload the current build and the current target in mode binary
+// uploadBinary will upload the binaries of the current build and the
+// current target in mode binary
 func uploadBinary(ctx *context.Context, instance config.Artifactory, build config.Build, target buildtarget.Target) error {
-	binary, err := getBinaryForUploadPerBuild(ctx, target)
-	if err != nil {
-		return err
+	var binaries = ctx.Artifacts.Filter(
+		artifact.And(
+			artifact.ByGoos(target.OS),
+			artifact.ByGoarch(target.Arch),
+			artifact.ByVariant(target.Variant()),
+			artifact.ByType(artifact.Binary),
+			artifact.ByBinary(build.Binary),
+		),
+	).List()
+	if len(binaries) == 0 {
+		return fmt.Errorf("binary for build target %s not found", target.String())
 	}
-
-	return uploadAssetAndLog(ctx, instance, binary.Path, &target)
+	for _, binary := range binaries {
+		binary := binary
+		if err := uploadAssetAndLog(ctx, instance, binary.Path, &binary); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // uploadAssetAndLog uploads file to target and logs all actions
-func uploadAssetAndLog(ctx *context.Context, instance config.Artifactory, path string, target *buildtarget.Target) error {
+func uploadAssetAndLog(ctx *context.Context, instance config.Artifactory, path string, binary *artifact.Artifact) error {
 	envName := fmt.Sprintf("ARTIFACTORY_%s_SECRET", strings.ToUpper(instance.Name))
 	secret := ctx.Env[envName]
 
 	// Generate the target url
-	targetURL, err := resolveTargetTemplate(ctx, instance, target)
+	targetURL, err := resolveTargetTemplate(ctx, instance, binary)
 	if err != nil {
 		msg := "artifactory: error while building the target url"
 		log.WithField("instance", instance.Name).WithError(err).Error(msg)
 		return errors.Wrap(err, msg)
 	}
 
-	// Handle the artifact
-	file, err := os.Open(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close() // nolint: errcheck
 	_, name := filepath.Split(path)
 
 	// The target url needs to contain the artifact name
@@ -211,6 +250,22 @@ func uploadAssetAndLog(ctx *context.Context, instance config.Artifactory, path s
 	}
 	targetURL += name
 
+	if ctx.DryRun {
+		log.WithFields(log.Fields{
+			"instance": instance.Name,
+			"file":     path,
+			"url":      targetURL,
+		}).Info("dry-run: would upload")
+		return nil
+	}
+
+	// Handle the artifact
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint: errcheck
+
 	artifact, _, err := uploadAssetToArtifactory(ctx, targetURL, instance.Username, secret, file)
 	if err != nil {
 		msg := "artifactory: upload failed"
@@ -226,10 +281,27 @@ func uploadAssetAndLog(ctx *context.Context, instance config.Artifactory, path s
 		"mode":     instance.Mode,
 		"uri":      artifact.DownloadURI,
 	}).Info("uploaded successful")
+	ctx.Undo("delete "+targetURL, func(ctx *context.Context) error {
+		return deleteFromArtifactory(ctx, targetURL, instance.Username, secret)
+	})
 
 	return nil
 }
 
+// selects returns true if the binaries of the given build should be
+// uploaded to the given instance
+func selects(instance config.Artifactory, build config.Build) bool {
+	if len(instance.Builds) == 0 {
+		return true
+	}
+	for _, id := range instance.Builds {
+		if id == build.ID {
+			return true
+		}
+	}
+	return false
+}
+
 // runPipeForModeBinary uploads all configured builds to instance
 func runPipeForModeBinary(ctx *context.Context, instance config.Artifactory, build config.Build) error {
 	sem := make(chan bool, ctx.Parallelism)
@@ -253,69 +325,16 @@ func runPipeForModeBinary(ctx *context.Context, instance config.Artifactory, bui
 	return g.Wait()
 }
 
-// getBinaryForUploadPerBuild determines the correct binary for the upload
-func getBinaryForUploadPerBuild(ctx *context.Context, target buildtarget.Target) (*context.Binary, error) {
-	var group = ctx.Binaries[target.String()]
-	if group == nil {
-		return nil, fmt.Errorf("binary for build target %s not found", target.String())
-	}
-
-	var binary context.Binary
-	for _, binaries := range group {
-		for _, b := range binaries {
-			binary = b
-			break
-		}
-		break
-	}
-
-	return &binary, nil
-}
-
 // targetData is used as a template struct for
-// Artifactory.Target
-type targetData struct {
-	Version     string
-	Tag         string
-	ProjectName string
-
-	// Only supported in mode binary
-	Os   string
-	Arch string
-	Arm  string
-}
-
 // resolveTargetTemplate returns the resolved target template with replaced variables
 // Those variables can be replaced by the given context, goos, goarch, goarm and more
-func resolveTargetTemplate(ctx *context.Context, artifactory config.Artifactory, target *buildtarget.Target) (string, error) {
-	data := targetData{
-		Version:     ctx.Version,
-		Tag:         ctx.Git.CurrentTag,
-		ProjectName: ctx.Config.ProjectName,
-	}
-
+func resolveTargetTemplate(ctx *context.Context, artifactory config.Artifactory, binary *artifact.Artifact) (string, error) {
+	var t = tmpl.New(ctx)
 	// Only supported in mode binary
-	if target != nil {
-		data.Os = replace(ctx.Config.Archive.Replacements, target.OS)
-		data.Arch = replace(ctx.Config.Archive.Replacements, target.Arch)
-		data.Arm = replace(ctx.Config.Archive.Replacements, target.Arm)
-	}
-
-	var out bytes.Buffer
-	t, err := template.New(ctx.Config.ProjectName).Parse(artifactory.Target)
-	if err != nil {
-		return "", err
-	}
-	err = t.Execute(&out, data)
-	return out.String(), err
-}
-
-func replace(replacements map[string]string, original string) string {
-	result := replacements[original]
-	if result == "" {
-		return original
+	if binary != nil {
+		t = t.WithArtifact(*binary, ctx.Config.ArchiveFor(binary.Extra["ID"]).Replacements)
 	}
-	return result
+	return t.Apply(artifactory.Target)
 }
 
 // uploadAssetToArtifactory uploads the asset file to target
@@ -328,17 +347,42 @@ func uploadAssetToArtifactory(ctx *context.Context, target, username, secret str
 		return nil, nil, errors.New("the asset to upload can't be a directory")
 	}
 
-	req, err := newUploadRequest(target, username, secret, file, stat.Size())
+	var policy = retry.New(ctx.Config.Retry)
+	var asset = new(artifactoryResponse)
+	var resp *http.Response
+	err = policy.Do(ctx, func() error {
+		// a failed attempt may have read part of the file already
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		// the http client closes the body, but the file may be needed for
+		// the next attempt
+		req, err := newUploadRequest(target, username, secret, ioutil.NopCloser(file), stat.Size())
+		if err != nil {
+			return err
+		}
+		resp, err = executeHTTPRequest(ctx, req, asset)
+		return policy.HTTP(resp, err)
+	})
 	if err != nil {
-		return nil, nil, err
+		return nil, resp, err
 	}
+	return asset, resp, nil
+}
 
-	asset := new(artifactoryResponse)
-	resp, err := executeHTTPRequest(ctx, req, asset)
+// deleteFromArtifactory deletes the artifact at target
+func deleteFromArtifactory(ctx *context.Context, target, username, secret string) error {
+	req, err := http.NewRequest("DELETE", target, nil)
 	if err != nil {
-		return nil, resp, err
+		return err
 	}
-	return asset, resp, nil
+	req.SetBasicAuth(username, secret)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	return checkResponse(resp)
 }
 
 // newUploadRequest creates a new http.Request for uploading
@@ -360,7 +404,7 @@ func newUploadRequest(target, username, secret string, reader io.Reader, size in
 
 // executeHTTPRequest processes the http call with respect of context ctx
 func executeHTTPRequest(ctx *context.Context, req *http.Request, v interface{}) (*http.Response, error) {
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
 	if err != nil {
 		// If we got an error, and the context has been canceled,
 		// the context's error is probably more useful.
@@ -14,7 +14,7 @@ import (
 	"github.com/goreleaser/goreleaser/checksum"
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
-	"github.com/goreleaser/goreleaser/internal/archiveformat"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/client"
 	"github.com/goreleaser/goreleaser/pipeline"
 )
@@ -23,7 +23,9 @@ import (
 // contain darwin and/or goarch doesn't contain amd64)
 var ErrNoDarwin64Build = errors.New("brew tap requires a darwin amd64 build")
 
-const platform = "darwinamd64"
+// ErrMultipleArchives when there is more than one darwin amd64 archive to
+// choose from
+var ErrMultipleArchives = errors.New("brew tap requires a single darwin amd64 archive, use brew.builds to choose one")
 
 // Pipe for brew deployment
 type Pipe struct{}
@@ -32,6 +34,16 @@ func (Pipe) String() string {
 	return "creating homebrew formula"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "brew"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"archive", "release"}
+}
+
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
 	client, err := client.NewGitHub(ctx)
@@ -94,32 +106,89 @@ func doRun(ctx *context.Context, client client.Client) error {
 	if ctx.Config.Release.Draft {
 		return pipeline.Skip("release is marked as draft")
 	}
-	if ctx.Config.Archive.Format == "binary" {
+	if ctx.PreRelease() {
+		return pipeline.Skip("release is marked as prerelease")
+	}
+	if onlyBinaries(ctx) {
 		return pipeline.Skip("archive format is binary")
 	}
-
-	var group = ctx.Binaries["darwinamd64"]
-	if group == nil {
+	if onlyWasm(ctx) {
+		return pipeline.Skip("archives are all wasm")
+	}
+
+	var archives = ctx.Artifacts.Filter(
+		artifact.And(
+			artifact.ByGoos("darwin"),
+			artifact.ByGoarch("amd64"),
+			artifact.ByGoarm(""),
+			artifact.ByVariantOrDefault(ctx.Config.Brew.Goamd64),
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByIDs(ctx.Config.Brew.Builds...),
+		),
+	).List()
+	if len(archives) == 0 {
 		return ErrNoDarwin64Build
 	}
-	var folder string
-	for f := range group {
-		folder = f
-		break
+	if len(archives) > 1 {
+		return ErrMultipleArchives
 	}
 	var path = filepath.Join(ctx.Config.Brew.Folder, ctx.Config.ProjectName+".rb")
+	if ctx.DryRun {
+		log.WithField("formula", path).
+			WithField("repo", ctx.Config.Brew.GitHub.String()).
+			WithField("archive", archives[0].Name).
+			Info("dry-run: would push")
+		return nil
+	}
 	log.WithField("formula", path).
 		WithField("repo", ctx.Config.Brew.GitHub.String()).
 		Info("pushing")
-	content, err := buildFormula(ctx, client, folder)
+	content, err := buildFormula(ctx, client, archives[0])
 	if err != nil {
 		return err
 	}
-	return client.CreateFile(ctx, content, path)
+	previous, exists, err := client.GetFile(ctx, path)
+	if err != nil {
+		return err
+	}
+	if err := client.CreateFile(ctx, content, path); err != nil {
+		return err
+	}
+	ctx.Undo("revert formula "+path, func(ctx *context.Context) error {
+		if !exists {
+			return client.DeleteFile(ctx, path)
+		}
+		return client.CreateFile(ctx, *bytes.NewBuffer(previous), path)
+	})
+	return nil
+}
+
+// onlyBinaries returns true if all archives have the binary format, in which
+// case there is no archive to install the formula from
+func onlyBinaries(ctx *context.Context) bool {
+	for _, archive := range ctx.Config.AllArchives() {
+		if archive.Format != "binary" {
+			return false
+		}
+	}
+	return true
+}
+
+// onlyWasm returns true if all the archives of the builds the formula is
+// made of are wasm ones, which can't be installed by brew
+func onlyWasm(ctx *context.Context) bool {
+	var archives = ctx.Artifacts.Filter(
+		artifact.And(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByIDs(ctx.Config.Brew.Builds...),
+		),
+	)
+	var count = len(archives.List())
+	return count > 0 && len(archives.Filter(artifact.ByGoarch("wasm")).List()) == count
 }
 
-func buildFormula(ctx *context.Context, client client.Client, folder string) (bytes.Buffer, error) {
-	data, err := dataFor(ctx, client, folder)
+func buildFormula(ctx *context.Context, client client.Client, archive artifact.Artifact) (bytes.Buffer, error) {
+	data, err := dataFor(ctx, client, archive)
 	if err != nil {
 		return bytes.Buffer{}, err
 	}
@@ -135,9 +204,8 @@ func doBuildFormula(data templateData) (out bytes.Buffer, err error) {
 	return
 }
 
-func dataFor(ctx *context.Context, client client.Client, folder string) (result templateData, err error) {
-	var file = folder + "." + archiveformat.For(ctx, platform)
-	sum, err := checksum.SHA256(filepath.Join(ctx.Config.Dist, file))
+func dataFor(ctx *context.Context, client client.Client, archive artifact.Artifact) (result templateData, err error) {
+	sum, err := checksum.SHA256(archive.Path)
 	if err != nil {
 		return
 	}
@@ -154,7 +222,7 @@ func dataFor(ctx *context.Context, client client.Client, folder string) (result
 		Tag:          ctx.Git.CurrentTag,
 		Version:      ctx.Version,
 		Caveats:      ctx.Config.Brew.Caveats,
-		File:         file,
+		File:         archive.Name,
 		SHA256:       sum,
 		Dependencies: ctx.Config.Brew.Dependencies,
 		Conflicts:    ctx.Config.Brew.Conflicts,
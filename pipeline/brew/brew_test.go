@@ -9,6 +9,9 @@ import (
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
+	"github.com/goreleaser/goreleaser/internal/semver"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/stretchr/testify/assert"
 )
@@ -124,7 +127,13 @@ func TestRunPipe(t *testing.T) {
 		Publish: true,
 	}
 	var path = filepath.Join(folder, "bin.tar.gz")
-	ctx.AddBinary("darwinamd64", "bin", "bin", path)
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name:   "bin.tar.gz",
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+	})
 	client := &DummyClient{}
 	assert.Error(t, doRun(ctx, client))
 	assert.False(t, client.CreatedFile)
@@ -168,13 +177,151 @@ func TestRunPipeFormatOverride(t *testing.T) {
 		},
 		Publish: true,
 	}
-	ctx.AddBinary("darwinamd64", "bin", "bin", path)
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name:   "bin.zip",
+		Path:   path,
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Type:   artifact.UploadableArchive,
+	})
 	client := &DummyClient{}
 	assert.NoError(t, doRun(ctx, client))
 	assert.True(t, client.CreatedFile)
 	assert.Contains(t, client.Content, "bin.zip")
 }
 
+func TestRunPipeMultipleArchives(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var ctx = &context.Context{
+		Config: config.Project{
+			Dist: folder,
+			Archives: []config.Archive{
+				{Format: "tar.gz", Builds: []string{"server"}},
+				{Format: "tar.gz", Builds: []string{"client"}},
+			},
+			Brew: config.Homebrew{
+				GitHub: config.Repo{
+					Owner: "test",
+					Name:  "test",
+				},
+			},
+		},
+		Publish: true,
+	}
+	for _, name := range []string{"server", "client"} {
+		var path = filepath.Join(folder, name+".tar.gz")
+		_, err = os.Create(path)
+		assert.NoError(t, err)
+		ctx.Artifacts.Add(artifact.Artifact{
+			Name:   name + ".tar.gz",
+			Path:   path,
+			Goos:   "darwin",
+			Goarch: "amd64",
+			Type:   artifact.UploadableArchive,
+			Extra:  map[string]string{"ID": name},
+		})
+	}
+	assert.Equal(t, ErrMultipleArchives, doRun(ctx, &DummyClient{}))
+
+	ctx.Config.Brew.Builds = []string{"client"}
+	client := &DummyClient{}
+	assert.NoError(t, doRun(ctx, client))
+	assert.True(t, client.CreatedFile)
+	assert.Contains(t, client.Content, "client.tar.gz")
+}
+
+func TestRunPipeVariants(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var ctx = &context.Context{
+		Config: config.Project{
+			Dist: folder,
+			Archive: config.Archive{
+				Format: "tar.gz",
+			},
+			Brew: config.Homebrew{
+				GitHub: config.Repo{
+					Owner: "test",
+					Name:  "test",
+				},
+			},
+		},
+		Publish: true,
+	}
+	for _, variant := range []string{"v1", "v3"} {
+		var name = "bin_" + variant + ".tar.gz"
+		var path = filepath.Join(folder, name)
+		_, err = os.Create(path)
+		assert.NoError(t, err)
+		ctx.Artifacts.Add(artifact.Artifact{
+			Name:    name,
+			Path:    path,
+			Goos:    "darwin",
+			Goarch:  "amd64",
+			Goamd64: variant,
+			Type:    artifact.UploadableArchive,
+		})
+	}
+	client := &DummyClient{}
+	assert.NoError(t, doRun(ctx, client))
+	assert.Contains(t, client.Content, "bin_v1.tar.gz")
+
+	ctx.Config.Brew.Goamd64 = "v3"
+	client = &DummyClient{}
+	assert.NoError(t, doRun(ctx, client))
+	assert.Contains(t, client.Content, "bin_v3.tar.gz")
+
+	ctx.Config.Brew.Goamd64 = "v2"
+	assert.Equal(t, ErrNoDarwin64Build, doRun(ctx, &DummyClient{}))
+}
+
+func TestRunPipeUndo(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var path = filepath.Join(folder, "bin.tar.gz")
+	_, err = os.Create(path)
+	assert.NoError(t, err)
+	for name, existing := range map[string]string{
+		"new":      "",
+		"existing": "class Bin < Formula\nend\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var ctx = context.New(config.Project{
+				Dist:        folder,
+				ProjectName: "bin",
+				Archive: config.Archive{
+					Format: "tar.gz",
+				},
+				Brew: config.Homebrew{
+					GitHub: config.Repo{
+						Owner: "test",
+						Name:  "test",
+					},
+				},
+			})
+			ctx.Publish = true
+			ctx.Artifacts.Add(artifact.Artifact{
+				Name:   "bin.tar.gz",
+				Path:   path,
+				Goos:   "darwin",
+				Goarch: "amd64",
+				Type:   artifact.UploadableArchive,
+			})
+			client := &DummyClient{ExistingFile: existing}
+			assert.NoError(t, doRun(ctx, client))
+			var undo = ctx.UndoActions()
+			assert.Len(t, undo, 1)
+			assert.Equal(t, "revert formula bin.rb", undo[0].Description)
+			assert.NoError(t, undo[0].Func(ctx))
+			assert.Equal(t, existing == "", client.DeletedFile)
+			if existing != "" {
+				assert.Equal(t, existing, client.Content)
+			}
+		})
+	}
+}
+
 func TestRunPipeNoDarwin64Build(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
@@ -220,7 +367,40 @@ func TestRunPipeBinaryRelease(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddBinary("darwinamd64", "foo", "bar", "baz")
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name:   "bin",
+		Path:   "doesnt matter",
+		Goos:   "darwin",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+	})
+	client := &DummyClient{}
+	testlib.AssertSkipped(t, doRun(ctx, client))
+	assert.False(t, client.CreatedFile)
+}
+
+func TestRunPipeOnlyWasm(t *testing.T) {
+	var ctx = &context.Context{
+		Publish: true,
+		Config: config.Project{
+			Archive: config.Archive{
+				Format: "tar.gz",
+			},
+			Brew: config.Homebrew{
+				GitHub: config.Repo{
+					Owner: "test",
+					Name:  "test",
+				},
+			},
+		},
+	}
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name:   "bin_js_wasm.tar.gz",
+		Path:   "doesnt matter",
+		Goos:   "js",
+		Goarch: "wasm",
+		Type:   artifact.UploadableArchive,
+	})
 	client := &DummyClient{}
 	testlib.AssertSkipped(t, doRun(ctx, client))
 	assert.False(t, client.CreatedFile)
@@ -255,6 +435,25 @@ func TestRunPipeDraftRelease(t *testing.T) {
 	assert.False(t, client.CreatedFile)
 }
 
+func TestRunPipePrerelease(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			Prerelease: "auto",
+		},
+		Brew: config.Homebrew{
+			GitHub: config.Repo{
+				Owner: "test",
+				Name:  "test",
+			},
+		},
+	})
+	ctx.Publish = true
+	ctx.Semver = semver.Version{Major: 1, Prerelease: "rc.1"}
+	client := &DummyClient{}
+	testlib.AssertSkipped(t, doRun(ctx, client))
+	assert.False(t, client.CreatedFile)
+}
+
 func TestRunPipeFormatBinary(t *testing.T) {
 	var ctx = &context.Context{
 		Config: config.Project{
@@ -303,21 +502,48 @@ func TestDefault(t *testing.T) {
 }
 
 type DummyClient struct {
-	CreatedFile bool
-	Content     string
+	CreatedFile  bool
+	DeletedFile  bool
+	Content      string
+	ExistingFile string
 }
 
-func (client *DummyClient) CreateRelease(ctx *context.Context, body string) (releaseID int, err error) {
-	return
+func (c *DummyClient) CreateRelease(ctx *context.Context, body string) (client.Release, error) {
+	return client.Release{}, nil
 }
 
-func (client *DummyClient) CreateFile(ctx *context.Context, content bytes.Buffer, path string) (err error) {
-	client.CreatedFile = true
+func (c *DummyClient) PublishRelease(ctx *context.Context, releaseID int) error {
+	return nil
+}
+
+func (c *DummyClient) DeleteRelease(ctx *context.Context, releaseID int) error {
+	return nil
+}
+
+func (c *DummyClient) GetFile(ctx *context.Context, path string) ([]byte, bool, error) {
+	return []byte(c.ExistingFile), c.ExistingFile != "", nil
+}
+
+func (c *DummyClient) CreateFile(ctx *context.Context, content bytes.Buffer, path string) error {
+	c.CreatedFile = true
 	bts, _ := ioutil.ReadAll(&content)
-	client.Content = string(bts)
-	return
+	c.Content = string(bts)
+	return nil
+}
+
+func (c *DummyClient) DeleteFile(ctx *context.Context, path string) error {
+	c.DeletedFile = true
+	return nil
+}
+
+func (c *DummyClient) Upload(ctx *context.Context, releaseID int, name string, file *os.File) (int, error) {
+	return 0, nil
+}
+
+func (c *DummyClient) ListAssets(ctx *context.Context, releaseID int) ([]client.Asset, error) {
+	return nil, nil
 }
 
-func (client *DummyClient) Upload(ctx *context.Context, releaseID int, name string, file *os.File) (err error) {
-	return
+func (c *DummyClient) DeleteAsset(ctx *context.Context, assetID int) error {
+	return nil
 }
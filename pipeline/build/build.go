@@ -2,15 +2,18 @@ package build
 
 import (
 	"os"
-	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/buildtarget"
 	"github.com/goreleaser/goreleaser/internal/ext"
+	"github.com/goreleaser/goreleaser/internal/nametemplate"
+	"github.com/goreleaser/goreleaser/internal/runner"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
@@ -22,6 +25,11 @@ func (Pipe) String() string {
 	return "building binaries"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "build"
+}
+
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
 	for _, build := range ctx.Config.Builds {
@@ -53,6 +61,9 @@ func buildWithDefaults(ctx *context.Context, build config.Build) config.Build {
 	if build.Binary == "" {
 		build.Binary = ctx.Config.Release.GitHub.Name
 	}
+	if build.ID == "" {
+		build.ID = build.Binary
+	}
 	if build.Main == "" {
 		build.Main = "."
 	}
@@ -72,7 +83,7 @@ func buildWithDefaults(ctx *context.Context, build config.Build) config.Build {
 }
 
 func runPipeOnBuild(ctx *context.Context, build config.Build) error {
-	if err := runHook(build.Env, build.Hooks.Pre); err != nil {
+	if err := runHook(ctx, build.Env, build.Hooks.Pre); err != nil {
 		return errors.Wrap(err, "pre hook failed")
 	}
 	sem := make(chan bool, ctx.Parallelism)
@@ -91,61 +102,155 @@ func runPipeOnBuild(ctx *context.Context, build config.Build) error {
 	if err := g.Wait(); err != nil {
 		return err
 	}
-	return errors.Wrap(runHook(build.Env, build.Hooks.Post), "post hook failed")
+	return errors.Wrap(runHook(ctx, build.Env, build.Hooks.Post), "post hook failed")
 }
 
-func runHook(env []string, hook string) error {
+func runHook(ctx *context.Context, env []string, hook string) error {
 	if hook == "" {
 		return nil
 	}
 	log.WithField("hook", hook).Info("running hook")
 	cmd := strings.Fields(hook)
-	return run(buildtarget.Runtime, cmd, env)
+	if ctx.Config.Reproducible {
+		env = append([]string{ctx.SourceDateEpoch()}, env...)
+	}
+	return run(ctx, buildtarget.Runtime, cmd, env)
 }
 
 func doBuild(ctx *context.Context, build config.Build, target buildtarget.Target) error {
-	var binaryName = build.Binary + ext.For(target)
-	var prettyName = binaryName
-	if ctx.Config.Archive.Format == "binary" {
+	build = withOverrides(build, target)
+	var extension = ext.For(target)
+	var binary = artifact.Artifact{
+		Type:   artifact.Binary,
+		Name:   build.Binary + extension,
+		Goos:   target.OS,
+		Goarch: target.Arch,
+		Goarm:  target.Arm,
+		// the variants of the other archs
+		Goamd64: target.Amd64,
+		Go386:   target.I386,
+		Gomips:  target.Mips,
+		Goppc64: target.Ppc64,
+		Extra: map[string]string{
+			"Binary": build.Binary,
+			"ID":     build.ID,
+			"Ext":    extension,
+		},
+	}
+	// the name template of the archive is also used for the build folder
+	var archive = ctx.Config.ArchiveFor(build.ID)
+	var binaryName = binary.Name
+	if archive.Format == "binary" {
 		var err error
-		binaryName, err = nameFor(ctx, target, build.Binary)
+		binaryName, err = nametemplate.Apply(ctx, archive, binary, build.Binary)
 		if err != nil {
 			return err
 		}
-		binaryName = binaryName + ext.For(target)
+		binaryName = binaryName + extension
 	}
-	folder, err := nameFor(ctx, target, ctx.Config.ProjectName)
+	folder, err := nametemplate.Apply(ctx, archive, binary, ctx.Config.ProjectName)
 	if err != nil {
 		return err
 	}
-	var binary = filepath.Join(ctx.Config.Dist, folder, binaryName)
-	ctx.AddBinary(target.String(), folder, prettyName, binary)
-	log.WithField("binary", binary).Info("building")
+	binary.Path = filepath.Join(ctx.Config.Dist, folder, binaryName)
+	log.WithField("binary", binary.Path).Info("building")
 	cmd := []string{"go", "build"}
 	if build.Flags != "" {
 		cmd = append(cmd, strings.Fields(build.Flags)...)
 	}
+	// removes the local file system paths from the binary
+	if ctx.Config.Reproducible && !strings.Contains(build.Flags, "-trimpath") {
+		cmd = append(cmd, "-trimpath")
+	}
+	if len(build.Tags) > 0 {
+		cmd = append(cmd, "-tags", strings.Join(build.Tags, " "))
+	}
+	if build.Gcflags != "" {
+		cmd = append(cmd, "-gcflags="+build.Gcflags)
+	}
+	if build.Asmflags != "" {
+		cmd = append(cmd, "-asmflags="+build.Asmflags)
+	}
 	flags, err := ldflags(ctx, build)
 	if err != nil {
 		return err
 	}
-	cmd = append(cmd, "-ldflags="+flags, "-o", binary, build.Main)
-	return errors.Wrapf(run(target, cmd, build.Env), "failed to build for %s", target)
+	redactedFlags, err := redactedLdflags(ctx, build)
+	if err != nil {
+		return err
+	}
+	var output = []string{"-o", binary.Path, build.Main}
+	// recorded for the provenance of the release, as resolved, but with the
+	// values that may be secrets redacted
+	binary.Extra["Cmd"] = shellJoin(append(append(cmd[:len(cmd):len(cmd)], "-ldflags="+redactedFlags), output...))
+	binary.Extra["Env"] = shellJoin(append(redactEnv(build.Env), target.Env()...))
+	cmd = append(append(cmd, "-ldflags="+flags), output...)
+	if err := run(ctx, target, cmd, build.Env); err != nil {
+		return errors.Wrapf(err, "failed to build for %s", target)
+	}
+	if !ctx.DryRun && built(binary.Path) {
+		if err := verify(build, flags, target, &binary); err != nil {
+			return errors.Wrapf(err, "failed to verify the build for %s", target)
+		}
+	}
+	ctx.Artifacts.Add(binary)
+	return nil
+}
+
+// redactEnv redacts the values of the given KEY=value variables, except the
+// ones of the go toolchain, e.g. CGO_ENABLED or GOFLAGS, which tell how the
+// binary was built
+func redactEnv(env []string) []string {
+	var result = make([]string, 0, len(env))
+	for _, e := range env {
+		var name = strings.SplitN(e, "=", 2)[0]
+		if strings.HasPrefix(name, "GO") || strings.HasPrefix(name, "CGO_") {
+			result = append(result, e)
+			continue
+		}
+		result = append(result, name+"="+redacted)
+	}
+	return result
+}
+
+// built returns false if go build wrote no binary, e.g. because of the -n
+// flag, in which case there's nothing to verify
+func built(path string) bool {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		log.WithField("binary", path).Warn("no binary was written, skipping verification")
+		return false
+	}
+	return true
 }
 
-func run(target buildtarget.Target, command, env []string) error {
-	/* #nosec */
-	var cmd = exec.Command(command[0], command[1:]...)
+func run(ctx *context.Context, target buildtarget.Target, command, env []string) error {
 	env = append(env, target.Env()...)
 	var log = log.WithField("target", target.PrettyString()).
 		WithField("env", env).
 		WithField("cmd", command)
-	cmd.Env = append(cmd.Env, os.Environ()...)
-	cmd.Env = append(cmd.Env, env...)
 	log.Debug("running")
-	if out, err := cmd.CombinedOutput(); err != nil {
+	if out, err := runner.Run(ctx, runner.Cmd{
+		Args: command,
+		Env:  env,
+	}); err != nil {
 		log.WithError(err).Debug("failed")
 		return errors.New(string(out))
 	}
 	return nil
 }
+
+var shellSafe = regexp.MustCompile(`^[a-zA-Z0-9_@%+=:,./-]+$`)
+
+// shellJoin joins the given arguments in a command line that can be pasted
+// in a shell, quoting the ones that need it
+func shellJoin(args []string) string {
+	var quoted = make([]string, len(args))
+	for i, arg := range args {
+		if shellSafe.MatchString(arg) {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -21,11 +21,17 @@ func TestPipeDescription(t *testing.T) {
 }
 
 func TestRun(t *testing.T) {
-	assert.NoError(t, run(buildtarget.Runtime, []string{"go", "list", "./..."}, emptyEnv))
+	assert.NoError(t, run(context.New(config.Project{}), buildtarget.Runtime, []string{"go", "list", "./..."}, emptyEnv))
 }
 
 func TestRunInvalidCommand(t *testing.T) {
-	assert.Error(t, run(buildtarget.Runtime, []string{"gggggo", "nope"}, emptyEnv))
+	assert.Error(t, run(context.New(config.Project{}), buildtarget.Runtime, []string{"gggggo", "nope"}, emptyEnv))
+}
+
+func TestRunDryRun(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.DryRun = true
+	assert.NoError(t, run(ctx, buildtarget.Runtime, []string{"gggggo", "nope"}, emptyEnv))
 }
 
 func TestBuild(t *testing.T) {
@@ -40,6 +46,10 @@ func TestBuild(t *testing.T) {
 	}
 	var ctx = context.New(config)
 	assert.NoError(t, doBuild(ctx, ctx.Config.Builds[0], buildtarget.Runtime))
+	// -n doesn't write the binary, so it isn't verified
+	var binaries = ctx.Artifacts.List()
+	assert.Len(t, binaries, 1)
+	assert.Empty(t, binaries[0].Extra["Format"])
 }
 
 func TestRunFullPipe(t *testing.T) {
@@ -110,6 +120,37 @@ func TestRunPipeFormatBinary(t *testing.T) {
 	assert.True(t, exists(binary))
 }
 
+func TestRunPipeWithOverrides(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	// the build only compiles with the tag set by the override
+	assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "main.go"),
+		[]byte("package main\nfunc main() {println(name)}"),
+		0644,
+	))
+	assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "name.go"),
+		[]byte("// +build special\n\npackage main\nconst name = \"special\""),
+		0644,
+	))
+	var config = config.Project{
+		Dist: folder,
+		Builds: []config.Build{
+			{
+				Binary: "testing",
+				Goos:   []string{runtime.GOOS},
+				Goarch: []string{runtime.GOARCH},
+				Overrides: []config.BuildOverride{
+					{Goos: runtime.GOOS, Tags: []string{"special"}},
+				},
+			},
+		},
+	}
+	assert.NoError(t, Pipe{}.Run(context.New(config)))
+	assert.True(t, exists(filepath.Join(folder, "testing")))
+}
+
 func TestRunPipeArmBuilds(t *testing.T) {
 	folder, back := testlib.Mktmp(t)
 	defer back()
@@ -183,9 +224,9 @@ func TestRunInvalidNametemplate(t *testing.T) {
 	defer back()
 	writeGoodMain(t, folder)
 	for format, msg := range map[string]string{
-		"binary": `template: bar:1: unexpected "}" in operand`,
-		"tar.gz": `template: foo:1: unexpected "}" in operand`,
-		"zip":    `template: foo:1: unexpected "}" in operand`,
+		"binary": `template: tmpl:1: unexpected "}" in operand`,
+		"tar.gz": `template: tmpl:1: unexpected "}" in operand`,
+		"zip":    `template: tmpl:1: unexpected "}" in operand`,
 	} {
 		t.Run(format, func(t *testing.T) {
 			var config = config.Project{
@@ -231,7 +272,132 @@ func TestRunInvalidLdflags(t *testing.T) {
 			},
 		},
 	}
-	assert.EqualError(t, Pipe{}.Run(context.New(config)), `template: ldflags:1: unexpected "}" in operand`)
+	assert.EqualError(t, Pipe{}.Run(context.New(config)), `template: tmpl:1: unexpected "}" in operand`)
+}
+
+func TestRunFullPipeDryRun(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	var binary = filepath.Join(folder, "testing")
+	var pre = filepath.Join(folder, "pre")
+	var config = config.Project{
+		Builds: []config.Build{
+			{
+				Binary: "testing",
+				Hooks: config.Hooks{
+					Pre: "touch " + pre,
+				},
+				Goos: []string{
+					runtime.GOOS,
+				},
+				Goarch: []string{
+					runtime.GOARCH,
+				},
+			},
+		},
+	}
+	var ctx = context.New(config)
+	ctx.DryRun = true
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.False(t, exists(binary), binary)
+	assert.False(t, exists(pre), pre)
+	assert.Len(t, ctx.Artifacts.List(), 1)
+}
+
+func TestRunRecordsCommand(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	var ctx = context.New(config.Project{
+		Dist: "dist",
+		Builds: []config.Build{
+			{
+				Binary:  "testing",
+				Main:    ".",
+				Ldflags: "-s -w",
+				Env:     []string{"CGO_ENABLED=0"},
+				Goos:    []string{"linux"},
+				Goarch:  []string{"amd64"},
+			},
+		},
+	})
+	ctx.DryRun = true
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var binaries = ctx.Artifacts.List()
+	assert.Len(t, binaries, 1)
+	assert.Equal(
+		t,
+		"go build '-ldflags=-s -w' -o "+binaries[0].Path+" .",
+		binaries[0].Extra["Cmd"],
+	)
+	assert.Equal(t, "CGO_ENABLED=0 GOOS=linux GOARCH=amd64 GOARM=", binaries[0].Extra["Env"])
+}
+
+func TestRunRecordsNoSecrets(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	assert.NoError(t, os.Setenv("API_KEY", "s3cr3t"))
+	defer os.Unsetenv("API_KEY") // nolint: errcheck
+	var ctx = context.New(config.Project{
+		Dist: "dist",
+		Builds: []config.Build{
+			{
+				Binary:  "testing",
+				Main:    ".",
+				Ldflags: `-s -X main.tag={{ .Tag }} -X main.key={{ .Env.API_KEY }} -X main.secret={{ env "API_KEY" }}`,
+				Env:     []string{"API_KEY=s3cr3t", "CGO_ENABLED=0", "GOFLAGS=-mod=mod"},
+				Goos:    []string{"linux"},
+				Goarch:  []string{"amd64"},
+			},
+		},
+	})
+	ctx.Git.CurrentTag = "v1.0.0"
+	ctx.DryRun = true
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var binaries = ctx.Artifacts.List()
+	assert.Len(t, binaries, 1)
+	assert.Equal(
+		t,
+		"go build '-ldflags=-s -X main.tag=v1.0.0 -X main.key=REDACTED -X main.secret=REDACTED' -o "+binaries[0].Path+" .",
+		binaries[0].Extra["Cmd"],
+	)
+	assert.Equal(t, "API_KEY=REDACTED CGO_ENABLED=0 GOFLAGS=-mod=mod GOOS=linux GOARCH=amd64 GOARM=", binaries[0].Extra["Env"])
+}
+
+func TestRunReproducible(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	var ctx = context.New(config.Project{
+		Dist:         "dist",
+		Reproducible: true,
+		Builds: []config.Build{
+			{
+				Binary:  "testing",
+				Main:    ".",
+				Ldflags: "-s -w",
+				Goos:    []string{"linux"},
+				Goarch:  []string{"amd64"},
+			},
+		},
+	})
+	ctx.DryRun = true
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var binaries = ctx.Artifacts.List()
+	assert.Len(t, binaries, 1)
+	assert.Equal(
+		t,
+		"go build -trimpath '-ldflags=-s -w' -o "+binaries[0].Path+" .",
+		binaries[0].Extra["Cmd"],
+	)
+}
+
+func TestShellJoin(t *testing.T) {
+	assert.Equal(t, "go build", shellJoin([]string{"go", "build"}))
+	assert.Equal(t, "-tags 'a b' ''", shellJoin([]string{"-tags", "a b", ""}))
+	assert.Equal(t, `'it'\''s'`, shellJoin([]string{"it's"}))
 }
 
 func TestRunPipeFailingHooks(t *testing.T) {
@@ -341,28 +507,25 @@ func TestRunPipeWithMainFuncNotInMainGoFile(t *testing.T) {
 }
 
 func TestDefaultNoBuilds(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{},
-	}
+	var ctx = context.New(config.Project{})
 	assert.NoError(t, Pipe{}.Default(ctx))
 }
 
 func TestDefaultEmptyBuild(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{
-			Release: config.Release{
-				GitHub: config.Repo{
-					Name: "foo",
-				},
-			},
-			Builds: []config.Build{
-				{},
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{
+				Name: "foo",
 			},
 		},
-	}
+		Builds: []config.Build{
+			{},
+		},
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	var build = ctx.Config.Builds[0]
 	assert.Equal(t, ctx.Config.Release.GitHub.Name, build.Binary)
+	assert.Equal(t, ctx.Config.Release.GitHub.Name, build.ID)
 	assert.Equal(t, ".", build.Main)
 	assert.Equal(t, []string{"linux", "darwin"}, build.Goos)
 	assert.Equal(t, []string{"amd64", "386"}, build.Goarch)
@@ -371,26 +534,26 @@ func TestDefaultEmptyBuild(t *testing.T) {
 }
 
 func TestDefaultPartialBuilds(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{
-			Builds: []config.Build{
-				{
-					Binary: "bar",
-					Goos:   []string{"linux"},
-					Main:   "./cmd/main.go",
-				},
-				{
-					Binary:  "foo",
-					Ldflags: "-s -w",
-					Goarch:  []string{"386"},
-				},
+	var ctx = context.New(config.Project{
+		Builds: []config.Build{
+			{
+				Binary: "bar",
+				Goos:   []string{"linux"},
+				Main:   "./cmd/main.go",
+			},
+			{
+				ID:      "foo-386",
+				Binary:  "foo",
+				Ldflags: "-s -w",
+				Goarch:  []string{"386"},
 			},
 		},
-	}
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	t.Run("build0", func(t *testing.T) {
 		var build = ctx.Config.Builds[0]
 		assert.Equal(t, "bar", build.Binary)
+		assert.Equal(t, "bar", build.ID)
 		assert.Equal(t, "./cmd/main.go", build.Main)
 		assert.Equal(t, []string{"linux"}, build.Goos)
 		assert.Equal(t, []string{"amd64", "386"}, build.Goarch)
@@ -400,6 +563,7 @@ func TestDefaultPartialBuilds(t *testing.T) {
 	t.Run("build1", func(t *testing.T) {
 		var build = ctx.Config.Builds[1]
 		assert.Equal(t, "foo", build.Binary)
+		assert.Equal(t, "foo-386", build.ID)
 		assert.Equal(t, ".", build.Main)
 		assert.Equal(t, []string{"linux", "darwin"}, build.Goos)
 		assert.Equal(t, []string{"386"}, build.Goarch)
@@ -412,18 +576,16 @@ func TestDefaultFillSingleBuild(t *testing.T) {
 	_, back := testlib.Mktmp(t)
 	defer back()
 
-	var ctx = &context.Context{
-		Config: config.Project{
-			Release: config.Release{
-				GitHub: config.Repo{
-					Name: "foo",
-				},
-			},
-			SingleBuild: config.Build{
-				Main: "testreleaser",
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{
+				Name: "foo",
 			},
 		},
-	}
+		SingleBuild: config.Build{
+			Main: "testreleaser",
+		},
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Len(t, ctx.Config.Builds, 1)
 	assert.Equal(t, ctx.Config.Builds[0].Binary, "foo")
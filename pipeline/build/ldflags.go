@@ -1,35 +1,26 @@
 package build
 
 import (
-	"bytes"
-	"text/template"
-	"time"
-
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 )
 
-type ldflagsData struct {
-	Date    string
-	Tag     string
-	Commit  string
-	Version string
-	Env     map[string]string
-}
+// redacted replaces the values that may be secrets in the recorded command
+// and env of the binaries
+const redacted = "REDACTED"
 
+// ldflags applies the build ldflags template
 func ldflags(ctx *context.Context, build config.Build) (string, error) {
-	var data = ldflagsData{
-		Commit:  ctx.Git.Commit,
-		Tag:     ctx.Git.CurrentTag,
-		Version: ctx.Version,
-		Date:    time.Now().UTC().Format(time.RFC3339),
-		Env:     ctx.Env,
-	}
-	var out bytes.Buffer
-	t, err := template.New("ldflags").Parse(build.Ldflags)
-	if err != nil {
-		return "", err
+	return tmpl.New(ctx).Apply(build.Ldflags)
+}
+
+// redactedLdflags applies the build ldflags template with the values of the
+// environment variables redacted, so it can be recorded
+func redactedLdflags(ctx *context.Context, build config.Build) (string, error) {
+	var env = map[string]string{}
+	for k := range ctx.Env {
+		env[k] = redacted
 	}
-	err = t.Execute(&out, data)
-	return out.String(), err
+	return tmpl.New(ctx).WithEnv(env).Apply(build.Ldflags)
 }
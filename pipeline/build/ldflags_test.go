@@ -2,6 +2,7 @@ package build
 
 import (
 	"testing"
+	"time"
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
@@ -35,6 +36,27 @@ func TestLdFlagsFullTemplate(t *testing.T) {
 	assert.Contains(t, flags, `-X "main.foo=123"`)
 }
 
+func TestLdFlagsCommitDate(t *testing.T) {
+	var ctx = context.New(config.Project{Reproducible: true})
+	ctx.Git.CommitDate = time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	flags, err := ldflags(ctx, config.Build{
+		Ldflags: `-X main.date={{.Date}} -X main.ts={{.Timestamp}} -X main.day={{ time "2006-01-02" }}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "-X main.date=2018-01-02T03:04:05Z -X main.ts=1514862245 -X main.day=2018-01-02", flags)
+}
+
+func TestLdFlagsReleaseDate(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	ctx.Date = time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	ctx.Git.CommitDate = time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	flags, err := ldflags(ctx, config.Build{
+		Ldflags: "-X main.date={{.Date}} -X main.ts={{.Timestamp}}",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "-X main.date=2018-01-02T03:04:05Z -X main.ts=1514862245", flags)
+}
+
 func TestInvalidTemplate(t *testing.T) {
 	var config = config.Project{
 		Builds: []config.Build{
@@ -0,0 +1,42 @@
+package build
+
+import (
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/internal/buildtarget"
+)
+
+// withOverrides returns the build with the overrides matching the given
+// target applied, in order. The flags replace the ones of the build, while
+// the env and tags are added to them.
+func withOverrides(build config.Build, target buildtarget.Target) config.Build {
+	// copies the lists, so appending to them doesn't change the ones other
+	// targets of the same build see
+	build.Env = append([]string{}, build.Env...)
+	build.Tags = append([]string{}, build.Tags...)
+	for _, override := range build.Overrides {
+		if !matches(override, target) {
+			continue
+		}
+		if override.Flags != "" {
+			build.Flags = override.Flags
+		}
+		if override.Ldflags != "" {
+			build.Ldflags = override.Ldflags
+		}
+		if override.Gcflags != "" {
+			build.Gcflags = override.Gcflags
+		}
+		if override.Asmflags != "" {
+			build.Asmflags = override.Asmflags
+		}
+		build.Env = append(build.Env, override.Env...)
+		build.Tags = append(build.Tags, override.Tags...)
+	}
+	return build
+}
+
+func matches(override config.BuildOverride, target buildtarget.Target) bool {
+	return (override.Goos == "" || override.Goos == target.OS) &&
+		(override.Goarch == "" || override.Goarch == target.Arch) &&
+		(override.Goarm == "" || override.Goarm == target.Arm)
+}
@@ -0,0 +1,89 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/internal/buildtarget"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithOverrides(t *testing.T) {
+	var build = config.Build{
+		Flags:   "-v",
+		Ldflags: "-s -w",
+		Env:     []string{"CGO_ENABLED=1"},
+		Tags:    []string{"netgo"},
+		Overrides: []config.BuildOverride{
+			{
+				Goos:    "linux",
+				Ldflags: "-s -w -extldflags -static",
+				Env:     []string{"CGO_ENABLED=0"},
+			},
+			{
+				Goos:    "linux",
+				Goarch:  "arm",
+				Goarm:   "7",
+				Gcflags: "all=-N -l",
+				Tags:    []string{"arm7"},
+			},
+			{
+				Goarch:   "amd64",
+				Flags:    "-a",
+				Asmflags: "all=-trimpath",
+			},
+		},
+	}
+	for name, tt := range map[string]struct {
+		target   buildtarget.Target
+		expected config.Build
+	}{
+		"no match": {
+			target: buildtarget.New("windows", "386", ""),
+			expected: config.Build{
+				Flags:   "-v",
+				Ldflags: "-s -w",
+				Env:     []string{"CGO_ENABLED=1"},
+				Tags:    []string{"netgo"},
+			},
+		},
+		"one match": {
+			target: buildtarget.New("linux", "arm", "6"),
+			expected: config.Build{
+				Flags:   "-v",
+				Ldflags: "-s -w -extldflags -static",
+				Env:     []string{"CGO_ENABLED=1", "CGO_ENABLED=0"},
+				Tags:    []string{"netgo"},
+			},
+		},
+		"two matches": {
+			target: buildtarget.New("linux", "arm", "7"),
+			expected: config.Build{
+				Flags:   "-v",
+				Ldflags: "-s -w -extldflags -static",
+				Gcflags: "all=-N -l",
+				Env:     []string{"CGO_ENABLED=1", "CGO_ENABLED=0"},
+				Tags:    []string{"netgo", "arm7"},
+			},
+		},
+		"any os": {
+			target: buildtarget.New("darwin", "amd64", ""),
+			expected: config.Build{
+				Flags:    "-a",
+				Ldflags:  "-s -w",
+				Asmflags: "all=-trimpath",
+				Env:      []string{"CGO_ENABLED=1"},
+				Tags:     []string{"netgo"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var result = withOverrides(build, tt.target)
+			result.Overrides = nil
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+	// the build itself is left untouched
+	assert.Equal(t, []string{"CGO_ENABLED=1"}, build.Env)
+	assert.Equal(t, []string{"netgo"}, build.Tags)
+}
@@ -0,0 +1,88 @@
+package build
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/binfmt"
+	"github.com/goreleaser/goreleaser/internal/buildtarget"
+)
+
+// verify checks the built binary is an executable of the given target,
+// statically linked on linux if cgo is disabled and stripped as the
+// ldflags say. What it finds is recorded in the extra fields of the
+// binary.
+func verify(build config.Build, ldflags string, target buildtarget.Target, binary *artifact.Artifact) error {
+	var format = formatOf(target.OS)
+	if format == "" {
+		// e.g. plan9, whose executables we don't read
+		return nil
+	}
+	info, err := binfmt.Inspect(binary.Path)
+	if err != nil {
+		return err
+	}
+	binary.Extra["Format"] = info.Format
+	binary.Extra["Static"] = strconv.FormatBool(!info.Dynamic)
+	binary.Extra["Stripped"] = strconv.FormatBool(!info.Symbols && !info.DWARF)
+	if info.Format != format {
+		return fmt.Errorf("%s is a %s executable, expected %s for %s", binary.Path, info.Format, format, target.PrettyString())
+	}
+	if info.Format == binfmt.ELF && info.OS != binfmt.ELFOS(target.OS) {
+		return fmt.Errorf("%s is an executable for %s, expected %s", binary.Path, describe(info.OS), target.PrettyString())
+	}
+	if info.Arch != "" && info.Arch != target.Arch {
+		return fmt.Errorf("%s is an executable for %s, expected %s", binary.Path, info.Arch, target.PrettyString())
+	}
+	if target.OS == "linux" && !cgoEnabled(build.Env) && info.Dynamic {
+		return fmt.Errorf("%s is dynamically linked, but cgo is disabled", binary.Path)
+	}
+	for _, flag := range strings.Fields(ldflags) {
+		if flag == "-s" && info.Symbols {
+			return fmt.Errorf("%s has a symbol table, but -s is set", binary.Path)
+		}
+		if flag == "-w" && info.DWARF {
+			return fmt.Errorf("%s has DWARF debug info, but -w is set", binary.Path)
+		}
+	}
+	return nil
+}
+
+// formatOf returns the executable format of the given GOOS, or an empty
+// string if its executables aren't verified
+func formatOf(goos string) string {
+	switch goos {
+	case "windows":
+		return binfmt.PE
+	case "darwin", "ios":
+		return binfmt.MachO
+	case "js", "wasip1":
+		return binfmt.Wasm
+	case "plan9", "aix":
+		// plan9 a.out and aix XCOFF executables aren't supported by binfmt
+		return ""
+	}
+	return binfmt.ELF
+}
+
+func describe(elfOS string) string {
+	if elfOS == "" {
+		return "linux or another System V OS"
+	}
+	return elfOS
+}
+
+// cgoEnabled returns false if the given env disables cgo. It is enabled by
+// default, but we only know for sure it isn't when CGO_ENABLED is 0.
+func cgoEnabled(env []string) bool {
+	var enabled = true
+	for _, e := range env {
+		if strings.HasPrefix(e, "CGO_ENABLED=") {
+			enabled = e != "CGO_ENABLED=0"
+		}
+	}
+	return enabled
+}
@@ -0,0 +1,92 @@
+package build
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/buildtarget"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	var linux = buildBinary(t, folder, "linux", "amd64", "-s -w")
+	var debug = buildBinary(t, folder, "linux", "arm64", "")
+	var freebsd = buildBinary(t, folder, "freebsd", "amd64", "")
+	var windows = buildBinary(t, folder, "windows", "amd64", "-s -w")
+	var aix = buildBinary(t, folder, "aix", "ppc64", "")
+	var static = config.Build{Env: []string{"CGO_ENABLED=0"}}
+	for _, tt := range []struct {
+		name    string
+		path    string
+		ldflags string
+		target  buildtarget.Target
+		err     string
+	}{
+		{"linux", linux, "-s -w", buildtarget.New("linux", "amd64", ""), ""},
+		{"windows", windows, "-s -w -X main.version=1", buildtarget.New("windows", "amd64", ""), ""},
+		{"plan9 isn't verified", linux, "", buildtarget.New("plan9", "amd64", ""), ""},
+		{"aix isn't verified", aix, "", buildtarget.New("aix", "ppc64", ""), ""},
+		{"wrong format", linux, "", buildtarget.New("windows", "amd64", ""), "is a elf executable, expected pe for windows/amd64"},
+		{"wrong os", linux, "", buildtarget.New("freebsd", "amd64", ""), "is an executable for linux or another System V OS, expected freebsd/amd64"},
+		{"wrong elf os", freebsd, "", buildtarget.New("linux", "amd64", ""), "is an executable for freebsd, expected linux/amd64"},
+		{"wrong arch", debug, "", buildtarget.New("linux", "amd64", ""), "is an executable for arm64, expected linux/amd64"},
+		{"not stripped", debug, "-s", buildtarget.New("linux", "arm64", ""), "has a symbol table, but -s is set"},
+		{"with dwarf", debug, "-w", buildtarget.New("linux", "arm64", ""), "has DWARF debug info, but -w is set"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var binary = artifact.Artifact{Path: tt.path, Extra: map[string]string{}}
+			var err = verify(static, tt.ldflags, tt.target, &binary)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assertContainsError(t, err, tt.err)
+		})
+	}
+}
+
+func TestVerifyExtra(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	writeGoodMain(t, folder)
+	var binary = artifact.Artifact{
+		Path:  buildBinary(t, folder, "linux", "amd64", "-s -w"),
+		Extra: map[string]string{},
+	}
+	assert.NoError(t, verify(config.Build{}, "-s -w", buildtarget.New("linux", "amd64", ""), &binary))
+	assert.Equal(t, map[string]string{
+		"Format":   "elf",
+		"Static":   "true",
+		"Stripped": "true",
+	}, binary.Extra)
+}
+
+func TestVerifyNotFound(t *testing.T) {
+	var binary = artifact.Artifact{Path: "/nope/nope", Extra: map[string]string{}}
+	assert.Error(t, verify(config.Build{}, "", buildtarget.New("linux", "amd64", ""), &binary))
+}
+
+func TestCgoEnabled(t *testing.T) {
+	assert.True(t, cgoEnabled(nil))
+	assert.True(t, cgoEnabled([]string{"FOO=bar"}))
+	assert.False(t, cgoEnabled([]string{"CGO_ENABLED=0"}))
+	assert.True(t, cgoEnabled([]string{"CGO_ENABLED=0", "CGO_ENABLED=1"}))
+}
+
+func buildBinary(t *testing.T, folder, goos, goarch, ldflags string) string {
+	var binary = filepath.Join(folder, goos+goarch+ldflags)
+	/* #nosec */
+	var cmd = exec.Command("go", "build", "-ldflags="+ldflags, "-o", binary, "main.go")
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+	return binary
+}
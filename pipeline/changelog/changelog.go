@@ -23,6 +23,11 @@ func (Pipe) String() string {
 	return "generating changelog"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "changelog"
+}
+
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
 	if ctx.ReleaseNotes != "" {
@@ -55,7 +60,7 @@ func checkSortDirection(mode string) error {
 }
 
 func buildChangelog(ctx *context.Context) ([]string, error) {
-	log, err := getChangelog(ctx.Git.CurrentTag)
+	log, err := getChangelog(ctx, ctx.Git.CurrentTag)
 	if err != nil {
 		return nil, err
 	}
@@ -112,29 +117,32 @@ func extractCommitInfo(line string) (hash, msg string) {
 	return ss[0], strings.Join(ss[1:], " ")
 }
 
-func getChangelog(tag string) (string, error) {
-	prev, err := previous(tag)
+func getChangelog(ctx *context.Context, tag string) (string, error) {
+	prev, err := previous(ctx, tag)
 	if err != nil {
 		return "", err
 	}
 	if !prev.Tag {
-		return gitLog(prev.SHA, tag)
+		return gitLog(ctx, prev.SHA, tag)
 	}
-	return gitLog(fmt.Sprintf("%v..%v", prev.SHA, tag))
+	return gitLog(ctx, fmt.Sprintf("%v..%v", prev.SHA, tag))
 }
 
-func gitLog(refs ...string) (string, error) {
+func gitLog(ctx *context.Context, refs ...string) (string, error) {
 	var args = []string{"log", "--pretty=oneline", "--abbrev-commit", "--no-decorate"}
 	args = append(args, refs...)
-	return git.Run(args...)
+	if dir := ctx.Config.Monorepo.Dir; dir != "" {
+		args = append(args, "--", dir)
+	}
+	return git.Run(ctx, args...)
 }
 
-func previous(tag string) (result ref, err error) {
+func previous(ctx *context.Context, tag string) (result ref, err error) {
 	result.Tag = true
-	result.SHA, err = git.Clean(git.Run("describe", "--tags", "--abbrev=0", tag+"^"))
+	result.SHA, err = git.LatestTag(ctx, ctx.Config.Monorepo.TagPrefix, tag+"^")
 	if err != nil {
 		result.Tag = false
-		result.SHA, err = git.Clean(git.Run("rev-list", "--max-parents=0", "HEAD"))
+		result.SHA, err = git.Clean(git.Run(ctx, "rev-list", "--max-parents=0", "HEAD"))
 	}
 	return
 }
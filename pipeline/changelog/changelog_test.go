@@ -1,6 +1,9 @@
 package changelog
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/apex/log"
@@ -179,6 +182,43 @@ func TestChangelogFilterInvalidRegex(t *testing.T) {
 	assert.EqualError(t, Pipe{}.Run(ctx), "error parsing regexp: invalid or unsupported Perl syntax: `(?ia`")
 }
 
+func TestChangelogMonorepo(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	testlib.GitInit(t)
+	for _, dir := range []string{"cli", "agent"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(folder, dir), 0755))
+	}
+	var commit = func(dir, msg string) {
+		assert.NoError(t, ioutil.WriteFile(
+			filepath.Join(folder, dir, "main.go"), []byte(msg), 0644,
+		))
+		testlib.GitAdd(t)
+		testlib.GitCommit(t, msg)
+	}
+	commit("cli", "cli: first")
+	testlib.GitTag(t, "cli/v1.0.0")
+	commit("agent", "agent: first")
+	testlib.GitTag(t, "agent/v1.0.0")
+	commit("cli", "cli: second")
+	commit("agent", "agent: second")
+	testlib.GitTag(t, "agent/v1.1.0")
+	commit("cli", "cli: third")
+	testlib.GitTag(t, "cli/v1.1.0")
+	var ctx = context.New(config.Project{
+		Monorepo: config.Monorepo{
+			TagPrefix: "cli/",
+			Dir:       "cli",
+		},
+	})
+	ctx.Git.CurrentTag = "cli/v1.1.0"
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Contains(t, ctx.ReleaseNotes, "cli: second")
+	assert.Contains(t, ctx.ReleaseNotes, "cli: third")
+	assert.NotContains(t, ctx.ReleaseNotes, "cli: first")
+	assert.NotContains(t, ctx.ReleaseNotes, "agent:")
+}
+
 func TestChangelogNoTags(t *testing.T) {
 	_, back := testlib.Mktmp(t)
 	defer back()
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/checksum"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -20,12 +22,27 @@ func (Pipe) String() string {
 	return "calculating checksums"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "checksums"
+}
+
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) (err error) {
 	filename, err := filenameFor(ctx)
 	if err != nil {
 		return err
 	}
+	if ctx.DryRun {
+		var path = filepath.Join(ctx.Config.Dist, filename)
+		log.WithField("file", path).Info("dry-run: would write checksums")
+		ctx.Artifacts.Add(artifact.Artifact{
+			Type: artifact.Checksum,
+			Path: path,
+			Name: filename,
+		})
+		return nil
+	}
 	file, err := os.OpenFile(
 		filepath.Join(ctx.Config.Dist, filename),
 		os.O_APPEND|os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
@@ -38,17 +55,45 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 		if err := file.Close(); err != nil {
 			log.WithError(err).Errorf("failed to close %s", file.Name())
 		}
-		ctx.AddArtifact(file.Name())
-		ctx.AddChecksum(file.Name())
+		ctx.Artifacts.Add(artifact.Artifact{
+			Type: artifact.Checksum,
+			Path: file.Name(),
+			Name: filename,
+		})
 	}()
+	var artifacts = ctx.Artifacts.Filter(
+		artifact.Or(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByType(artifact.UploadableBinary),
+			artifact.ByType(artifact.LinuxPackage),
+			artifact.ByType(artifact.Snap),
+			artifact.ByType(artifact.SBOM),
+		),
+	).List()
+	// the lines are sorted by file name so the checksums file is the same
+	// no matter the order the artifacts were created in
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Name < artifacts[j].Name
+	})
+	var lines = make([]string, len(artifacts))
 	var g errgroup.Group
-	for _, artifact := range ctx.Artifacts {
-		artifact := artifact
-		g.Go(func() error {
-			return checksums(ctx, file, artifact)
+	for i, a := range artifacts {
+		i := i
+		a := a
+		g.Go(func() (err error) {
+			lines[i], err = checksums(a)
+			return err
 		})
 	}
-	return g.Wait()
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := file.WriteString(line); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Default sets the pipe defaults
@@ -59,13 +104,11 @@ func (Pipe) Default(ctx *context.Context) error {
 	return nil
 }
 
-func checksums(ctx *context.Context, file *os.File, name string) error {
-	log.WithField("file", name).Info("checksumming")
-	var artifact = filepath.Join(ctx.Config.Dist, name)
-	sha, err := checksum.SHA256(artifact)
+func checksums(a artifact.Artifact) (string, error) {
+	log.WithField("file", a.Name).Info("checksumming")
+	sha, err := checksum.SHA256(a.Path)
 	if err != nil {
-		return err
+		return "", err
 	}
-	_, err = file.WriteString(fmt.Sprintf("%v  %v\n", sha, name))
-	return err
+	return fmt.Sprintf("%v  %v\n", sha, a.Name), nil
 }
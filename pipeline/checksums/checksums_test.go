@@ -3,10 +3,12 @@ package checksums
 import (
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,14 +32,50 @@ func TestPipe(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddArtifact(file)
+	ctx.Artifacts.Add(artifact.Artifact{
+		Path: file,
+		Name: binary,
+		Type: artifact.UploadableArchive,
+	})
 	assert.NoError(t, Pipe{}.Run(ctx))
-	assert.Contains(t, ctx.Artifacts, checksums, binary)
+	var artifacts []string
+	for _, a := range ctx.Artifacts.List() {
+		artifacts = append(artifacts, a.Name)
+	}
+	assert.Contains(t, artifacts, checksums, binary)
 	bts, err := ioutil.ReadFile(filepath.Join(folder, checksums))
 	assert.NoError(t, err)
 	assert.Equal(t, "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  binary\n", string(bts))
 }
 
+func TestPipeSortsByName(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		Checksum: config.Checksum{
+			NameTemplate: "checksums.txt",
+		},
+	})
+	for _, name := range []string{"c", "a", "b"} {
+		var file = filepath.Join(folder, name)
+		assert.NoError(t, ioutil.WriteFile(file, []byte(name), 0644))
+		ctx.Artifacts.Add(artifact.Artifact{
+			Path: file,
+			Name: name,
+			Type: artifact.UploadableArchive,
+		})
+	}
+	assert.NoError(t, Pipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "checksums.txt"))
+	assert.NoError(t, err)
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(string(bts)), "\n") {
+		names = append(names, strings.Fields(line)[1])
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+}
+
 func TestPipeFileNotExist(t *testing.T) {
 	folder, err := ioutil.TempDir("", "goreleasertest")
 	assert.NoError(t, err)
@@ -49,7 +87,11 @@ func TestPipeFileNotExist(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddArtifact("nope")
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "nope",
+		Path: filepath.Join(folder, "nope"),
+		Type: artifact.UploadableArchive,
+	})
 	err = Pipe{}.Run(ctx)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "/nope: no such file or directory")
@@ -67,10 +109,14 @@ func TestPipeInvalidNameTemplate(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddArtifact("whatever")
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "whatever",
+		Path: filepath.Join(folder, "whatever"),
+		Type: artifact.UploadableArchive,
+	})
 	err = Pipe{}.Run(ctx)
 	assert.Error(t, err)
-	assert.Equal(t, `template: checksums:1: unexpected "}" in operand`, err.Error())
+	assert.Equal(t, `template: tmpl:1: unexpected "}" in operand`, err.Error())
 }
 
 func TestPipeCouldNotOpenChecksumsTxt(t *testing.T) {
@@ -86,7 +132,11 @@ func TestPipeCouldNotOpenChecksumsTxt(t *testing.T) {
 			},
 		},
 	}
-	ctx.AddArtifact("nope")
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "nope",
+		Path: filepath.Join(folder, "nope"),
+		Type: artifact.UploadableArchive,
+	})
 	err = Pipe{}.Run(ctx)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "/checksums.txt: permission denied")
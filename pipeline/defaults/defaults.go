@@ -13,7 +13,9 @@ import (
 	"github.com/goreleaser/goreleaser/pipeline/checksums"
 	"github.com/goreleaser/goreleaser/pipeline/docker"
 	"github.com/goreleaser/goreleaser/pipeline/fpm"
+	"github.com/goreleaser/goreleaser/pipeline/provenance"
 	"github.com/goreleaser/goreleaser/pipeline/release"
+	"github.com/goreleaser/goreleaser/pipeline/sbom"
 	"github.com/goreleaser/goreleaser/pipeline/sign"
 	"github.com/goreleaser/goreleaser/pipeline/snapshot"
 )
@@ -31,7 +33,9 @@ var defaulters = []pipeline.Defaulter{
 	archive.Pipe{},
 	build.Pipe{},
 	fpm.Pipe{},
+	sbom.Pipe{},
 	checksums.Pipe{},
+	provenance.Pipe{},
 	sign.Pipe{},
 	docker.Pipe{},
 	artifactory.Pipe{},
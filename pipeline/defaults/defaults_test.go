@@ -19,9 +19,7 @@ func TestFillBasicData(t *testing.T) {
 	testlib.GitInit(t)
 	testlib.GitRemoteAdd(t, "git@github.com:goreleaser/goreleaser.git")
 
-	var ctx = &context.Context{
-		Config: config.Project{},
-	}
+	var ctx = context.New(config.Project{})
 
 	assert.NoError(t, Pipe{}.Run(ctx))
 	assert.Equal(t, "goreleaser", ctx.Config.Release.GitHub.Owner)
@@ -51,35 +49,33 @@ func TestFillPartial(t *testing.T) {
 	testlib.GitInit(t)
 	testlib.GitRemoteAdd(t, "git@github.com:goreleaser/goreleaser.git")
 
-	var ctx = &context.Context{
-		Config: config.Project{
-			Dist: "disttt",
-			Release: config.Release{
-				GitHub: config.Repo{
-					Owner: "goreleaser",
-					Name:  "test",
-				},
+	var ctx = context.New(config.Project{
+		Dist: "disttt",
+		Release: config.Release{
+			GitHub: config.Repo{
+				Owner: "goreleaser",
+				Name:  "test",
 			},
-			Archive: config.Archive{
-				Files: []string{
-					"glob/*",
-				},
+		},
+		Archive: config.Archive{
+			Files: []string{
+				"glob/*",
 			},
-			Builds: []config.Build{
-				{Binary: "testreleaser"},
-				{Goos: []string{"linux"}},
-				{
-					Binary: "another",
-					Ignore: []config.IgnoredBuild{
-						{Goos: "darwin", Goarch: "amd64"},
-					},
+		},
+		Builds: []config.Build{
+			{Binary: "testreleaser"},
+			{Goos: []string{"linux"}},
+			{
+				Binary: "another",
+				Ignore: []config.IgnoredBuild{
+					{Goos: "darwin", Goarch: "amd64"},
 				},
 			},
-			Dockers: []config.Docker{
-				{Image: "a/b"},
-			},
 		},
-	}
+		Dockers: []config.Docker{
+			{Image: "a/b"},
+		},
+	})
 	assert.NoError(t, Pipe{}.Run(ctx))
 	assert.Len(t, ctx.Config.Archive.Files, 1)
 	assert.Equal(t, `bin.install "testreleaser"`, ctx.Config.Brew.Install)
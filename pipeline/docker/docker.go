@@ -2,16 +2,17 @@
 package docker
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"text/template"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/retry"
+	"github.com/goreleaser/goreleaser/internal/runner"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pipeline"
 	"github.com/pkg/errors"
 )
@@ -26,16 +27,51 @@ func (Pipe) String() string {
 	return "creating Docker images"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "docker"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"build"}
+}
+
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
 	if len(ctx.Config.Dockers) == 0 || ctx.Config.Dockers[0].Image == "" {
 		return pipeline.Skip("docker section is not configured")
 	}
-	_, err := exec.LookPath("docker")
-	if err != nil {
+	if err := runner.LookPath(ctx, "docker"); err != nil {
+		return ErrNoDocker
+	}
+	if err := doRun(ctx); err != nil {
+		return err
+	}
+	return publish(ctx)
+}
+
+// PublishPipe pushes the docker images built by a previous run
+type PublishPipe struct{}
+
+func (PublishPipe) String() string {
+	return "pushing Docker images"
+}
+
+// ID of the pipe
+func (PublishPipe) ID() string {
+	return "docker"
+}
+
+// Run the pipe
+func (PublishPipe) Run(ctx *context.Context) error {
+	if len(ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List()) == 0 {
+		return pipeline.Skip("no docker images to push")
+	}
+	if err := runner.LookPath(ctx, "docker"); err != nil {
 		return ErrNoDocker
 	}
-	return doRun(ctx)
+	return publish(ctx)
 }
 
 // Default sets the pipe defaults
@@ -66,21 +102,24 @@ func (Pipe) Default(ctx *context.Context) error {
 
 func doRun(ctx *context.Context) error {
 	for _, docker := range ctx.Config.Dockers {
-		var imagePlatform = docker.Goos + docker.Goarch + docker.Goarm
-		for platform, groups := range ctx.Binaries {
-			if platform != imagePlatform {
+		var binaries = ctx.Artifacts.Filter(
+			artifact.And(
+				artifact.ByGoos(docker.Goos),
+				artifact.ByGoarch(docker.Goarch),
+				artifact.ByGoarm(docker.Goarm),
+				artifact.ByVariantOrDefault(docker.Goamd64+docker.Go386+docker.Gomips+docker.Goppc64),
+				artifact.ByType(artifact.Binary),
+				artifact.ByBinary(docker.Binary),
+				artifact.ByIDs(docker.Builds...),
+			),
+		).List()
+		for _, binary := range binaries {
+			if binary.Goarch == "wasm" {
+				log.WithField("binary", binary.Name).Warn("skipped wasm binary, which can't run in a docker image")
 				continue
 			}
-			for folder, binaries := range groups {
-				for _, binary := range binaries {
-					if binary.Name != docker.Binary {
-						continue
-					}
-					var err = process(ctx, folder, docker, binary)
-					if err != nil && !pipeline.IsSkip(err) {
-						return err
-					}
-				}
+			if err := process(ctx, docker, binary); err != nil {
+				return err
 			}
 		}
 	}
@@ -88,25 +127,11 @@ func doRun(ctx *context.Context) error {
 }
 
 func tagName(ctx *context.Context, docker config.Docker) (string, error) {
-	var out bytes.Buffer
-	t, err := template.New("tag").Parse(docker.TagTemplate)
-	if err != nil {
-		return "", err
-	}
-	data := struct {
-		Version, Tag string
-		Env          map[string]string
-	}{
-		Version: ctx.Version,
-		Tag:     ctx.Git.CurrentTag,
-		Env:     ctx.Env,
-	}
-	err = t.Execute(&out, data)
-	return out.String(), err
+	return tmpl.New(ctx).Apply(docker.TagTemplate)
 }
 
-func process(ctx *context.Context, folder string, docker config.Docker, binary context.Binary) error {
-	var root = filepath.Join(ctx.Config.Dist, folder)
+func process(ctx *context.Context, docker config.Docker, binary artifact.Artifact) error {
+	var root = filepath.Dir(binary.Path)
 	var dockerfile = filepath.Join(root, filepath.Base(docker.Dockerfile))
 	tag, err := tagName(ctx, docker)
 	if err != nil {
@@ -115,27 +140,46 @@ func process(ctx *context.Context, folder string, docker config.Docker, binary c
 	var image = fmt.Sprintf("%s:%s", docker.Image, tag)
 	var latest = fmt.Sprintf("%s:latest", docker.Image)
 
-	if err := os.Link(docker.Dockerfile, dockerfile); err != nil {
+	if err := link(ctx, docker.Dockerfile, dockerfile); err != nil {
 		return errors.Wrap(err, "failed to link dockerfile")
 	}
 	for _, file := range docker.Files {
-		if err := os.Link(file, filepath.Join(root, filepath.Base(file))); err != nil {
+		if err := link(ctx, file, filepath.Join(root, filepath.Base(file))); err != nil {
 			return errors.Wrapf(err, "failed to link extra file '%s'", file)
 		}
 	}
-	if err := dockerBuild(root, dockerfile, image); err != nil {
+	if err := dockerBuild(ctx, root, dockerfile, image); err != nil {
 		return err
 	}
-	if docker.Latest {
-		if err := dockerTag(image, latest); err != nil {
+	var images = []string{image}
+	if docker.Latest && ctx.PreRelease() {
+		log.WithField("image", image).Info("skipping latest tag for prerelease")
+	}
+	if docker.Latest && !ctx.PreRelease() {
+		if err := dockerTag(ctx, image, latest); err != nil {
 			return err
 		}
+		images = append(images, latest)
+	}
+	for _, img := range images {
+		ctx.Artifacts.Add(artifact.Artifact{
+			Type:   artifact.PublishableDockerImage,
+			Name:   img,
+			Path:   img,
+			Goos:   docker.Goos,
+			Goarch: docker.Goarch,
+			Goarm:  docker.Goarm,
+			// the variants of the other archs
+			Goamd64: binary.Goamd64,
+			Go386:   binary.Go386,
+			Gomips:  binary.Gomips,
+			Goppc64: binary.Goppc64,
+		})
 	}
-
-	return publish(ctx, docker, image, latest)
+	return nil
 }
 
-func publish(ctx *context.Context, docker config.Docker, image, latest string) error {
+func publish(ctx *context.Context) error {
 	// TODO: improve this so it can log it to stdout
 	if !ctx.Publish {
 		return pipeline.Skip("--skip-publish is set")
@@ -143,25 +187,36 @@ func publish(ctx *context.Context, docker config.Docker, image, latest string) e
 	if ctx.Config.Release.Draft {
 		return pipeline.Skip("release is marked as draft")
 	}
-	if err := dockerPush(image); err != nil {
-		return err
+	for _, image := range ctx.Artifacts.Filter(
+		artifact.ByType(artifact.PublishableDockerImage),
+	).List() {
+		if err := dockerPush(ctx, image.Name); err != nil {
+			return err
+		}
+		var name = image.Name
+		ctx.Undo("remove docker image "+name, func(ctx *context.Context) error {
+			// docker has no command to delete an image from a registry
+			return fmt.Errorf("%s was already pushed, remove it from the registry manually", name)
+		})
+		image.Type = artifact.DockerImage
+		ctx.Artifacts.Add(image)
 	}
-	ctx.AddDocker(image)
-	if !docker.Latest {
+	return nil
+}
+
+func link(ctx *context.Context, src, dst string) error {
+	if ctx.DryRun {
+		log.WithField("src", src).WithField("dst", dst).Info("dry-run: would link file")
 		return nil
 	}
-	if err := dockerTag(image, latest); err != nil {
-		return err
-	}
-	return dockerPush(latest)
+	return os.Link(src, dst)
 }
 
-func dockerBuild(root, dockerfile, image string) error {
+func dockerBuild(ctx *context.Context, root, dockerfile, image string) error {
 	log.WithField("image", image).Info("building docker image")
-	/* #nosec */
-	var cmd = exec.Command("docker", "build", "-f", dockerfile, "-t", image, root)
-	log.WithField("cmd", cmd).Debug("executing")
-	out, err := cmd.CombinedOutput()
+	out, err := runner.Run(ctx, runner.Cmd{
+		Args: []string{"docker", "build", "-f", dockerfile, "-t", image, root},
+	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to build docker image: \n%s", string(out))
 	}
@@ -169,12 +224,11 @@ func dockerBuild(root, dockerfile, image string) error {
 	return nil
 }
 
-func dockerTag(image, tag string) error {
+func dockerTag(ctx *context.Context, image, tag string) error {
 	log.WithField("image", image).WithField("tag", tag).Info("tagging docker image")
-	/* #nosec */
-	var cmd = exec.Command("docker", "tag", image, tag)
-	log.WithField("cmd", cmd).Debug("executing")
-	out, err := cmd.CombinedOutput()
+	out, err := runner.Run(ctx, runner.Cmd{
+		Args: []string{"docker", "tag", image, tag},
+	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to tag docker image: \n%s", string(out))
 	}
@@ -182,12 +236,17 @@ func dockerTag(image, tag string) error {
 	return nil
 }
 
-func dockerPush(image string) error {
+func dockerPush(ctx *context.Context, image string) error {
 	log.WithField("image", image).Info("pushing docker image")
-	/* #nosec */
-	var cmd = exec.Command("docker", "push", image)
-	log.WithField("cmd", cmd).Debug("executing")
-	out, err := cmd.CombinedOutput()
+	var out []byte
+	var err = retry.New(ctx.Config.Retry).Do(ctx, func() error {
+		var err error
+		out, err = runner.Run(ctx, runner.Cmd{
+			Args: []string{"docker", "push", image},
+		})
+		// docker doesn't tell why a push failed, so always retry
+		return retry.Temporary(err, 0)
+	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to push docker image: \n%s", string(out))
 	}
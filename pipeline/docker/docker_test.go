@@ -10,6 +10,7 @@ import (
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pipeline"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,6 +45,7 @@ func TestRunPipe(t *testing.T) {
 	var table = map[string]struct {
 		docker config.Docker
 		err    string
+		images int
 	}{
 		"valid": {
 			docker: config.Docker{
@@ -55,7 +57,8 @@ func TestRunPipe(t *testing.T) {
 				Latest:      true,
 				TagTemplate: "{{.Tag}}-{{.Env.FOO}}",
 			},
-			err: "",
+			err:    "",
+			images: 2,
 		},
 		"invalid": {
 			docker: config.Docker{
@@ -78,7 +81,7 @@ func TestRunPipe(t *testing.T) {
 				Latest:      true,
 				TagTemplate: "{{.Tag}",
 			},
-			err: `template: tag:1: unexpected "}" in operand`,
+			err: `template: tmpl:1: unexpected "}" in operand`,
 		},
 	}
 	var images = []string{
@@ -92,29 +95,51 @@ func TestRunPipe(t *testing.T) {
 
 	for name, docker := range table {
 		t.Run(name, func(t *testing.T) {
-			var ctx = &context.Context{
-				Version: "1.0.0",
-				Publish: true,
-				Git: context.GitInfo{
-					CurrentTag: "v1.0.0",
-				},
-				Config: config.Project{
-					ProjectName: "mybin",
-					Dist:        dist,
-					Dockers: []config.Docker{
-						docker.docker,
-					},
+			var ctx = context.New(config.Project{
+				ProjectName: "mybin",
+				Dist:        dist,
+				Dockers: []config.Docker{
+					docker.docker,
 				},
-				Env: map[string]string{"FOO": "123"},
+			})
+			ctx.Version = "1.0.0"
+			ctx.Publish = true
+			ctx.Git = context.GitInfo{
+				CurrentTag: "v1.0.0",
 			}
-			for _, plat := range []string{"linuxamd64", "linux386", "darwinamd64"} {
-				ctx.AddBinary(plat, "mybin", "mybin", binPath)
+			ctx.Env = map[string]string{"FOO": "123"}
+			for _, goos := range []string{"linux", "darwin"} {
+				for _, goarch := range []string{"amd64", "386"} {
+					ctx.Artifacts.Add(artifact.Artifact{
+						Name:   "mybin",
+						Path:   binPath,
+						Goarch: goarch,
+						Goos:   goos,
+						Type:   artifact.Binary,
+						Extra: map[string]string{
+							"Binary": "mybin",
+						},
+					})
+				}
+				// only the default variant is used, unless configured
+				ctx.Artifacts.Add(artifact.Artifact{
+					Name:    "mybin",
+					Path:    binPath,
+					Goarch:  "amd64",
+					Goamd64: "v3",
+					Goos:    goos,
+					Type:    artifact.Binary,
+					Extra: map[string]string{
+						"Binary": "mybin",
+					},
+				})
 			}
 			if docker.err == "" {
 				assert.NoError(t, Pipe{}.Run(ctx))
 			} else {
 				assert.EqualError(t, Pipe{}.Run(ctx), docker.err)
 			}
+			assert.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.PublishableDockerImage)).List(), docker.images)
 		})
 	}
 
@@ -156,34 +181,30 @@ func TestDockerNotInPath(t *testing.T) {
 		assert.NoError(t, os.Setenv("PATH", path))
 	}()
 	assert.NoError(t, os.Setenv("PATH", ""))
-	var ctx = &context.Context{
-		Version: "1.0.0",
-		Config: config.Project{
-			Dockers: []config.Docker{
-				{
-					Image: "a/b",
-				},
+	var ctx = context.New(config.Project{
+		Dockers: []config.Docker{
+			{
+				Image: "a/b",
 			},
 		},
-	}
+	})
+	ctx.Version = "1.0.0"
 	assert.EqualError(t, Pipe{}.Run(ctx), ErrNoDocker.Error())
 }
 
 func TestDefault(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{
-			Builds: []config.Build{
-				{
-					Binary: "foo",
-				},
+	var ctx = context.New(config.Project{
+		Builds: []config.Build{
+			{
+				Binary: "foo",
 			},
-			Dockers: []config.Docker{
-				{
-					Latest: true,
-				},
+		},
+		Dockers: []config.Docker{
+			{
+				Latest: true,
 			},
 		},
-	}
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Len(t, ctx.Config.Dockers, 1)
 	var docker = ctx.Config.Dockers[0]
@@ -195,28 +216,24 @@ func TestDefault(t *testing.T) {
 }
 
 func TestDefaultNoDockers(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{
-			Dockers: []config.Docker{},
-		},
-	}
+	var ctx = context.New(config.Project{
+		Dockers: []config.Docker{},
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Empty(t, ctx.Config.Dockers)
 }
 
 func TestDefaultSet(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{
-			Dockers: []config.Docker{
-				{
-					Goos:       "windows",
-					Goarch:     "i386",
-					Binary:     "bar",
-					Dockerfile: "Dockerfile.foo",
-				},
+	var ctx = context.New(config.Project{
+		Dockers: []config.Docker{
+			{
+				Goos:       "windows",
+				Goarch:     "i386",
+				Binary:     "bar",
+				Dockerfile: "Dockerfile.foo",
 			},
 		},
-	}
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Len(t, ctx.Config.Dockers, 1)
 	var docker = ctx.Config.Dockers[0]
@@ -9,6 +9,9 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
+// Filename is the name of the effective config file inside dist
+const Filename = "config.yaml"
+
 // Pipe that writes the effective config file to dist
 type Pipe struct {
 }
@@ -19,7 +22,7 @@ func (Pipe) String() string {
 
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) (err error) {
-	var path = filepath.Join(ctx.Config.Dist, "config.yaml")
+	var path = filepath.Join(ctx.Config.Dist, Filename)
 	bts, err := yaml.Marshal(ctx.Config)
 	if err != nil {
 		return err
@@ -29,6 +29,9 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 	if !ctx.Validate {
 		return pipeline.Skip("--skip-validate is set")
 	}
+	if ctx.Token == "" && ctx.DryRun {
+		return pipeline.Skip("missing GITHUB_TOKEN, but --dry-run is set")
+	}
 	if ctx.Token == "" {
 		return ErrMissingToken
 	}
@@ -35,6 +35,17 @@ func TestInvalidEnv(t *testing.T) {
 	assert.Error(t, Pipe{}.Run(ctx))
 }
 
+func TestInvalidEnvDryRun(t *testing.T) {
+	assert.NoError(t, os.Unsetenv("GITHUB_TOKEN"))
+	var ctx = &context.Context{
+		Config:   config.Project{},
+		Validate: true,
+		Publish:  true,
+		DryRun:   true,
+	}
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
 type flags struct {
 	Validate, Publish, Snapshot bool
 }
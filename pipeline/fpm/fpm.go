@@ -1,16 +1,20 @@
-// Package fpm implements the Pipe interface providing FPM bindings.
+// Package fpm implements the Pipe interface creating Linux packages, either
+// natively or with FPM.
 package fpm
 
 import (
 	"fmt"
 	"io/ioutil"
-	"os/exec"
+	"os"
+	"path"
 	"path/filepath"
-	"strings"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/linux"
+	"github.com/goreleaser/goreleaser/internal/nametemplate"
+	"github.com/goreleaser/goreleaser/internal/runner"
 	"github.com/goreleaser/goreleaser/pipeline"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
@@ -23,7 +27,17 @@ var ErrNoFPM = errors.New("fpm not present in $PATH")
 type Pipe struct{}
 
 func (Pipe) String() string {
-	return "creating Linux packages with fpm"
+	return "creating Linux packages"
+}
+
+// ID of the pipe
+func (Pipe) ID() string {
+	return "fpm"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"build"}
 }
 
 // Default sets the pipe defaults
@@ -31,6 +45,9 @@ func (Pipe) Default(ctx *context.Context) error {
 	if ctx.Config.FPM.Bindir == "" {
 		ctx.Config.FPM.Bindir = "/usr/local/bin"
 	}
+	if ctx.Config.FPM.Packager == "" {
+		ctx.Config.FPM.Packager = "native"
+	}
 	return nil
 }
 
@@ -39,46 +56,142 @@ func (Pipe) Run(ctx *context.Context) error {
 	if len(ctx.Config.FPM.Formats) == 0 {
 		return pipeline.Skip("no output formats configured")
 	}
-	_, err := exec.LookPath("fpm")
-	if err != nil {
-		return ErrNoFPM
+	for _, format := range ctx.Config.FPM.Formats {
+		if !usesFPM(ctx, format) {
+			continue
+		}
+		if err := runner.LookPath(ctx, "fpm"); err != nil {
+			return ErrNoFPM
+		}
 	}
 	return doRun(ctx)
 }
 
+// usesFPM returns true if the packages of the given format are created
+// with fpm instead of natively
+func usesFPM(ctx *context.Context, format string) bool {
+	return ctx.Config.FPM.Packager == "fpm" || !linux.Supports(format)
+}
+
 func doRun(ctx *context.Context) error {
 	var g errgroup.Group
 	sem := make(chan bool, ctx.Parallelism)
 	for _, format := range ctx.Config.FPM.Formats {
-		for platform, groups := range ctx.Binaries {
-			if !strings.Contains(platform, "linux") {
-				log.WithField("platform", platform).Debug("skipped non-linux builds for fpm")
-				continue
-			}
+		for platform, artifacts := range ctx.Artifacts.Filter(
+			artifact.And(
+				artifact.ByType(artifact.Binary),
+				artifact.ByGoos("linux"),
+				artifact.ByIDs(ctx.Config.FPM.Builds...),
+			),
+		).GroupByPlatform() {
 			sem <- true
 			format := format
-			arch := linux.Arch(platform)
-			for folder, binaries := range groups {
-				g.Go(func() error {
-					defer func() {
-						<-sem
-					}()
-					return create(ctx, format, folder, arch, binaries)
-				})
-			}
+			arch := linux.Arch(artifacts[0].Goarch, artifacts[0].Variant())
+			artifacts := artifacts
+			log.WithField("platform", platform).Debug("creating fpm package")
+			g.Go(func() error {
+				defer func() {
+					<-sem
+				}()
+				return create(ctx, format, arch, artifacts)
+			})
 		}
 	}
 	return g.Wait()
 }
 
-func create(ctx *context.Context, format, folder, arch string, binaries []context.Binary) error {
-	var path = filepath.Join(ctx.Config.Dist, folder)
-	var file = path + "." + format
+func create(ctx *context.Context, format, arch string, binaries []artifact.Artifact) error {
+	folder, err := nametemplate.Apply(ctx, ctx.Config.ArchiveFor(binaries[0].Extra["ID"]), binaries[0], ctx.Config.ProjectName)
+	if err != nil {
+		return err
+	}
+	var file = filepath.Join(ctx.Config.Dist, folder) + "." + format
+	if usesFPM(ctx, format) {
+		err = createWithFPM(ctx, format, arch, file, binaries)
+	} else {
+		err = createNative(ctx, format, file, binaries)
+	}
+	if err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type:    artifact.LinuxPackage,
+		Name:    folder + "." + format,
+		Path:    file,
+		Goos:    binaries[0].Goos,
+		Goarch:  binaries[0].Goarch,
+		Goarm:   binaries[0].Goarm,
+		Goamd64: binaries[0].Goamd64,
+		Go386:   binaries[0].Go386,
+		Gomips:  binaries[0].Gomips,
+		Goppc64: binaries[0].Goppc64,
+		Extra: map[string]string{
+			"ID": artifact.JoinIDs(binaries),
+		},
+	})
+	return nil
+}
+
+// createNative writes the package of the given format without fpm
+func createNative(ctx *context.Context, format, file string, binaries []artifact.Artifact) error {
+	var cfg = ctx.Config.FPM
+	var pkg = linux.Package{
+		Name:         ctx.Config.ProjectName,
+		Version:      ctx.Version,
+		Goarch:       binaries[0].Goarch,
+		Variant:      binaries[0].Variant(),
+		Vendor:       cfg.Vendor,
+		Homepage:     cfg.Homepage,
+		Maintainer:   cfg.Maintainer,
+		Description:  cfg.Description,
+		License:      cfg.License,
+		Dependencies: cfg.Dependencies,
+		Conflicts:    cfg.Conflicts,
+		Date:         ctx.SourceDate(),
+	}
+	for _, binary := range binaries {
+		pkg.Files = append(pkg.Files, linux.File{
+			Source:      binary.Path,
+			Destination: path.Join(cfg.Bindir, binary.Name),
+			Mode:        0755,
+		})
+	}
+	for src, dest := range cfg.Files {
+		files, err := linux.Files(src, dest)
+		if err != nil {
+			return err
+		}
+		pkg.Files = append(pkg.Files, files...)
+	}
+	var log = log.WithField("format", format).WithField("file", file)
+	if ctx.DryRun {
+		log.Info("dry-run: would create linux package")
+		return nil
+	}
+	log.Info("creating linux package")
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := linux.Write(format, f, pkg); err != nil {
+		f.Close() // nolint: errcheck
+		return errors.Wrapf(err, "failed to create %s", file)
+	}
+	return f.Close()
+}
+
+// createWithFPM creates the package of the given format with fpm
+func createWithFPM(ctx *context.Context, format, arch, file string, binaries []artifact.Artifact) error {
 	var log = log.WithField("format", format).WithField("arch", arch)
 	dir, err := ioutil.TempDir("", "fpm")
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.WithError(err).WithField("workdir", dir).Warn("failed to remove temp dir")
+		}
+	}()
 	log.WithField("file", file).WithField("workdir", dir).Info("creating fpm archive")
 	var options = basicOptions(ctx, dir, format, arch, file)
 
@@ -107,11 +220,11 @@ func create(ctx *context.Context, format, folder, arch string, binaries []contex
 	}
 
 	log.WithField("args", options).Debug("creating fpm package")
-	/* #nosec */
-	if out, err := exec.Command("fpm", options...).CombinedOutput(); err != nil {
+	if out, err := runner.Run(ctx, runner.Cmd{
+		Args: append([]string{"fpm"}, options...),
+	}); err != nil {
 		return errors.Wrap(err, string(out))
 	}
-	ctx.AddArtifact(file)
 	return nil
 }
 
@@ -5,10 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"testing"
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,14 +20,31 @@ func TestDescription(t *testing.T) {
 }
 
 func TestRunPipeNoFormats(t *testing.T) {
-	var ctx = &context.Context{
-		Version:     "1.0.0",
-		Config:      config.Project{},
-		Parallelism: runtime.NumCPU(),
-	}
+	var ctx = context.New(config.Project{})
+	ctx.Version = "1.0.0"
+	ctx.Parallelism = runtime.NumCPU()
 	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
 }
 
+func TestRunPipeOnlyWasm(t *testing.T) {
+	var ctx = context.New(config.Project{
+		FPM: config.FPM{
+			Formats: []string{"deb", "rpm"},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Parallelism = runtime.NumCPU()
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name:   "mybin.wasm",
+		Path:   "doesnt matter",
+		Goos:   "js",
+		Goarch: "wasm",
+		Type:   artifact.Binary,
+	})
+	assert.NoError(t, doRun(ctx))
+	assert.Empty(t, ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List())
+}
+
 func TestRunPipe(t *testing.T) {
 	folder, err := ioutil.TempDir("", "archivetest")
 	assert.NoError(t, err)
@@ -35,46 +54,116 @@ func TestRunPipe(t *testing.T) {
 	var binPath = filepath.Join(dist, "mybin", "mybin")
 	_, err = os.Create(binPath)
 	assert.NoError(t, err)
-	var ctx = &context.Context{
-		Version:     "1.0.0",
-		Parallelism: runtime.NumCPU(),
-		Debug:       true,
-		Config: config.Project{
-			ProjectName: "mybin",
-			Dist:        dist,
-			FPM: config.FPM{
-				Formats:      []string{"deb", "rpm"},
-				Dependencies: []string{"make"},
-				Conflicts:    []string{"git"},
-				Description:  "Some description",
-				License:      "MIT",
-				Maintainer:   "me@me",
-				Vendor:       "asdf",
-				Homepage:     "https://goreleaser.github.io",
-			},
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		FPM: config.FPM{
+			Formats:      []string{"deb", "rpm"},
+			Dependencies: []string{"make"},
+			Conflicts:    []string{"git"},
+			Description:  "Some description",
+			License:      "MIT",
+			Maintainer:   "me@me",
+			Vendor:       "asdf",
+			Homepage:     "https://goreleaser.github.io",
+			Bindir:       "/usr/local/bin",
 		},
-	}
-	for _, plat := range []string{"linuxamd64", "linux386", "darwinamd64"} {
-		ctx.AddBinary(plat, "mybin", "mybin", binPath)
+	})
+	ctx.Version = "1.0.0"
+	ctx.Parallelism = runtime.NumCPU()
+	ctx.Debug = true
+	for _, goos := range []string{"linux", "darwin"} {
+		for _, goarch := range []string{"amd64", "386"} {
+			ctx.Artifacts.Add(artifact.Artifact{
+				Name:   "mybin",
+				Path:   binPath,
+				Goarch: goarch,
+				Goos:   goos,
+				Type:   artifact.Binary,
+			})
+		}
 	}
 	assert.NoError(t, Pipe{}.Run(ctx))
+	var packages = ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List()
+	assert.Len(t, packages, 4)
+	for _, pkg := range packages {
+		assert.Equal(t, "linux", pkg.Goos)
+		_, err := os.Stat(pkg.Path)
+		assert.NoError(t, err, pkg.Path)
+	}
 }
 
-func TestNoFPMInPath(t *testing.T) {
+func TestRunPipeNativeWithoutFPM(t *testing.T) {
 	var path = os.Getenv("PATH")
 	defer func() {
 		assert.NoError(t, os.Setenv("PATH", path))
 	}()
 	assert.NoError(t, os.Setenv("PATH", ""))
-	var ctx = &context.Context{
-		Version:     "1.0.0",
-		Parallelism: runtime.NumCPU(),
-		Config: config.Project{
-			FPM: config.FPM{
-				Formats: []string{"deb", "rpm"},
+	folder, err := ioutil.TempDir("", "archivetest")
+	assert.NoError(t, err)
+	var binPath = filepath.Join(folder, "mybin")
+	assert.NoError(t, ioutil.WriteFile(binPath, []byte("bin"), 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        folder,
+		FPM: config.FPM{
+			Formats:  []string{"deb", "rpm", "apk"},
+			Packager: "native",
+			Bindir:   "/usr/bin",
+			Files: map[string]string{
+				"testdata/testfile.txt": "/var/lib/test/testfile.txt",
 			},
 		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Parallelism = runtime.NumCPU()
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name:   "mybin",
+		Path:   binPath,
+		Goos:   "linux",
+		Goarch: "arm64",
+		Type:   artifact.Binary,
+	})
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var names []string
+	for _, pkg := range ctx.Artifacts.Filter(artifact.ByType(artifact.LinuxPackage)).List() {
+		names = append(names, filepath.Ext(pkg.Name))
 	}
+	sort.Strings(names)
+	assert.Equal(t, []string{".apk", ".deb", ".rpm"}, names)
+}
+
+func TestNoFPMInPathForUnsupportedFormat(t *testing.T) {
+	var path = os.Getenv("PATH")
+	defer func() {
+		assert.NoError(t, os.Setenv("PATH", path))
+	}()
+	assert.NoError(t, os.Setenv("PATH", ""))
+	var ctx = context.New(config.Project{
+		FPM: config.FPM{
+			Formats:  []string{"deb", "pacman"},
+			Packager: "native",
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Parallelism = runtime.NumCPU()
+	assert.EqualError(t, Pipe{}.Run(ctx), ErrNoFPM.Error())
+}
+
+func TestNoFPMInPath(t *testing.T) {
+	var path = os.Getenv("PATH")
+	defer func() {
+		assert.NoError(t, os.Setenv("PATH", path))
+	}()
+	assert.NoError(t, os.Setenv("PATH", ""))
+	var ctx = context.New(config.Project{
+		FPM: config.FPM{
+			Formats:  []string{"deb", "rpm"},
+			Packager: "fpm",
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Parallelism = runtime.NumCPU()
 	assert.EqualError(t, Pipe{}.Run(ctx), ErrNoFPM.Error())
 }
 
@@ -84,54 +173,55 @@ func TestCreateFileDoesntExist(t *testing.T) {
 	var dist = filepath.Join(folder, "dist")
 	assert.NoError(t, os.Mkdir(dist, 0755))
 	assert.NoError(t, os.Mkdir(filepath.Join(dist, "mybin"), 0755))
-	var ctx = &context.Context{
-		Version:     "1.0.0",
-		Parallelism: runtime.NumCPU(),
-		Config: config.Project{
-			Dist: dist,
-			FPM: config.FPM{
-				Formats: []string{"deb", "rpm"},
-				Files: map[string]string{
-					"testdata/testfile.txt": "/var/lib/test/testfile.txt",
-				},
+	var ctx = context.New(config.Project{
+		Dist: dist,
+		FPM: config.FPM{
+			Formats: []string{"deb", "rpm"},
+			Files: map[string]string{
+				"testdata/testfile.txt": "/var/lib/test/testfile.txt",
 			},
 		},
-	}
-	ctx.AddBinary("linuxamd64", "mybin", "mybin", filepath.Join(dist, "mybin", "mybin"))
+	})
+	ctx.Version = "1.0.0"
+	ctx.Parallelism = runtime.NumCPU()
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name:   "mybin",
+		Path:   filepath.Join(dist, "mybin", "mybin"),
+		Goos:   "linux",
+		Goarch: "amd64",
+		Type:   artifact.Binary,
+	})
 	assert.Error(t, Pipe{}.Run(ctx))
 }
 
 func TestRunPipeWithExtraFiles(t *testing.T) {
-	var ctx = &context.Context{
-		Version:     "1.0.0",
-		Parallelism: runtime.NumCPU(),
-		Config: config.Project{
-			FPM: config.FPM{
-				Formats: []string{"deb", "rpm"},
-			},
+	var ctx = context.New(config.Project{
+		FPM: config.FPM{
+			Formats: []string{"deb", "rpm"},
 		},
-	}
+	})
+	ctx.Version = "1.0.0"
+	ctx.Parallelism = runtime.NumCPU()
 	assert.NoError(t, Pipe{}.Run(ctx))
 }
 
 func TestDefault(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{
-			FPM: config.FPM{},
-		},
-	}
+	var ctx = context.New(config.Project{
+		FPM: config.FPM{},
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Equal(t, "/usr/local/bin", ctx.Config.FPM.Bindir)
+	assert.Equal(t, "native", ctx.Config.FPM.Packager)
 }
 
 func TestDefaultSet(t *testing.T) {
-	var ctx = &context.Context{
-		Config: config.Project{
-			FPM: config.FPM{
-				Bindir: "/bin",
-			},
+	var ctx = context.New(config.Project{
+		FPM: config.FPM{
+			Bindir:   "/bin",
+			Packager: "fpm",
 		},
-	}
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Equal(t, "/bin", ctx.Config.FPM.Bindir)
+	assert.Equal(t, "fpm", ctx.Config.FPM.Packager)
 }
@@ -0,0 +1,16 @@
+package fromdist
+
+import "fmt"
+
+// ErrMismatch happens when the dist folder was not generated from the
+// current git state
+type ErrMismatch struct {
+	field, dist, current string
+}
+
+func (e ErrMismatch) Error() string {
+	return fmt.Sprintf(
+		"dist %v %v doesn't match the current git %v %v",
+		e.field, e.dist, e.field, e.current,
+	)
+}
@@ -0,0 +1,92 @@
+// Package fromdist provides a Pipe that loads the artifacts and metadata of
+// a previous run from the dist folder, so they can be published.
+package fromdist
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/semver"
+	"github.com/goreleaser/goreleaser/pipeline/metadata"
+	"github.com/pkg/errors"
+)
+
+// Pipe that loads a previous run from dist
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "loading artifacts from dist"
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	var info metadata.Metadata
+	if err := read(filepath.Join(ctx.Config.Dist, metadata.MetadataFile), &info); err != nil {
+		return err
+	}
+	var artifacts []metadata.Artifact
+	if err := read(filepath.Join(ctx.Config.Dist, metadata.ArtifactsFile), &artifacts); err != nil {
+		return err
+	}
+	if err := validate(ctx, info); err != nil {
+		return err
+	}
+	ctx.Git = context.GitInfo{
+		CurrentTag:  info.Tag,
+		PreviousTag: info.PreviousTag,
+		Commit:      info.Commit,
+	}
+	ctx.Version = info.Version
+	ctx.Semver, _ = semver.ParseTolerant(info.Version)
+	for i, a := range artifacts {
+		if a.Type == artifact.DockerImage || a.Type == artifact.PublishableDockerImage {
+			continue
+		}
+		// the paths are relative to dist, which may have been moved
+		if !filepath.IsAbs(a.Path) {
+			artifacts[i].Path = filepath.Join(ctx.Config.Dist, a.Path)
+		}
+		if _, err := os.Stat(artifacts[i].Path); err != nil {
+			return errors.Wrapf(err, "artifact %s is missing from %s", a.Name, ctx.Config.Dist)
+		}
+	}
+	log.Infof("publishing %s, commit %s", info.Tag, info.Commit)
+	for _, a := range artifacts {
+		ctx.Artifacts.Add(a.Artifact)
+	}
+	return nil
+}
+
+func validate(ctx *context.Context, info metadata.Metadata) error {
+	var prefix = ctx.Config.Monorepo.TagPrefix
+	tag, err := git.LatestTag(ctx, prefix)
+	if err != nil {
+		return errors.Wrap(err, "failed to get current git tag")
+	}
+	if version := git.VersionOf(tag, prefix); version != info.Version {
+		return ErrMismatch{"version", info.Version, version}
+	}
+	commit, err := git.Clean(git.Run(ctx, "show", "--format='%H'", "HEAD"))
+	if err != nil {
+		return errors.Wrap(err, "failed to get current git commit")
+	}
+	if commit != info.Commit {
+		return ErrMismatch{"commit", info.Commit, commit}
+	}
+	return nil
+}
+
+func read(path string, v interface{}) error {
+	log.WithField("file", path).Debug("loading")
+	bts, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bts, v)
+}
@@ -0,0 +1,118 @@
+package fromdist
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pipeline/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescription(t *testing.T) {
+	assert.NotEmpty(t, Pipe{}.String())
+}
+
+func TestRunPipe(t *testing.T) {
+	dist, commit, back := setup(t, "1.0.0", "")
+	defer back()
+	var ctx = context.New(config.Project{Dist: dist})
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Equal(t, "v1.0.0", ctx.Git.CurrentTag)
+	assert.Equal(t, commit, ctx.Git.Commit)
+	assert.Equal(t, "1.0.0", ctx.Version)
+	var archives = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	assert.Len(t, archives, 1)
+	assert.Equal(t, "foo.tar.gz", archives[0].Name)
+	assert.Equal(t, "linux", archives[0].Goos)
+	assert.Equal(t, filepath.Join(dist, "foo.tar.gz"), archives[0].Path)
+}
+
+func TestRunPipeRelocatedDist(t *testing.T) {
+	dist, _, back := setup(t, "1.0.0", "")
+	defer back()
+	var moved = filepath.Join(filepath.Dir(dist), "downloaded", "dist")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(moved), 0755))
+	assert.NoError(t, os.Rename(dist, moved))
+	var ctx = context.New(config.Project{Dist: moved})
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var archives = ctx.Artifacts.Filter(artifact.ByType(artifact.UploadableArchive)).List()
+	assert.Len(t, archives, 1)
+	assert.Equal(t, filepath.Join(moved, "foo.tar.gz"), archives[0].Path)
+}
+
+func TestRunPipeMissingArtifact(t *testing.T) {
+	dist, _, back := setup(t, "1.0.0", "")
+	defer back()
+	assert.NoError(t, os.Remove(filepath.Join(dist, "foo.tar.gz")))
+	var ctx = context.New(config.Project{Dist: dist})
+	var err = Pipe{}.Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "artifact foo.tar.gz is missing from "+dist)
+	assert.Empty(t, ctx.Artifacts.List())
+}
+
+func TestRunPipeVersionMismatch(t *testing.T) {
+	dist, _, back := setup(t, "0.9.0", "")
+	defer back()
+	var ctx = context.New(config.Project{Dist: dist})
+	assert.EqualError(
+		t,
+		Pipe{}.Run(ctx),
+		"dist version 0.9.0 doesn't match the current git version 1.0.0",
+	)
+	assert.Empty(t, ctx.Artifacts.List())
+}
+
+func TestRunPipeCommitMismatch(t *testing.T) {
+	dist, _, back := setup(t, "1.0.0", "a1b2c3")
+	defer back()
+	var ctx = context.New(config.Project{Dist: dist})
+	var err = Pipe{}.Run(ctx)
+	assert.Error(t, err)
+	assert.IsType(t, ErrMismatch{}, err)
+	assert.Contains(t, err.Error(), "dist commit a1b2c3 doesn't match")
+}
+
+func TestRunPipeNoManifest(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{Dist: filepath.Join(folder, "dist")})
+	assert.Error(t, Pipe{}.Run(ctx))
+}
+
+// setup creates a git repo tagged v1.0.0 and a dist folder written by the
+// metadata pipe with the given version and commit. If commit is empty, the
+// current commit is used.
+func setup(t *testing.T, version, commit string) (dist, head string, back func()) {
+	folder, back := testlib.Mktmp(t)
+	testlib.GitInit(t)
+	testlib.GitCommit(t, "first")
+	testlib.GitTag(t, "v1.0.0")
+	dist = filepath.Join(folder, "dist")
+	var ctx = context.New(config.Project{Dist: dist})
+	head, err := git.Clean(git.Run(ctx, "show", "--format='%H'", "HEAD"))
+	assert.NoError(t, err)
+	if commit == "" {
+		commit = head
+	}
+	assert.NoError(t, os.Mkdir(dist, 0755))
+	var file = filepath.Join(dist, "foo.tar.gz")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("foo"), 0644))
+	ctx.Version = version
+	ctx.Git = context.GitInfo{CurrentTag: "v" + version, Commit: commit}
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "foo.tar.gz",
+		Path: file,
+		Goos: "linux",
+		Type: artifact.UploadableArchive,
+	})
+	assert.NoError(t, metadata.Write(ctx))
+	return dist, head, back
+}
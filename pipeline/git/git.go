@@ -3,15 +3,17 @@
 package git
 
 import (
-	"bytes"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/context"
 	"github.com/goreleaser/goreleaser/internal/git"
+	"github.com/goreleaser/goreleaser/internal/semver"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
 	"github.com/goreleaser/goreleaser/pipeline"
 	"github.com/pkg/errors"
 )
@@ -25,7 +27,7 @@ func (Pipe) String() string {
 
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) (err error) {
-	tag, commit, err := getInfo()
+	tag, commit, err := getInfo(ctx)
 	if err != nil {
 		return
 	}
@@ -33,8 +35,13 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 		return ErrNoTag
 	}
 	ctx.Git = context.GitInfo{
-		CurrentTag: tag,
-		Commit:     commit,
+		CurrentTag:  tag,
+		PreviousTag: previousTag(ctx, tag),
+		Commit:      commit,
+		URL:         remoteURL(ctx),
+	}
+	if ctx.Git.CommitDate, err = commitDate(ctx); err != nil {
+		return
 	}
 	log.Infof("releasing %s, commit %s", tag, commit)
 	if err = setVersion(ctx, tag, commit); err != nil {
@@ -43,67 +50,107 @@ func (Pipe) Run(ctx *context.Context) (err error) {
 	if !ctx.Validate {
 		return pipeline.Skip("--skip-validate is set")
 	}
-	return validate(ctx, commit, tag)
+	err = validate(ctx, commit, tag)
+	if err != nil && ctx.DryRun {
+		return pipeline.Skip(err.Error() + ", but --dry-run is set")
+	}
+	return err
 }
 
 func setVersion(ctx *context.Context, tag, commit string) (err error) {
 	if ctx.Snapshot {
-		snapshotName, err := getSnapshotName(ctx, tag, commit)
+		snapshotName, err := getSnapshotName(ctx)
 		if err != nil {
 			return errors.Wrap(err, "failed to generate snapshot name")
 		}
 		ctx.Version = snapshotName
 		return nil
 	}
-	// removes usual `v` prefix
-	ctx.Version = strings.TrimPrefix(tag, "v")
+	// removes the monorepo tag prefix and the usual `v` prefix
+	ctx.Version = git.VersionOf(tag, ctx.Config.Monorepo.TagPrefix)
+	// an invalid version is only an error if validation is on, see validate
+	ctx.Semver, _ = semver.ParseTolerant(ctx.Version)
 	return
 }
 
-type snapshotNameData struct {
-	Commit    string
-	Tag       string
-	Timestamp int64
+func getSnapshotName(ctx *context.Context) (string, error) {
+	return tmpl.New(ctx).Apply(ctx.Config.Snapshot.NameTemplate)
 }
 
-func getSnapshotName(ctx *context.Context, tag, commit string) (string, error) {
-	tmpl, err := template.New("snapshot").Parse(ctx.Config.Snapshot.NameTemplate)
-	var out bytes.Buffer
-	if err != nil {
-		return "", err
-	}
-	var data = snapshotNameData{
-		Commit:    commit,
-		Tag:       tag,
-		Timestamp: time.Now().Unix(),
-	}
-	err = tmpl.Execute(&out, data)
-	return out.String(), err
-}
+// looseVersion matches the versions accepted before they were parsed as
+// semantic versions
+var looseVersion = regexp.MustCompile(`^[0-9.]+`)
 
 func validate(ctx *context.Context, commit, tag string) error {
-	out, err := git.Run("status", "--porcelain")
+	out, err := git.Run(ctx, "status", "--porcelain")
 	if strings.TrimSpace(out) != "" || err != nil {
 		return ErrDirty{out}
 	}
 	if ctx.Snapshot {
 		return nil
 	}
-	if !regexp.MustCompile("^[0-9.]+").MatchString(ctx.Version) {
-		return ErrInvalidVersionFormat{ctx.Version}
+	if _, err := semver.ParseTolerant(ctx.Version); err != nil {
+		if !looseVersion.MatchString(ctx.Version) {
+			return ErrInvalidVersionFormat{ctx.Version}
+		}
+		// versions like 1.2.3.4 used to be accepted, so they still are
+		log.WithField("version", ctx.Version).Warn("version is not a semantic version")
 	}
-	_, err = git.Clean(git.Run("describe", "--exact-match", "--tags", "--match", tag))
+	_, err = git.Clean(git.Run(ctx, "describe", "--exact-match", "--tags", "--match", tag))
 	if err != nil {
 		return ErrWrongRef{commit, tag}
 	}
 	return nil
 }
 
-func getInfo() (tag, commit string, err error) {
-	tag, err = git.Clean(git.Run("describe", "--tags", "--abbrev=0"))
+// previousTag returns the tag before the given one, or an empty string if
+// there is none
+func previousTag(ctx *context.Context, tag string) string {
+	if tag == "" {
+		return ""
+	}
+	previous, err := git.LatestTag(ctx, ctx.Config.Monorepo.TagPrefix, tag+"^")
+	if err != nil {
+		return ""
+	}
+	return previous
+}
+
+// remoteURL returns the URL of the `origin` remote, without the credentials
+// it may have, or an empty string if there is no such remote
+func remoteURL(ctx *context.Context) string {
+	out, err := git.Clean(git.Run(ctx, "config", "--get", "remote.origin.url"))
+	if err != nil {
+		return ""
+	}
+	u, err := url.Parse(out)
+	if err != nil || u.Scheme == "" {
+		// e.g. git@github.com:goreleaser/goreleaser.git
+		return out
+	}
+	u.User = nil
+	return u.String()
+}
+
+// commitDate returns the committer date of HEAD, which the builds and
+// archives use instead of the current time so they are reproducible
+func commitDate(ctx *context.Context) (time.Time, error) {
+	out, err := git.Clean(git.Run(ctx, "show", "-s", "--format=%ct", "HEAD"))
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to get the commit date")
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid commit date %s", out)
+	}
+	return time.Unix(secs, 0).UTC(), nil
+}
+
+func getInfo(ctx *context.Context) (tag, commit string, err error) {
+	tag, err = git.LatestTag(ctx, ctx.Config.Monorepo.TagPrefix)
 	if err != nil {
 		log.WithError(err).Info("failed to retrieve current tag")
 	}
-	commit, err = git.Clean(git.Run("show", "--format='%H'", "HEAD"))
+	commit, err = git.Clean(git.Run(ctx, "show", "--format='%H'", "HEAD"))
 	return
 }
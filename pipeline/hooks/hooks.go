@@ -0,0 +1,96 @@
+// Package hooks provides the pipes that run the global before and after
+// hooks of the release.
+package hooks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/runner"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pipeline"
+	"github.com/pkg/errors"
+)
+
+// BeforePipe runs the hooks that should run before the build
+type BeforePipe struct{}
+
+func (BeforePipe) String() string {
+	return "running before hooks"
+}
+
+// ID of the pipe
+func (BeforePipe) ID() string {
+	return "before"
+}
+
+// Run the pipe
+func (BeforePipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.Before.Hooks) == 0 {
+		return pipeline.Skip("no before hooks configured")
+	}
+	return run(ctx, ctx.Config.Before.Hooks)
+}
+
+// AfterPipe runs the hooks that should run at the end of the release
+type AfterPipe struct{}
+
+func (AfterPipe) String() string {
+	return "running after hooks"
+}
+
+// ID of the pipe
+func (AfterPipe) ID() string {
+	return "after"
+}
+
+// Run the pipe
+func (AfterPipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.After.Hooks) == 0 {
+		return pipeline.Skip("no after hooks configured")
+	}
+	return run(ctx, ctx.Config.After.Hooks)
+}
+
+func run(ctx *context.Context, hooks []config.Hook) error {
+	for _, hook := range hooks {
+		if err := runHook(ctx, hook); err != nil {
+			if !hook.IgnoreErrors {
+				return err
+			}
+			log.WithError(err).Warn("ignoring failed hook")
+		}
+	}
+	return nil
+}
+
+func runHook(ctx *context.Context, hook config.Hook) error {
+	cmd, err := tmpl.New(ctx).Apply(hook.Cmd)
+	if err != nil {
+		return errors.Wrapf(err, "invalid hook %s", hook.Cmd)
+	}
+	var args = strings.Fields(cmd)
+	if hook.Shell {
+		args = []string{"sh", "-c", cmd}
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("empty hook")
+	}
+	log.WithField("hook", cmd).Info("running")
+	var env = hook.Env
+	if ctx.Config.Reproducible {
+		env = append([]string{ctx.SourceDateEpoch()}, env...)
+	}
+	out, err := runner.Run(ctx, runner.Cmd{
+		Args: args,
+		Env:  env,
+	})
+	log.WithField("hook", cmd).Debug(string(out))
+	if err != nil {
+		return fmt.Errorf("hook %s failed: %v: %s", cmd, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
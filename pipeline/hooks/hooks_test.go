@@ -0,0 +1,143 @@
+package hooks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescription(t *testing.T) {
+	assert.NotEmpty(t, BeforePipe{}.String())
+	assert.NotEmpty(t, AfterPipe{}.String())
+}
+
+func TestNoHooks(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	testlib.AssertSkipped(t, BeforePipe{}.Run(ctx))
+	testlib.AssertSkipped(t, AfterPipe{}.Run(ctx))
+}
+
+func TestBeforeHooks(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		ProjectName: "foo",
+		Before: config.GlobalHooks{
+			Hooks: []config.Hook{
+				{Cmd: "touch first"},
+				{
+					Cmd:   "echo {{ .ProjectName }} {{ .Version }} {{ .Tag }} {{ .Commit }} $FOO > second",
+					Shell: true,
+					Env:   []string{"FOO=bar"},
+				},
+			},
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0", Commit: "abc"}
+	assert.NoError(t, BeforePipe{}.Run(ctx))
+	_, err := os.Stat(filepath.Join(folder, "first"))
+	assert.NoError(t, err)
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "second"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo 1.0.0 v1.0.0 abc bar\n", string(bts))
+}
+
+func TestHookSourceDateEpoch(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Reproducible: true,
+		Before: config.GlobalHooks{
+			Hooks: []config.Hook{
+				{Cmd: "echo $SOURCE_DATE_EPOCH > date", Shell: true},
+			},
+		},
+	})
+	ctx.Git.CommitDate = time.Unix(1500000000, 0)
+	assert.NoError(t, BeforePipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "date"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1500000000\n", string(bts))
+}
+
+func TestHookFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		After: config.GlobalHooks{
+			Hooks: []config.Hook{
+				{Cmd: "echo oops; exit 1", Shell: true},
+				{Cmd: "touch never"},
+			},
+		},
+	})
+	assert.EqualError(t, AfterPipe{}.Run(ctx), "hook echo oops; exit 1 failed: exit status 1: oops")
+	_, err := ioutil.ReadFile(filepath.Join(folder, "never"))
+	assert.Error(t, err)
+}
+
+func TestHookIgnoreErrors(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		After: config.GlobalHooks{
+			Hooks: []config.Hook{
+				{Cmd: "exit 1", Shell: true, IgnoreErrors: true},
+				{Cmd: "touch after"},
+			},
+		},
+	})
+	assert.NoError(t, AfterPipe{}.Run(ctx))
+	_, err := os.Stat(filepath.Join(folder, "after"))
+	assert.NoError(t, err)
+}
+
+func TestHookInvalidTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Before: config.GlobalHooks{
+			Hooks: []config.Hook{{Cmd: "echo {{ .Tag"}},
+		},
+	})
+	var err = BeforePipe{}.Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid hook echo {{ .Tag")
+}
+
+func TestHookDryRun(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Before: config.GlobalHooks{
+			Hooks: []config.Hook{{Cmd: "touch dry"}},
+		},
+	})
+	ctx.DryRun = true
+	assert.NoError(t, BeforePipe{}.Run(ctx))
+	_, err := ioutil.ReadFile(filepath.Join(folder, "dry"))
+	assert.Error(t, err)
+}
+
+func TestHookNoSourceDateEpoch(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{
+		Before: config.GlobalHooks{
+			Hooks: []config.Hook{
+				{Cmd: "echo \"[$SOURCE_DATE_EPOCH]\" > date", Shell: true},
+			},
+		},
+	})
+	ctx.Git.CommitDate = time.Unix(1500000000, 0)
+	assert.NoError(t, BeforePipe{}.Run(ctx))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, "date"))
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", string(bts))
+}
@@ -0,0 +1,149 @@
+// Package metadata writes machine-readable information about the release
+// and its artifacts to the dist folder.
+package metadata
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/checksum"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// ArtifactsFile is the name of the file listing all artifacts
+	ArtifactsFile = "artifacts.json"
+	// MetadataFile is the name of the file with the release metadata
+	MetadataFile = "metadata.json"
+)
+
+// Artifact is an artifact as written to the artifacts file
+type Artifact struct {
+	artifact.Artifact
+	Target string `json:"target,omitempty"`
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// Metadata holds information about the release
+type Metadata struct {
+	ProjectName string    `json:"project_name"`
+	Tag         string    `json:"tag"`
+	PreviousTag string    `json:"previous_tag,omitempty"`
+	Version     string    `json:"version"`
+	Commit      string    `json:"commit"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at"`
+}
+
+// Write writes the artifacts and metadata files to dist, with the artifacts
+// added to the context so far. It is called after each pipe, so the files
+// are there even if the release fails.
+func Write(ctx *context.Context) error {
+	artifacts, err := collect(ctx)
+	if err != nil {
+		return err
+	}
+	if err := write(filepath.Join(ctx.Config.Dist, ArtifactsFile), artifacts); err != nil {
+		return err
+	}
+	return write(filepath.Join(ctx.Config.Dist, MetadataFile), Metadata{
+		ProjectName: ctx.Config.ProjectName,
+		Tag:         ctx.Git.CurrentTag,
+		PreviousTag: ctx.Git.PreviousTag,
+		Version:     ctx.Version,
+		Commit:      ctx.Git.Commit,
+		StartedAt:   ctx.Date,
+		EndedAt:     time.Now(),
+	})
+}
+
+func collect(ctx *context.Context) ([]Artifact, error) {
+	var list = ctx.Artifacts.List()
+	var result = make([]Artifact, len(list))
+	var g errgroup.Group
+	var sem = make(chan bool, ctx.Parallelism)
+	for i, a := range list {
+		i := i
+		a := a
+		sem <- true
+		g.Go(func() error {
+			defer func() {
+				<-sem
+			}()
+			item, err := describe(ctx, a)
+			result[i] = item
+			return err
+		})
+	}
+	return result, g.Wait()
+}
+
+func describe(ctx *context.Context, a artifact.Artifact) (Artifact, error) {
+	var result = Artifact{
+		Artifact: a,
+		Target:   target(a),
+	}
+	// docker images are not files, so there is nothing to measure
+	if a.Type == artifact.DockerImage || a.Type == artifact.PublishableDockerImage {
+		return result, nil
+	}
+	// relative to dist, so it can be published from another location
+	result.Path = relative(ctx.Config.Dist, a.Path)
+	// nothing was written in dry-run mode
+	if ctx.DryRun {
+		return result, nil
+	}
+	info, err := os.Stat(a.Path)
+	if err != nil {
+		return result, err
+	}
+	result.Size = info.Size()
+	result.SHA256, err = checksum.SHA256(a.Path)
+	return result, err
+}
+
+// relative returns the given path relative to dist, or as it is if it
+// isn't in dist
+func relative(dist, path string) string {
+	absDist, err := filepath.Abs(dist)
+	if err != nil {
+		return path
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absDist, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return rel
+}
+
+func target(a artifact.Artifact) string {
+	if a.Goos == "" {
+		return ""
+	}
+	var parts = []string{a.Goos, a.Goarch}
+	if a.Variant() != "" {
+		parts = append(parts, a.Variant())
+	}
+	return strings.Join(parts, "_")
+}
+
+func write(path string, v interface{}) error {
+	bts, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	log.WithField("file", path).Debug("writing")
+	return ioutil.WriteFile(path, bts, 0644)
+}
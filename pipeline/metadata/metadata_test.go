@@ -0,0 +1,130 @@
+package metadata
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var file = filepath.Join(folder, "foo_linux_arm_6.tar.gz")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("some string"), 0644))
+	var ctx = context.New(config.Project{
+		Dist:        folder,
+		ProjectName: "foo",
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		Commit:     "a1b2c3",
+	}
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name:   "foo_linux_arm_6.tar.gz",
+		Path:   file,
+		Goos:   "linux",
+		Goarch: "arm",
+		Goarm:  "6",
+		Type:   artifact.UploadableArchive,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "foo/bar:v1.0.0",
+		Path: "foo/bar:v1.0.0",
+		Type: artifact.DockerImage,
+	})
+	assert.NoError(t, Write(ctx))
+
+	bts, err := ioutil.ReadFile(filepath.Join(folder, ArtifactsFile))
+	assert.NoError(t, err)
+	var artifacts []Artifact
+	assert.NoError(t, json.Unmarshal(bts, &artifacts))
+	assert.Equal(t, []Artifact{
+		{
+			Artifact: artifact.Artifact{
+				Name:   "foo_linux_arm_6.tar.gz",
+				Path:   "foo_linux_arm_6.tar.gz",
+				Goos:   "linux",
+				Goarch: "arm",
+				Goarm:  "6",
+				Type:   artifact.UploadableArchive,
+			},
+			Target: "linux_arm_6",
+			Size:   11,
+			SHA256: "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc",
+		},
+		{
+			Artifact: artifact.Artifact{
+				Name: "foo/bar:v1.0.0",
+				Path: "foo/bar:v1.0.0",
+				Type: artifact.DockerImage,
+			},
+		},
+	}, artifacts)
+
+	bts, err = ioutil.ReadFile(filepath.Join(folder, MetadataFile))
+	assert.NoError(t, err)
+	var metadata Metadata
+	assert.NoError(t, json.Unmarshal(bts, &metadata))
+	assert.Equal(t, "foo", metadata.ProjectName)
+	assert.Equal(t, "v1.0.0", metadata.Tag)
+	assert.Equal(t, "1.0.0", metadata.Version)
+	assert.Equal(t, "a1b2c3", metadata.Commit)
+	assert.True(t, ctx.Date.Equal(metadata.StartedAt))
+	assert.False(t, metadata.EndedAt.Before(metadata.StartedAt))
+	assert.WithinDuration(t, time.Now(), metadata.EndedAt, time.Minute)
+}
+
+func TestWriteFileNotExist(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var ctx = context.New(config.Project{
+		Dist: folder,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "nope",
+		Path: filepath.Join(folder, "nope"),
+		Type: artifact.Binary,
+	})
+	assert.Error(t, Write(ctx))
+	_, err = os.Stat(filepath.Join(folder, ArtifactsFile))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteChangedFile(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var file = filepath.Join(folder, "foo")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("some string"), 0644))
+	var ctx = context.New(config.Project{
+		Dist: folder,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "foo",
+		Path: file,
+		Type: artifact.Binary,
+	})
+	assert.NoError(t, Write(ctx))
+	// the file is described again once it changes, even with the same size
+	// and modification time, e.g. in reproducible mode
+	info, err := os.Stat(file)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(file, []byte("same string"), 0644))
+	assert.NoError(t, os.Chtimes(file, info.ModTime(), info.ModTime()))
+	assert.NoError(t, Write(ctx))
+	bts, err := ioutil.ReadFile(filepath.Join(folder, ArtifactsFile))
+	assert.NoError(t, err)
+	var artifacts []Artifact
+	assert.NoError(t, json.Unmarshal(bts, &artifacts))
+	assert.Len(t, artifacts, 1)
+	assert.Equal(t, int64(11), artifacts[0].Size)
+	assert.Equal(t, "82ad12ffd7c05b5c035646178b5b636a98299c43f83ba3f5dacae0bcb2dba8db", artifacts[0].SHA256)
+}
@@ -0,0 +1,185 @@
+// Package plugin provides pipes that run external executables, so
+// project-specific steps can be added to the release without a fork.
+//
+// A plugin gets the release context as JSON on its standard input and may
+// print a JSON Output to its standard output.
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/runner"
+	"github.com/goreleaser/goreleaser/pipeline"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// DefaultAfter is the ID of the pipe plugins run after, if none is set
+const DefaultAfter = "release"
+
+// Input is what plugins get on their standard input
+type Input struct {
+	ProjectName string              `json:"project_name"`
+	Config      interface{}         `json:"config"`
+	Git         context.GitInfo     `json:"git"`
+	Version     string              `json:"version"`
+	Snapshot    bool                `json:"snapshot"`
+	Publish     bool                `json:"publish"`
+	DryRun      bool                `json:"dry_run"`
+	Artifacts   []artifact.Artifact `json:"artifacts"`
+}
+
+// Output is what plugins may print to their standard output.
+// Artifacts are added to the context, and a non-empty Skip marks the pipe
+// as skipped with the given reason.
+type Output struct {
+	Artifacts []artifact.Artifact `json:"artifacts,omitempty"`
+	Skip      string              `json:"skip,omitempty"`
+}
+
+// Pipe runs a plugin
+type Pipe struct {
+	plugin config.Plugin
+}
+
+// New returns a pipe that runs the given plugin
+func New(plugin config.Plugin) Pipe {
+	if plugin.Name == "" {
+		plugin.Name = filepath.Base(plugin.Cmd)
+	}
+	return Pipe{plugin: plugin}
+}
+
+func (p Pipe) String() string {
+	return fmt.Sprintf("running %s plugin", p.plugin.Name)
+}
+
+// ID of the pipe, which is the plugin name
+func (p Pipe) ID() string {
+	return p.plugin.Name
+}
+
+// Run the plugin
+func (p Pipe) Run(ctx *context.Context) error {
+	if p.plugin.Cmd == "" {
+		return fmt.Errorf("plugin %s: no cmd set", p.plugin.Name)
+	}
+	input, err := newInput(ctx)
+	if err != nil {
+		return err
+	}
+	bts, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	log.WithField("cmd", p.plugin.Cmd).Info("running")
+	out, err := runner.Output(ctx, runner.Cmd{
+		Args:  append([]string{p.plugin.Cmd}, p.plugin.Args...),
+		Env:   p.plugin.Env,
+		Stdin: bytes.NewReader(bts),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "plugin %s failed", p.plugin.Name)
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil
+	}
+	var output Output
+	if err := json.Unmarshal(out, &output); err != nil {
+		return errors.Wrapf(err, "plugin %s printed invalid output", p.plugin.Name)
+	}
+	for _, a := range output.Artifacts {
+		ctx.Artifacts.Add(a)
+	}
+	if output.Skip != "" {
+		return pipeline.Skip(output.Skip)
+	}
+	return nil
+}
+
+// Insert returns a copy of pipes with a pipe for each plugin, placed right
+// after the pipe it should run after. Plugins whose pipe is not in the list
+// are not inserted, and are returned as missing.
+func Insert(pipes []pipeline.Piper, plugins []config.Plugin) (result []pipeline.Piper, missing []config.Plugin) {
+	var inserted = make([]bool, len(plugins))
+	for _, pipe := range pipes {
+		result = append(result, pipe)
+		id, ok := pipe.(pipeline.Identifier)
+		if !ok {
+			continue
+		}
+		for i, plugin := range plugins {
+			if after(plugin) == id.ID() {
+				result = append(result, New(plugin))
+				inserted[i] = true
+			}
+		}
+	}
+	for i, plugin := range plugins {
+		if !inserted[i] {
+			missing = append(missing, plugin)
+		}
+	}
+	return result, missing
+}
+
+func after(plugin config.Plugin) string {
+	if plugin.After == "" {
+		return DefaultAfter
+	}
+	return plugin.After
+}
+
+func newInput(ctx *context.Context) (Input, error) {
+	cfg, err := toJSON(ctx.Config)
+	if err != nil {
+		return Input{}, err
+	}
+	return Input{
+		ProjectName: ctx.Config.ProjectName,
+		Config:      cfg,
+		Git:         ctx.Git,
+		Version:     ctx.Version,
+		Snapshot:    ctx.Snapshot,
+		Publish:     ctx.Publish,
+		DryRun:      ctx.DryRun,
+		Artifacts:   ctx.Artifacts.List(),
+	}, nil
+}
+
+// toJSON converts the config to something that can be encoded to JSON with
+// the same keys as the YAML config file
+func toJSON(cfg config.Project) (interface{}, error) {
+	bts, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var result interface{}
+	if err := yaml.Unmarshal(bts, &result); err != nil {
+		return nil, err
+	}
+	return stringKeys(result), nil
+}
+
+func stringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		var result = map[string]interface{}{}
+		for k, e := range v {
+			result[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return result
+	case []interface{}:
+		for i, e := range v {
+			v[i] = stringKeys(e)
+		}
+	}
+	return v
+}
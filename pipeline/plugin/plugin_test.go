@@ -0,0 +1,158 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/goreleaser/goreleaser/pipeline"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescription(t *testing.T) {
+	var pipe = New(config.Plugin{Cmd: "./scripts/cdn-upload"})
+	assert.Equal(t, "cdn-upload", pipe.ID())
+	assert.NotEmpty(t, pipe.String())
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var input = filepath.Join(folder, "input.json")
+	var ctx = context.New(config.Project{ProjectName: "foo"})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0", Commit: "abc"}
+	ctx.Version = "1.0.0"
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "foo.tar.gz",
+		Path: "dist/foo.tar.gz",
+		Type: artifact.UploadableArchive,
+	})
+	var pipe = New(writeScript(t, folder, `cat > `+input+`
+echo '{"artifacts":[{"name":"foo.tar.gz","path":"https://cdn.example.com/foo.tar.gz","type":"archive","extra":{"Source":"cdn"}}]}'`))
+	assert.NoError(t, pipe.Run(ctx))
+	var cdn = ctx.Artifacts.Filter(func(a artifact.Artifact) bool {
+		return a.Extra["Source"] == "cdn"
+	}).List()
+	assert.Len(t, cdn, 1)
+	assert.Equal(t, "https://cdn.example.com/foo.tar.gz", cdn[0].Path)
+	assert.Equal(t, artifact.UploadableArchive, cdn[0].Type)
+
+	bts, err := ioutil.ReadFile(input)
+	assert.NoError(t, err)
+	var result map[string]interface{}
+	assert.NoError(t, json.Unmarshal(bts, &result))
+	assert.Equal(t, "foo", result["project_name"])
+	assert.Equal(t, "1.0.0", result["version"])
+	assert.Equal(t, "v1.0.0", result["git"].(map[string]interface{})["current_tag"])
+	assert.Equal(t, "foo", result["config"].(map[string]interface{})["project_name"])
+	assert.Len(t, result["artifacts"], 1)
+}
+
+func TestRunPipeNoOutput(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{})
+	assert.NoError(t, New(writeScript(t, folder, "cat > /dev/null")).Run(ctx))
+	assert.Empty(t, ctx.Artifacts.List())
+}
+
+func TestRunPipeSkip(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = context.New(config.Project{})
+	var err = New(writeScript(t, folder, `echo '{"skip":"nothing to register"}'`)).Run(ctx)
+	testlib.AssertSkipped(t, err)
+	assert.EqualError(t, err, "nothing to register")
+}
+
+func TestRunPipeFails(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var plugin = writeScript(t, folder, "echo 'deploy service is down' >&2; exit 1")
+	plugin.Name = "deploy"
+	var err = New(plugin).Run(context.New(config.Project{}))
+	assert.EqualError(t, err, "plugin deploy failed: exit status 1: deploy service is down")
+	assert.False(t, pipeline.IsSkip(err))
+}
+
+func TestRunPipeInvalidOutput(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var plugin = writeScript(t, folder, "echo 'uploaded!'")
+	plugin.Name = "cdn"
+	var err = New(plugin).Run(context.New(config.Project{}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin cdn printed invalid output")
+}
+
+func TestRunPipeArgsAndEnv(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var plugin = writeScript(t, folder, `echo "{\"skip\":\"$1 $FOO\"}"`)
+	plugin.Args = []string{"hello"}
+	plugin.Env = []string{"FOO=bar"}
+	assert.EqualError(t, New(plugin).Run(context.New(config.Project{})), "hello bar")
+}
+
+func TestRunPipeNoCmd(t *testing.T) {
+	assert.EqualError(
+		t,
+		New(config.Plugin{Name: "foo"}).Run(context.New(config.Project{})),
+		"plugin foo: no cmd set",
+	)
+}
+
+type fakePipe struct {
+	id string
+}
+
+func (p fakePipe) String() string {
+	return p.id
+}
+
+func (fakePipe) Run(ctx *context.Context) error {
+	return nil
+}
+
+func (p fakePipe) ID() string {
+	return p.id
+}
+
+func TestInsert(t *testing.T) {
+	var pipes = []pipeline.Piper{
+		fakePipe{"build"},
+		fakePipe{"archive"},
+		fakePipe{"release"},
+		fakePipe{"brew"},
+	}
+	result, missing := Insert(pipes, []config.Plugin{
+		{Name: "deploy", Cmd: "deploy"},
+		{Name: "cdn", Cmd: "cdn", After: "archive"},
+		{Name: "nope", Cmd: "nope", After: "snapcraft"},
+	})
+	var ids []string
+	for _, pipe := range result {
+		ids = append(ids, pipe.(pipeline.Identifier).ID())
+	}
+	assert.Equal(t, []string{"build", "archive", "cdn", "release", "deploy", "brew"}, ids)
+	assert.Len(t, missing, 1)
+	assert.Equal(t, "nope", missing[0].Name)
+}
+
+func TestInsertNoPlugins(t *testing.T) {
+	var pipes = []pipeline.Piper{fakePipe{"build"}}
+	result, missing := Insert(pipes, nil)
+	assert.Equal(t, pipes, result)
+	assert.Empty(t, missing)
+}
+
+func writeScript(t *testing.T, folder, script string) config.Plugin {
+	var path = filepath.Join(folder, "plugin.sh")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	return config.Plugin{Cmd: path}
+}
@@ -0,0 +1,194 @@
+// Package provenance implements the Pipe interface generating an in-toto
+// statement of how the release was built, as SLSA provenance, whose
+// subjects are the checksummed artifacts.
+package provenance
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/runner"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pipeline"
+)
+
+const (
+	statementType = "https://in-toto.io/Statement/v1"
+	predicateType = "https://slsa.dev/provenance/v1"
+	buildType     = "https://github.com/goreleaser/goreleaser/blob/master/docs/074-provenance.md"
+)
+
+// Pipe for provenance
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "generating the provenance statement"
+}
+
+// ID of the pipe
+func (Pipe) ID() string {
+	return "provenance"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"checksums"}
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	if ctx.Config.Provenance.NameTemplate == "" {
+		ctx.Config.Provenance.NameTemplate = "{{ .ProjectName }}_{{ .Version }}_provenance.intoto.json"
+	}
+	return nil
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if ctx.Config.Provenance.BuilderID == "" {
+		return pipeline.Skip("provenance.builder_id is not configured")
+	}
+	var checksums = ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List()
+	if len(checksums) == 0 {
+		return pipeline.Skip("no checksums to take the subjects from")
+	}
+	name, err := tmpl.New(ctx).Apply(ctx.Config.Provenance.NameTemplate)
+	if err != nil {
+		return err
+	}
+	builderID, err := tmpl.New(ctx).Apply(ctx.Config.Provenance.BuilderID)
+	if err != nil {
+		return err
+	}
+	var result = artifact.Artifact{
+		Type: artifact.Provenance,
+		Name: name,
+		Path: filepath.Join(ctx.Config.Dist, name),
+	}
+	if ctx.DryRun {
+		log.WithField("file", result.Path).Info("dry-run: would write")
+		ctx.Artifacts.Add(result)
+		return nil
+	}
+	subjects, err := subjectsOf(checksums[0].Path)
+	if err != nil {
+		return fmt.Errorf("failed to read the checksums: %v", err)
+	}
+	goVersion, err := goVersion(ctx)
+	if err != nil {
+		return err
+	}
+	bts, err := json.MarshalIndent(statementOf(ctx, subjects, goVersion, builderID), "", "  ")
+	if err != nil {
+		return err
+	}
+	log.WithField("file", result.Path).Info("writing")
+	if err := ioutil.WriteFile(result.Path, bts, 0644); err != nil {
+		return err
+	}
+	ctx.Artifacts.Add(result)
+	return nil
+}
+
+func statementOf(ctx *context.Context, subjects []subject, goVersion, builderID string) statement {
+	var definition = buildDefinition{
+		BuildType: buildType,
+		ExternalParameters: externalParameters{
+			Repository: ctx.Git.URL,
+			Tag:        ctx.Git.CurrentTag,
+			Commit:     ctx.Git.Commit,
+			Version:    ctx.Version,
+			Snapshot:   ctx.Snapshot,
+		},
+		InternalParameters: internalParameters{
+			GoVersion: goVersion,
+			Builds:    binariesOf(ctx),
+		},
+	}
+	if ctx.Git.URL != "" {
+		definition.ResolvedDependencies = []resourceDescriptor{{
+			URI:    sourceURI(ctx.Git.URL, ctx.Git.CurrentTag),
+			Digest: map[string]string{"gitCommit": ctx.Git.Commit},
+		}}
+	}
+	return statement{
+		Type:          statementType,
+		Subject:       subjects,
+		PredicateType: predicateType,
+		Predicate: predicate{
+			BuildDefinition: definition,
+			RunDetails: runDetails{
+				Builder: builder{ID: builderID},
+			},
+		},
+	}
+}
+
+// subjectsOf reads the subjects of the statement from the given checksums
+// file, whose lines are sorted by file name
+func subjectsOf(path string) ([]subject, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+	var result = []subject{}
+	var scanner = bufio.NewScanner(f)
+	for scanner.Scan() {
+		var fields = strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid checksum line: %s", scanner.Text())
+		}
+		result = append(result, subject{
+			Name:   fields[1],
+			Digest: map[string]string{"sha256": fields[0]},
+		})
+	}
+	return result, scanner.Err()
+}
+
+// binariesOf returns how the binaries were built, sorted, so the statement
+// is always the same
+func binariesOf(ctx *context.Context) []binary {
+	var binaries = ctx.Artifacts.Filter(artifact.ByType(artifact.Binary)).List()
+	sort.Slice(binaries, func(i, j int) bool {
+		return binaries[i].Path < binaries[j].Path
+	})
+	var result = []binary{}
+	for _, b := range binaries {
+		var target = b.Goos + "/" + b.Goarch
+		if b.Variant() != "" {
+			target += "/" + b.Variant()
+		}
+		result = append(result, binary{
+			Name:    b.Name,
+			Target:  target,
+			Command: b.Extra["Cmd"],
+			Env:     b.Extra["Env"],
+		})
+	}
+	return result
+}
+
+// goVersion returns the version of the go toolchain, e.g. go1.10
+func goVersion(ctx *context.Context) (string, error) {
+	out, err := runner.Output(ctx, runner.Cmd{Args: []string{"go", "version"}})
+	if err != nil {
+		return "", fmt.Errorf("failed to get the go version: %v", err)
+	}
+	// go version go1.10 linux/amd64
+	var fields = strings.Fields(string(out))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("invalid go version: %s", string(out))
+	}
+	return fields[2], nil
+}
@@ -0,0 +1,181 @@
+package provenance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescription(t *testing.T) {
+	assert.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.Equal(
+		t,
+		"{{ .ProjectName }}_{{ .Version }}_provenance.intoto.json",
+		ctx.Config.Provenance.NameTemplate,
+	)
+}
+
+func TestRunPipeNotConfigured(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestRunPipeNoChecksums(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Provenance: config.Provenance{BuilderID: "https://ci.example.com"},
+	})
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
+func newContext(t *testing.T, folder string) *context.Context {
+	var ctx = context.New(config.Project{
+		ProjectName: "proj",
+		Dist:        folder,
+		Provenance: config.Provenance{
+			BuilderID: "https://ci.example.com/{{ .Tag }}",
+		},
+	})
+	ctx.Version = "1.0.0"
+	ctx.Git = context.GitInfo{
+		CurrentTag: "v1.0.0",
+		Commit:     "a1b2c3",
+		URL:        "https://github.com/goreleaser/goreleaser.git",
+	}
+	var checksums = filepath.Join(folder, "checksums.txt")
+	assert.NoError(t, ioutil.WriteFile(checksums, []byte(
+		"61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc  proj_linux_amd64.tar.gz\n"+
+			"8c6ec3d0e1d9ad87a5b2e13a8a47a1e8f1c0b5b9f4fa7e2e6cbbd7e8eaf3d6f0  proj_windows_amd64.zip\n",
+	), 0644))
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type: artifact.Checksum,
+		Name: "checksums.txt",
+		Path: checksums,
+	})
+	for _, goos := range []string{"windows", "linux"} {
+		ctx.Artifacts.Add(artifact.Artifact{
+			Type:    artifact.Binary,
+			Name:    "proj",
+			Path:    filepath.Join(folder, goos, "proj"),
+			Goos:    goos,
+			Goarch:  "amd64",
+			Goamd64: "v2",
+			Extra: map[string]string{
+				"Cmd": "go build -o " + goos + "/proj .",
+				"Env": "GOOS=" + goos + " GOARCH=amd64 GOARM= GOAMD64=v2",
+			},
+		})
+	}
+	assert.NoError(t, Pipe{}.Default(ctx))
+	return ctx
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = newContext(t, folder)
+	assert.NoError(t, Pipe{}.Run(ctx))
+
+	var provenances = ctx.Artifacts.Filter(artifact.ByType(artifact.Provenance)).List()
+	assert.Len(t, provenances, 1)
+	assert.Equal(t, "proj_1.0.0_provenance.intoto.json", provenances[0].Name)
+	bts, err := ioutil.ReadFile(provenances[0].Path)
+	assert.NoError(t, err)
+	var s statement
+	assert.NoError(t, json.Unmarshal(bts, &s))
+	assert.Equal(t, "https://in-toto.io/Statement/v1", s.Type)
+	assert.Equal(t, "https://slsa.dev/provenance/v1", s.PredicateType)
+	assert.Equal(t, []subject{
+		{
+			Name:   "proj_linux_amd64.tar.gz",
+			Digest: map[string]string{"sha256": "61d034473102d7dac305902770471fd50f4c5b26f6831a56dd90b5184b3c30fc"},
+		},
+		{
+			Name:   "proj_windows_amd64.zip",
+			Digest: map[string]string{"sha256": "8c6ec3d0e1d9ad87a5b2e13a8a47a1e8f1c0b5b9f4fa7e2e6cbbd7e8eaf3d6f0"},
+		},
+	}, s.Subject)
+
+	var definition = s.Predicate.BuildDefinition
+	assert.Equal(t, externalParameters{
+		Repository: "https://github.com/goreleaser/goreleaser.git",
+		Tag:        "v1.0.0",
+		Commit:     "a1b2c3",
+		Version:    "1.0.0",
+	}, definition.ExternalParameters)
+	assert.Equal(t, []resourceDescriptor{{
+		URI:    "git+https://github.com/goreleaser/goreleaser.git@refs/tags/v1.0.0",
+		Digest: map[string]string{"gitCommit": "a1b2c3"},
+	}}, definition.ResolvedDependencies)
+	assert.Contains(t, definition.InternalParameters.GoVersion, "go")
+	assert.Equal(t, []binary{
+		{
+			Name:    "proj",
+			Target:  "linux/amd64/v2",
+			Command: "go build -o linux/proj .",
+			Env:     "GOOS=linux GOARCH=amd64 GOARM= GOAMD64=v2",
+		},
+		{
+			Name:    "proj",
+			Target:  "windows/amd64/v2",
+			Command: "go build -o windows/proj .",
+			Env:     "GOOS=windows GOARCH=amd64 GOARM= GOAMD64=v2",
+		},
+	}, definition.InternalParameters.Builds)
+	assert.Equal(t, "https://ci.example.com/v1.0.0", s.Predicate.RunDetails.Builder.ID)
+
+	// the statement is the same when generated again
+	assert.NoError(t, Pipe{}.Run(ctx))
+	again, err := ioutil.ReadFile(provenances[0].Path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(bts), string(again))
+}
+
+func TestRunPipeDryRun(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = newContext(t, folder)
+	ctx.DryRun = true
+	assert.NoError(t, Pipe{}.Run(ctx))
+	var provenances = ctx.Artifacts.Filter(artifact.ByType(artifact.Provenance)).List()
+	assert.Len(t, provenances, 1)
+	_, err := ioutil.ReadFile(provenances[0].Path)
+	assert.Error(t, err)
+}
+
+func TestRunPipeInvalidChecksums(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = newContext(t, folder)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(folder, "checksums.txt"), []byte("nope\n"), 0644))
+	assert.EqualError(t, Pipe{}.Run(ctx), "failed to read the checksums: invalid checksum line: nope")
+}
+
+func TestRunPipeInvalidBuilderID(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var ctx = newContext(t, folder)
+	ctx.Config.Provenance.BuilderID = "{{ .Nope }"
+	assert.Error(t, Pipe{}.Run(ctx))
+}
+
+func TestSourceURI(t *testing.T) {
+	for url, expected := range map[string]string{
+		"https://github.com/goreleaser/goreleaser.git": "git+https://github.com/goreleaser/goreleaser.git@refs/tags/v1.0.0",
+		"git@github.com:goreleaser/goreleaser.git":     "git+ssh://git@github.com/goreleaser/goreleaser.git@refs/tags/v1.0.0",
+		"git+ssh://github.com/goreleaser/goreleaser":   "git+ssh://github.com/goreleaser/goreleaser@refs/tags/v1.0.0",
+	} {
+		assert.Equal(t, expected, sourceURI(url, "v1.0.0"), url)
+	}
+	assert.Equal(t, "git+https://example.com/repo", sourceURI("https://example.com/repo", ""))
+}
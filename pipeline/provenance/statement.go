@@ -0,0 +1,83 @@
+package provenance
+
+import (
+	"strings"
+)
+
+// in-toto statement v1, see https://github.com/in-toto/attestation/blob/main/spec/v1/statement.md
+type statement struct {
+	Type          string    `json:"_type"`
+	Subject       []subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     predicate `json:"predicate"`
+}
+
+type subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// SLSA provenance v1, see https://slsa.dev/spec/v1.0/provenance
+type predicate struct {
+	BuildDefinition buildDefinition `json:"buildDefinition"`
+	RunDetails      runDetails      `json:"runDetails"`
+}
+
+type buildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   externalParameters   `json:"externalParameters"`
+	InternalParameters   internalParameters   `json:"internalParameters"`
+	ResolvedDependencies []resourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// externalParameters are the ones the release was asked for
+type externalParameters struct {
+	Repository string `json:"repository,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Commit     string `json:"commit"`
+	Version    string `json:"version"`
+	Snapshot   bool   `json:"snapshot,omitempty"`
+}
+
+// internalParameters are the ones goreleaser resolved
+type internalParameters struct {
+	GoVersion string   `json:"goVersion"`
+	Builds    []binary `json:"builds"`
+}
+
+// binary is how a binary was built
+type binary struct {
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+	Command string `json:"command"`
+	Env     string `json:"env"`
+}
+
+type resourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+type runDetails struct {
+	Builder builder `json:"builder"`
+}
+
+type builder struct {
+	ID string `json:"id"`
+}
+
+// sourceURI returns the URI of the given git repository at the given tag,
+// e.g. git+https://github.com/goreleaser/goreleaser@refs/tags/v1.0.0
+func sourceURI(url, tag string) string {
+	if !strings.Contains(url, "://") {
+		// e.g. git@github.com:goreleaser/goreleaser.git
+		url = "ssh://" + strings.Replace(url, ":", "/", 1)
+	}
+	if !strings.HasPrefix(url, "git+") {
+		url = "git+" + url
+	}
+	if tag == "" {
+		return url
+	}
+	return url + "@refs/tags/" + tag
+}
@@ -2,10 +2,15 @@ package release
 
 import (
 	"bytes"
-	"os/exec"
-	"text/template"
+	"fmt"
+	"io/ioutil"
 
+	"github.com/goreleaser/goreleaser/checksum"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/runner"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/pkg/errors"
 )
 
 const bodyTemplate = `{{ .ReleaseNotes }}
@@ -22,9 +27,24 @@ const bodyTemplate = `{{ .ReleaseNotes }}
 Automated with [GoReleaser](https://github.com/goreleaser)
 Built with {{ .GoVersion }}`
 
+// bodyArtifact is an artifact as seen by the release body template
+type bodyArtifact struct {
+	Name   string
+	Type   string
+	Goos   string
+	Goarch string
+	Goarm  string
+	path   string
+}
+
+// Checksum returns the SHA256 of the artifact. It is only calculated if
+// the template uses it.
+func (a bodyArtifact) Checksum() (string, error) {
+	return checksum.SHA256(a.path)
+}
+
 func describeBody(ctx *context.Context) (bytes.Buffer, error) {
-	/* #nosec */
-	bts, err := exec.Command("go", "version").CombinedOutput()
+	bts, err := runner.Query(ctx, runner.Cmd{Args: []string{"go", "version"}})
 	if err != nil {
 		return bytes.Buffer{}, err
 	}
@@ -33,14 +53,69 @@ func describeBody(ctx *context.Context) (bytes.Buffer, error) {
 
 func describeBodyVersion(ctx *context.Context, version string) (bytes.Buffer, error) {
 	var out bytes.Buffer
-	var template = template.Must(template.New("release").Parse(bodyTemplate))
-	err := template.Execute(&out, struct {
-		ReleaseNotes, GoVersion string
-		DockerImages            []string
-	}{
-		ReleaseNotes: ctx.ReleaseNotes,
-		GoVersion:    version,
-		DockerImages: ctx.Dockers,
-	})
+	body, err := loadBodyTemplate(ctx)
+	if err != nil {
+		return out, err
+	}
+	var dockers []string
+	for _, a := range ctx.Artifacts.Filter(artifact.ByType(artifact.DockerImage)).List() {
+		dockers = append(dockers, a.Name)
+	}
+	var artifacts []bodyArtifact
+	for _, a := range uploadable(ctx) {
+		artifacts = append(artifacts, bodyArtifact{
+			Name:   a.Name,
+			Type:   a.Type.String(),
+			Goos:   a.Goos,
+			Goarch: a.Goarch,
+			Goarm:  a.Goarm,
+			path:   a.Path,
+		})
+	}
+	s, err := tmpl.New(ctx).WithFields(tmpl.Fields{
+		"ReleaseNotes": ctx.ReleaseNotes,
+		"GoVersion":    version,
+		"DockerImages": dockers,
+		"Artifacts":    artifacts,
+		"PreviousTag":  ctx.Git.PreviousTag,
+		"CompareURL":   compareURL(ctx),
+	}).Apply(body)
+	out.WriteString(s)
 	return out, err
 }
+
+// loadBodyTemplate returns the configured body template, read from a file
+// if release.body_template_file is set
+func loadBodyTemplate(ctx *context.Context) (string, error) {
+	var release = ctx.Config.Release
+	if release.BodyTemplateFile != "" {
+		bts, err := ioutil.ReadFile(release.BodyTemplateFile)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read release body template")
+		}
+		return string(bts), nil
+	}
+	if release.BodyTemplate != "" {
+		return release.BodyTemplate, nil
+	}
+	return bodyTemplate, nil
+}
+
+// compareURL returns the GitHub URL comparing the previous and the current
+// tag, or an empty string if there is no previous tag
+func compareURL(ctx *context.Context) string {
+	if ctx.Git.PreviousTag == "" {
+		return ""
+	}
+	var url = "https://github.com"
+	if ctx.Config.GitHubURLs.Download != "" {
+		url = ctx.Config.GitHubURLs.Download
+	}
+	return fmt.Sprintf(
+		"%s/%s/compare/%s...%s",
+		url,
+		ctx.Config.Release.GitHub.String(),
+		ctx.Git.PreviousTag,
+		ctx.Git.CurrentTag,
+	)
+}
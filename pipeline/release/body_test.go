@@ -3,21 +3,28 @@ package release
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestDescribeBody(t *testing.T) {
 	var changelog = "\nfeature1: description\nfeature2: other description"
-	var ctx = &context.Context{
-		ReleaseNotes: changelog,
-		Dockers: []string{
-			"goreleaser/goreleaser:0.40.0",
-			"goreleaser/goreleaser:latest",
-			"goreleaser/godownloader:v0.1.0",
-		},
+	var ctx = context.New(config.Project{})
+	ctx.ReleaseNotes = changelog
+	for _, d := range []string{
+		"goreleaser/goreleaser:0.40.0",
+		"goreleaser/goreleaser:latest",
+		"goreleaser/godownloader:v0.1.0",
+	} {
+		ctx.Artifacts.Add(artifact.Artifact{
+			Name: d,
+			Type: artifact.DockerImage,
+		})
 	}
 	out, err := describeBodyVersion(ctx, "go version go1.9 darwin/amd64")
 	assert.NoError(t, err)
@@ -31,9 +38,8 @@ func TestDescribeBody(t *testing.T) {
 
 func TestDescribeBodyNoDockerImagesNoBrews(t *testing.T) {
 	var changelog = "\nfeature1: description\nfeature2: other description"
-	var ctx = &context.Context{
-		ReleaseNotes: changelog,
-	}
+	var ctx = context.New(config.Project{})
+	ctx.ReleaseNotes = changelog
 	out, err := describeBodyVersion(ctx, "go version go1.9 darwin/amd64")
 	assert.NoError(t, err)
 
@@ -44,11 +50,84 @@ func TestDescribeBodyNoDockerImagesNoBrews(t *testing.T) {
 	assert.Equal(t, string(bts), out.String())
 }
 
+func TestDescribeBodyTemplate(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var path = filepath.Join(folder, "bin.tar.gz")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("bin"), 0644))
+	var ctx = context.New(config.Project{
+		ProjectName: "bin",
+		Release: config.Release{
+			GitHub: config.Repo{Owner: "goreleaser", Name: "bin"},
+			BodyTemplate: `{{ .ReleaseNotes }}
+Compare: {{ .CompareURL }}
+Install: go get github.com/goreleaser/bin@{{ .Tag }}
+{{ range .Artifacts }}| {{ .Name }} | {{ .Checksum }} |
+{{ end }}`,
+		},
+	})
+	ctx.ReleaseNotes = "## Changelog"
+	ctx.Git = context.GitInfo{CurrentTag: "v1.1.0", PreviousTag: "v1.0.0"}
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "bin.tar.gz",
+		Path: path,
+		Type: artifact.UploadableArchive,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "bin",
+		Path: "/nope/bin",
+		Type: artifact.Binary,
+	})
+	out, err := describeBodyVersion(ctx, "go version go1.9 darwin/amd64")
+	assert.NoError(t, err)
+	assert.Equal(t, `## Changelog
+Compare: https://github.com/goreleaser/bin/compare/v1.0.0...v1.1.0
+Install: go get github.com/goreleaser/bin@v1.1.0
+| bin.tar.gz | 51a1f05af85e342e3c849b47d387086476282d5f50dc240c19216d6edfb1eb5a |
+`, out.String())
+}
+
+func TestDescribeBodyTemplateFile(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	var path = filepath.Join(folder, "body.md")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{{ .ProjectName }} {{ .PreviousTag }}{{ .CompareURL }}"), 0644))
+	var ctx = context.New(config.Project{
+		ProjectName: "bin",
+		Release: config.Release{
+			BodyTemplateFile: path,
+		},
+	})
+	out, err := describeBodyVersion(ctx, "go version go1.9 darwin/amd64")
+	assert.NoError(t, err)
+	assert.Equal(t, "bin ", out.String())
+}
+
+func TestDescribeBodyTemplateFileNotFound(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			BodyTemplateFile: "/nope/body.md",
+		},
+	})
+	_, err := describeBodyVersion(ctx, "go version go1.9 darwin/amd64")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read release body template")
+}
+
+func TestDescribeBodyInvalidTemplate(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			BodyTemplate: "{{ .Nope }}",
+		},
+	})
+	_, err := describeBodyVersion(ctx, "go version go1.9 darwin/amd64")
+	assert.Error(t, err)
+}
+
 func TestDontEscapeHTML(t *testing.T) {
 	var changelog = "<h1>test</h1>"
-	var ctx = &context.Context{
-		ReleaseNotes: changelog,
-	}
+	var ctx = context.New(config.Project{})
+	ctx.ReleaseNotes = changelog
 	out, err := describeBody(ctx)
 	assert.NoError(t, err)
 	assert.Contains(t, out.String(), changelog)
@@ -60,9 +139,8 @@ func TestGoVersionFails(t *testing.T) {
 		assert.NoError(t, os.Setenv("PATH", path))
 	}()
 	assert.NoError(t, os.Setenv("PATH", ""))
-	var ctx = &context.Context{
-		ReleaseNotes: "changelog",
-	}
+	var ctx = context.New(config.Project{})
+	ctx.ReleaseNotes = "changelog"
 	_, err := describeBody(ctx)
 	assert.Error(t, err)
 }
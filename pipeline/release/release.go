@@ -3,13 +3,15 @@
 package release
 
 import (
+	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/client"
 	"github.com/goreleaser/goreleaser/pipeline"
+	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -20,6 +22,11 @@ func (Pipe) String() string {
 	return "releasing to GitHub"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "release"
+}
+
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
 	c, err := client.NewGitHub(ctx)
@@ -37,7 +44,7 @@ func (Pipe) Default(ctx *context.Context) error {
 	if ctx.Config.Release.GitHub.Name != "" {
 		return nil
 	}
-	repo, err := remoteRepo()
+	repo, err := remoteRepo(ctx)
 	if err != nil {
 		return err
 	}
@@ -56,33 +63,114 @@ func doRun(ctx *context.Context, c client.Client) error {
 	if err != nil {
 		return err
 	}
-	releaseID, err := c.CreateRelease(ctx, body.String())
+	var artifacts = uploadable(ctx)
+	if ctx.DryRun {
+		log.Debugf("dry-run: release body: \n%s", body.String())
+		for _, a := range artifacts {
+			log.WithField("file", a.Path).WithField("name", a.Name).Info("dry-run: would upload to release")
+		}
+		return nil
+	}
+	release, err := c.CreateRelease(ctx, body.String())
 	if err != nil {
 		return err
 	}
+	if release.Draft {
+		ctx.UndoRelease("delete release "+ctx.Git.CurrentTag, func(ctx *context.Context) error {
+			return c.DeleteRelease(ctx, release.ID)
+		})
+	}
 	var g errgroup.Group
 	sem := make(chan bool, ctx.Parallelism)
-	for _, artifact := range ctx.Artifacts {
+	for _, a := range artifacts {
 		sem <- true
-		artifact := artifact
+		a := a
 		g.Go(func() error {
 			defer func() {
 				<-sem
 			}()
-			return upload(ctx, c, releaseID, artifact)
+			return upload(ctx, c, release, a)
 		})
 	}
-	return g.Wait()
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if err := verify(ctx, c, release.ID, artifacts); err != nil {
+		return err
+	}
+	if ctx.Config.Release.Draft && release.Draft {
+		return nil
+	}
+	if release.Draft {
+		if err := c.PublishRelease(ctx, release.ID); err != nil {
+			return err
+		}
+	}
+	// the release is public, so a failure later on, e.g. in an after hook,
+	// must not delete it, but still reverts the other pipes
+	ctx.Published()
+	return nil
 }
 
-func upload(ctx *context.Context, c client.Client, releaseID int, artifact string) error {
-	var path = filepath.Join(ctx.Config.Dist, artifact)
-	file, err := os.Open(path)
+// uploadable returns the artifacts that are uploaded to the release
+func uploadable(ctx *context.Context) []artifact.Artifact {
+	return ctx.Artifacts.Filter(
+		artifact.Or(
+			artifact.ByType(artifact.UploadableArchive),
+			artifact.ByType(artifact.UploadableBinary),
+			artifact.ByType(artifact.Checksum),
+			artifact.ByType(artifact.Signature),
+			artifact.ByType(artifact.LinuxPackage),
+			artifact.ByType(artifact.Snap),
+			artifact.ByType(artifact.SBOM),
+			artifact.ByType(artifact.Provenance),
+		),
+	).List()
+}
+
+func upload(ctx *context.Context, c client.Client, release client.Release, a artifact.Artifact) error {
+	file, err := os.Open(a.Path)
 	if err != nil {
 		return err
 	}
 	defer func() { _ = file.Close() }()
-	_, name := filepath.Split(path)
-	log.WithField("file", file.Name()).WithField("name", name).Info("uploading to release")
-	return c.Upload(ctx, releaseID, name, file)
+	log.WithField("file", file.Name()).WithField("name", a.Name).Info("uploading to release")
+	assetID, err := c.Upload(ctx, release.ID, a.Name, file)
+	if err != nil {
+		return err
+	}
+	// assets of a draft release are deleted with the release itself
+	if !release.Draft {
+		ctx.UndoRelease("delete release asset "+a.Name, func(ctx *context.Context) error {
+			return c.DeleteAsset(ctx, assetID)
+		})
+	}
+	return nil
+}
+
+// verify checks that all the artifacts were uploaded to the release, with
+// the same size as on disk
+func verify(ctx *context.Context, c client.Client, releaseID int, artifacts []artifact.Artifact) error {
+	assets, err := c.ListAssets(ctx, releaseID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list release assets")
+	}
+	var sizes = map[string]int64{}
+	for _, asset := range assets {
+		sizes[asset.Name] = asset.Size
+	}
+	for _, a := range artifacts {
+		stat, err := os.Stat(a.Path)
+		if err != nil {
+			return err
+		}
+		size, ok := sizes[a.Name]
+		if !ok {
+			return fmt.Errorf("release asset %s is missing", a.Name)
+		}
+		if size != stat.Size() {
+			return fmt.Errorf("release asset %s has %d bytes, expected %d", a.Name, size, stat.Size())
+		}
+	}
+	return nil
 }
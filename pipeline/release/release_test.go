@@ -6,10 +6,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/client"
 	"github.com/goreleaser/goreleaser/internal/testlib"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,14 +40,113 @@ func TestRunPipe(t *testing.T) {
 	var ctx = context.New(config)
 	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
 	ctx.Publish = true
-	ctx.AddArtifact(tarfile.Name())
-	ctx.AddArtifact(debfile.Name())
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: tarfile.Name(),
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type: artifact.LinuxPackage,
+		Name: "bin.deb",
+		Path: debfile.Name(),
+	})
 	client := &DummyClient{}
 	assert.NoError(t, doRun(ctx, client))
 	assert.True(t, client.CreatedRelease)
 	assert.True(t, client.UploadedFile)
 	assert.Contains(t, client.UploadedFileNames, "bin.deb")
 	assert.Contains(t, client.UploadedFileNames, "bin.tar.gz")
+	assert.True(t, client.PublishedRelease)
+	// the release is public, so it isn't rolled back anymore
+	assert.Empty(t, ctx.UndoActions())
+}
+
+func TestRunPipeDraft(t *testing.T) {
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			Draft: true,
+		},
+	})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Publish = true
+	client := &DummyClient{}
+	assert.NoError(t, doRun(ctx, client))
+	assert.True(t, client.CreatedRelease)
+	assert.False(t, client.PublishedRelease)
+	// the draft isn't public, so it is still rolled back
+	assert.Len(t, ctx.UndoActions(), 1)
+}
+
+func TestRunPipeExistingRelease(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	tarfile, err := os.Create(filepath.Join(folder, "bin.tar.gz"))
+	assert.NoError(t, err)
+	var ctx = context.New(config.Project{})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Publish = true
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: tarfile.Name(),
+	})
+	client := &DummyClient{
+		ExistingRelease: true,
+	}
+	assert.NoError(t, doRun(ctx, client))
+	assert.False(t, client.PublishedRelease)
+	assert.Empty(t, ctx.UndoActions())
+}
+
+func TestRunPipeExistingReleaseVerifyFailure(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	tarfile, err := os.Create(filepath.Join(folder, "bin.tar.gz"))
+	assert.NoError(t, err)
+	var ctx = context.New(config.Project{})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Publish = true
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: tarfile.Name(),
+	})
+	client := &DummyClient{
+		ExistingRelease: true,
+		CorruptUploads:  true,
+	}
+	assert.Error(t, doRun(ctx, client))
+	var undo = ctx.UndoActions()
+	assert.Len(t, undo, 1)
+	assert.Equal(t, "delete release asset bin.tar.gz", undo[0].Description)
+	assert.NoError(t, undo[0].Func(ctx))
+	assert.Equal(t, []string{"bin.tar.gz"}, client.DeletedAssets)
+	assert.False(t, client.DeletedRelease)
+}
+
+func TestRunPipeVerifyFailure(t *testing.T) {
+	folder, err := ioutil.TempDir("", "goreleasertest")
+	assert.NoError(t, err)
+	tarfile, err := os.Create(filepath.Join(folder, "bin.tar.gz"))
+	assert.NoError(t, err)
+	var ctx = context.New(config.Project{})
+	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
+	ctx.Publish = true
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: tarfile.Name(),
+	})
+	client := &DummyClient{
+		CorruptUploads: true,
+	}
+	assert.EqualError(t, doRun(ctx, client), "release asset bin.tar.gz has 1 bytes, expected 0")
+	assert.False(t, client.PublishedRelease)
+	var undo = ctx.UndoActions()
+	assert.Len(t, undo, 1)
+	assert.Equal(t, "delete release v1.0.0", undo[0].Description)
+	assert.NoError(t, undo[0].Func(ctx))
+	assert.True(t, client.DeletedRelease)
 }
 
 func TestRunPipeReleaseCreationFailed(t *testing.T) {
@@ -79,7 +181,11 @@ func TestRunPipeWithFileThatDontExist(t *testing.T) {
 	var ctx = context.New(config)
 	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
 	ctx.Publish = true
-	ctx.AddArtifact("this-file-wont-exist-hopefully")
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: "/nope/nope/nope",
+	})
 	client := &DummyClient{}
 	assert.Error(t, doRun(ctx, client))
 	assert.True(t, client.CreatedRelease)
@@ -102,20 +208,25 @@ func TestRunPipeUploadFailure(t *testing.T) {
 	var ctx = context.New(config)
 	ctx.Git = context.GitInfo{CurrentTag: "v1.0.0"}
 	ctx.Publish = true
-	ctx.AddArtifact(tarfile.Name())
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type: artifact.UploadableArchive,
+		Name: "bin.tar.gz",
+		Path: tarfile.Name(),
+	})
 	client := &DummyClient{
 		FailToUpload: true,
 	}
 	assert.Error(t, doRun(ctx, client))
 	assert.True(t, client.CreatedRelease)
 	assert.False(t, client.UploadedFile)
+	assert.False(t, client.PublishedRelease)
+	assert.Len(t, ctx.UndoActions(), 1)
 }
 
 func TestSkipPublish(t *testing.T) {
-	var ctx = &context.Context{
-		Publish:     false,
-		Parallelism: 1,
-	}
+	var ctx = context.New(config.Project{})
+	ctx.Publish = false
+	ctx.Parallelism = 1
 	client := &DummyClient{}
 	testlib.AssertSkipped(t, doRun(ctx, client))
 	assert.False(t, client.CreatedRelease)
@@ -128,9 +239,7 @@ func TestDefault(t *testing.T) {
 	testlib.GitInit(t)
 	testlib.GitRemoteAdd(t, "git@github.com:goreleaser/goreleaser.git")
 
-	var ctx = &context.Context{
-		Config: config.Project{},
-	}
+	var ctx = context.New(config.Project{})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Equal(t, "goreleaser", ctx.Config.Release.GitHub.Name)
 	assert.Equal(t, "goreleaser", ctx.Config.Release.GitHub.Owner)
@@ -142,16 +251,14 @@ func TestDefaultFilled(t *testing.T) {
 	testlib.GitInit(t)
 	testlib.GitRemoteAdd(t, "git@github.com:goreleaser/goreleaser.git")
 
-	var ctx = &context.Context{
-		Config: config.Project{
-			Release: config.Release{
-				GitHub: config.Repo{
-					Name:  "foo",
-					Owner: "bar",
-				},
+	var ctx = context.New(config.Project{
+		Release: config.Release{
+			GitHub: config.Repo{
+				Name:  "foo",
+				Owner: "bar",
 			},
 		},
-	}
+	})
 	assert.NoError(t, Pipe{}.Default(ctx))
 	assert.Equal(t, "foo", ctx.Config.Release.GitHub.Name)
 	assert.Equal(t, "bar", ctx.Config.Release.GitHub.Owner)
@@ -161,9 +268,7 @@ func TestDefaultNotAGitRepo(t *testing.T) {
 	_, back := testlib.Mktmp(t)
 	defer back()
 	testlib.GitInit(t)
-	var ctx = &context.Context{
-		Config: config.Project{},
-	}
+	var ctx = context.New(config.Project{})
 	assert.Error(t, Pipe{}.Default(ctx))
 	assert.Empty(t, ctx.Config.Release.GitHub.String())
 }
@@ -171,9 +276,7 @@ func TestDefaultNotAGitRepo(t *testing.T) {
 func TestDefaultGitRepoWithoutRemote(t *testing.T) {
 	_, back := testlib.Mktmp(t)
 	defer back()
-	var ctx = &context.Context{
-		Config: config.Project{},
-	}
+	var ctx = context.New(config.Project{})
 	assert.Error(t, Pipe{}.Default(ctx))
 	assert.Empty(t, ctx.Config.Release.GitHub.String())
 }
@@ -181,28 +284,73 @@ func TestDefaultGitRepoWithoutRemote(t *testing.T) {
 type DummyClient struct {
 	FailToCreateRelease bool
 	FailToUpload        bool
+	CorruptUploads      bool
+	ExistingRelease     bool
 	CreatedRelease      bool
+	PublishedRelease    bool
+	DeletedRelease      bool
 	UploadedFile        bool
 	UploadedFileNames   []string
+	DeletedAssets       []string
+	assets              []client.Asset
+	lock                sync.Mutex
 }
 
-func (client *DummyClient) CreateRelease(ctx *context.Context, body string) (releaseID int, err error) {
-	if client.FailToCreateRelease {
-		return 0, errors.New("release failed")
+func (c *DummyClient) CreateRelease(ctx *context.Context, body string) (client.Release, error) {
+	if c.FailToCreateRelease {
+		return client.Release{}, errors.New("release failed")
 	}
-	client.CreatedRelease = true
-	return
+	c.CreatedRelease = true
+	return client.Release{ID: 1, Draft: !c.ExistingRelease}, nil
+}
+
+func (c *DummyClient) PublishRelease(ctx *context.Context, releaseID int) error {
+	c.PublishedRelease = true
+	return nil
+}
+
+func (c *DummyClient) DeleteRelease(ctx *context.Context, releaseID int) error {
+	c.DeletedRelease = true
+	return nil
+}
+
+func (c *DummyClient) GetFile(ctx *context.Context, path string) ([]byte, bool, error) {
+	return nil, false, nil
 }
 
-func (client *DummyClient) CreateFile(ctx *context.Context, content bytes.Buffer, path string) (err error) {
-	return
+func (c *DummyClient) CreateFile(ctx *context.Context, content bytes.Buffer, path string) error {
+	return nil
 }
 
-func (client *DummyClient) Upload(ctx *context.Context, releaseID int, name string, file *os.File) (err error) {
-	if client.FailToUpload {
-		return errors.New("upload failed")
+func (c *DummyClient) DeleteFile(ctx *context.Context, path string) error {
+	return nil
+}
+
+func (c *DummyClient) Upload(ctx *context.Context, releaseID int, name string, file *os.File) (int, error) {
+	if c.FailToUpload {
+		return 0, errors.New("upload failed")
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		return 0, err
 	}
-	client.UploadedFile = true
-	client.UploadedFileNames = append(client.UploadedFileNames, name)
-	return
+	var size = stat.Size()
+	if c.CorruptUploads {
+		size++
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.UploadedFile = true
+	c.UploadedFileNames = append(c.UploadedFileNames, name)
+	c.assets = append(c.assets, client.Asset{ID: len(c.assets), Name: name, Size: size})
+	return len(c.assets) - 1, nil
+}
+
+func (c *DummyClient) ListAssets(ctx *context.Context, releaseID int) ([]client.Asset, error) {
+	return c.assets, nil
+}
+
+func (c *DummyClient) DeleteAsset(ctx *context.Context, assetID int) error {
+	c.DeletedAssets = append(c.DeletedAssets, c.assets[assetID].Name)
+	return nil
 }
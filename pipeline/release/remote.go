@@ -4,16 +4,17 @@ import (
 	"strings"
 
 	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
 	"github.com/goreleaser/goreleaser/internal/git"
 	"github.com/pkg/errors"
 )
 
 // remoteRepo gets the repo name from the Git config.
-func remoteRepo() (result config.Repo, err error) {
-	if !git.IsRepo() {
+func remoteRepo(ctx *context.Context) (result config.Repo, err error) {
+	if !git.IsRepo(ctx) {
 		return result, errors.New("current folder is not a git repository")
 	}
-	out, err := git.Run("config", "--get", "remote.origin.url")
+	out, err := git.Run(ctx, "config", "--get", "remote.origin.url")
 	if err != nil {
 		return result, errors.Wrap(err, "repository doesn't have an `origin` remote")
 	}
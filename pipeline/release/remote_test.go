@@ -3,8 +3,9 @@ package release
 import (
 	"testing"
 
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
 	"github.com/goreleaser/goreleaser/internal/testlib"
-
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,7 +14,7 @@ func TestRepoName(t *testing.T) {
 	defer back()
 	testlib.GitInit(t)
 	testlib.GitRemoteAdd(t, "git@github.com:goreleaser/goreleaser.git")
-	repo, err := remoteRepo()
+	repo, err := remoteRepo(context.New(config.Project{}))
 	assert.NoError(t, err)
 	assert.Equal(t, "goreleaser/goreleaser", repo.String())
 }
@@ -0,0 +1,172 @@
+package sbom
+
+import (
+	"debug/buildinfo"
+	"runtime/debug"
+	"sort"
+	"time"
+
+	"github.com/goreleaser/goreleaser/internal/artifact"
+)
+
+// module is a component of a bill of materials
+type module struct {
+	Path    string
+	Version string
+	// Application is true for the main modules of the binaries, and false
+	// for the libraries they depend on
+	Application bool
+}
+
+// purl returns the package URL of the module, which identifies it in both
+// formats, e.g. pkg:golang/github.com/apex/log@v1.0.0
+func (m module) purl() string {
+	if m.Version == "" {
+		return "pkg:golang/" + m.Path
+	}
+	return "pkg:golang/" + m.Path + "@" + m.Version
+}
+
+// bom is what a bill of materials describes: its subject, e.g. a binary,
+// the modules it is made of and which of them depends on which
+type bom struct {
+	Name    string
+	Date    time.Time
+	Subject module
+	// Modules are sorted and unique, and don't include the subject
+	Modules []module
+	// DependsOn holds the purls each module depends on, by purl
+	DependsOn map[string][]string
+}
+
+// app is a binary and the modules it was built from
+type app struct {
+	Main module
+	Deps []module
+}
+
+// readApp reads the modules the given binary was built from. The main
+// module of a binary built from a local checkout has no version, so the
+// version being released is used instead.
+func readApp(binary artifact.Artifact, version string) (app, error) {
+	info, err := buildinfo.ReadFile(binary.Path)
+	if err != nil {
+		return app{}, err
+	}
+	var main = module{
+		Path:        info.Main.Path,
+		Version:     info.Main.Version,
+		Application: true,
+	}
+	if main.Path == "" {
+		// built outside of a module
+		main.Path = info.Path
+	}
+	if main.Version == "" || main.Version == "(devel)" {
+		main.Version = version
+	}
+	var result = app{
+		Main: main,
+		Deps: []module{{Path: "stdlib", Version: info.GoVersion}},
+	}
+	for _, dep := range info.Deps {
+		result.Deps = append(result.Deps, moduleOf(dep))
+	}
+	sortModules(result.Deps)
+	return result, nil
+}
+
+func moduleOf(dep *debug.Module) module {
+	if dep.Replace != nil {
+		return module{Path: dep.Replace.Path, Version: dep.Replace.Version}
+	}
+	return module{Path: dep.Path, Version: dep.Version}
+}
+
+// binaryBOM returns the bill of materials of a single binary
+func binaryBOM(name string, date time.Time, a app) bom {
+	return bom{
+		Name:    name,
+		Date:    date,
+		Subject: a.Main,
+		Modules: a.Deps,
+		DependsOn: map[string][]string{
+			a.Main.purl(): purls(a.Deps),
+		},
+	}
+}
+
+// releaseBOM returns the bill of materials of the whole release, whose
+// subject is the project, made of the main modules of all its binaries
+func releaseBOM(name string, date time.Time, project module, apps []app) bom {
+	var result = bom{
+		Name:      name,
+		Date:      date,
+		Subject:   project,
+		DependsOn: map[string][]string{},
+	}
+	var seen = map[string]bool{}
+	var add = func(m module) {
+		if !seen[m.purl()] {
+			seen[m.purl()] = true
+			result.Modules = append(result.Modules, m)
+		}
+	}
+	var mains []module
+	for _, a := range apps {
+		add(a.Main)
+		mains = append(mains, a.Main)
+		for _, dep := range a.Deps {
+			add(dep)
+		}
+		// the same main module may be in several binaries, e.g. one per
+		// platform, whose dependencies differ, e.g. golang.org/x/sys is only
+		// in some of them, so it depends on all of them
+		var main = a.Main.purl()
+		result.DependsOn[main] = append(result.DependsOn[main], purls(a.Deps)...)
+	}
+	for main, deps := range result.DependsOn {
+		result.DependsOn[main] = unique(deps)
+	}
+	sortModules(result.Modules)
+	result.DependsOn[project.purl()] = unique(purls(mains))
+	return result
+}
+
+func purls(modules []module) []string {
+	var result []string
+	for _, m := range modules {
+		result = append(result, m.purl())
+	}
+	return result
+}
+
+func unique(list []string) []string {
+	var seen = map[string]bool{}
+	var result []string
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sortModules(modules []module) {
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].purl() < modules[j].purl()
+	})
+}
+
+// dependents returns the purls of the modules with dependencies, sorted,
+// so the documents are always the same
+func (b bom) dependents() []string {
+	var result []string
+	for purl := range b.DependsOn {
+		result = append(result, purl)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,80 @@
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CycloneDX 1.5 JSON document, see https://cyclonedx.org/docs/1.5/json/
+type cdxDocument struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func cdxComponentOf(m module) cdxComponent {
+	var kind = "library"
+	if m.Application {
+		kind = "application"
+	}
+	return cdxComponent{
+		BOMRef:  m.purl(),
+		Type:    kind,
+		Name:    m.Path,
+		Version: m.Version,
+		PURL:    m.purl(),
+	}
+}
+
+func cycloneDX(b bom) ([]byte, error) {
+	var doc = cdxDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cdxMetadata{
+			Timestamp: b.Date.UTC().Format(time.RFC3339),
+			Tools: cdxTools{
+				Components: []cdxComponent{{Type: "application", Name: "goreleaser"}},
+			},
+			Component: cdxComponentOf(b.Subject),
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{},
+	}
+	for _, m := range b.Modules {
+		doc.Components = append(doc.Components, cdxComponentOf(m))
+	}
+	for _, purl := range b.dependents() {
+		doc.Dependencies = append(doc.Dependencies, cdxDependency{
+			Ref:       purl,
+			DependsOn: append([]string{}, b.DependsOn[purl]...),
+		})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
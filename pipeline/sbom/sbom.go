@@ -0,0 +1,156 @@
+// Package sbom implements the Pipe interface generating the software bills
+// of materials of the binaries and of the release, from the module info the
+// go toolchain embeds in every binary.
+package sbom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/apex/log"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/tmpl"
+	"github.com/goreleaser/goreleaser/pipeline"
+)
+
+// releaseNameTemplate is the name of the bill of materials of the release
+const releaseNameTemplate = "{{ .ProjectName }}_{{ .Version }}"
+
+// formats are the supported formats, with the extension of their files and
+// the function generating their documents
+var formats = map[string]struct {
+	ext      string
+	generate func(bom) ([]byte, error)
+}{
+	"cyclonedx": {".cdx.json", cycloneDX},
+	"spdx":      {".spdx.json", spdx},
+}
+
+// Pipe for sbom
+type Pipe struct{}
+
+func (Pipe) String() string {
+	return "generating software bills of materials"
+}
+
+// ID of the pipe
+func (Pipe) ID() string {
+	return "sbom"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"build"}
+}
+
+// Default sets the pipe defaults
+func (Pipe) Default(ctx *context.Context) error {
+	if ctx.Config.SBOM.NameTemplate == "" {
+		ctx.Config.SBOM.NameTemplate = "{{ .Binary }}_{{ .Version }}_{{ .Os }}_{{ .Arch }}{{ if .Arm }}v{{ .Arm }}{{ end }}{{ .Amd64 }}{{ if .I386 }}_{{ .I386 }}{{ end }}{{ if .Mips }}_{{ .Mips }}{{ end }}{{ if .Ppc64 }}_{{ .Ppc64 }}{{ end }}"
+	}
+	return nil
+}
+
+// Run the pipe
+func (Pipe) Run(ctx *context.Context) error {
+	if len(ctx.Config.SBOM.Formats) == 0 {
+		return pipeline.Skip("sbom section is not configured")
+	}
+	for _, format := range ctx.Config.SBOM.Formats {
+		if _, ok := formats[format]; !ok {
+			return fmt.Errorf("invalid sbom format: %s", format)
+		}
+	}
+	var binaries = ctx.Artifacts.Filter(
+		artifact.And(
+			artifact.ByType(artifact.Binary),
+			artifact.ByIDs(ctx.Config.SBOM.Builds...),
+		),
+	).List()
+	if len(binaries) == 0 {
+		return pipeline.Skip("no binaries to generate a bill of materials of")
+	}
+	// sorted, so the bill of materials of the release is always the same
+	sort.Slice(binaries, func(i, j int) bool {
+		return binaries[i].Path < binaries[j].Path
+	})
+	var apps []app
+	for _, binary := range binaries {
+		name, err := tmpl.New(ctx).
+			WithArtifact(binary, nil).
+			Apply(ctx.Config.SBOM.NameTemplate)
+		if err != nil {
+			return err
+		}
+		var a app
+		if !ctx.DryRun {
+			a, err = readApp(binary, ctx.Version)
+			if err != nil {
+				return fmt.Errorf("failed to read the modules of %s: %v", binary.Path, err)
+			}
+			apps = append(apps, a)
+		}
+		if err := write(ctx, binaryBOM(name, ctx.SourceDate(), a), artifact.Artifact{
+			Goos:    binary.Goos,
+			Goarch:  binary.Goarch,
+			Goarm:   binary.Goarm,
+			Goamd64: binary.Goamd64,
+			Go386:   binary.Go386,
+			Gomips:  binary.Gomips,
+			Goppc64: binary.Goppc64,
+			Extra: map[string]string{
+				"Binary": binary.Extra["Binary"],
+				"ID":     binary.Extra["ID"],
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	name, err := tmpl.New(ctx).Apply(releaseNameTemplate)
+	if err != nil {
+		return err
+	}
+	var project = module{
+		Path:        ctx.Config.ProjectName,
+		Version:     ctx.Version,
+		Application: true,
+	}
+	return write(ctx, releaseBOM(name, ctx.SourceDate(), project, apps), artifact.Artifact{
+		Extra: map[string]string{
+			"ID": artifact.JoinIDs(binaries),
+		},
+	})
+}
+
+// write writes the given bill of materials in all the configured formats,
+// and adds them as artifacts based on the given one
+func write(ctx *context.Context, b bom, base artifact.Artifact) error {
+	for _, format := range ctx.Config.SBOM.Formats {
+		var result = base
+		result.Type = artifact.SBOM
+		result.Name = b.Name + formats[format].ext
+		result.Path = filepath.Join(ctx.Config.Dist, result.Name)
+		result.Extra = map[string]string{"Format": format}
+		for k, v := range base.Extra {
+			result.Extra[k] = v
+		}
+		if ctx.DryRun {
+			log.WithField("file", result.Path).Info("dry-run: would write")
+			ctx.Artifacts.Add(result)
+			continue
+		}
+		bts, err := formats[format].generate(b)
+		if err != nil {
+			return err
+		}
+		log.WithField("file", result.Path).Info("writing")
+		if err := ioutil.WriteFile(result.Path, bts, 0644); err != nil {
+			return err
+		}
+		ctx.Artifacts.Add(result)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goreleaser/goreleaser/config"
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/testlib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescription(t *testing.T) {
+	assert.NotEmpty(t, Pipe{}.String())
+}
+
+func TestDefault(t *testing.T) {
+	var ctx = context.New(config.Project{})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	assert.NotEmpty(t, ctx.Config.SBOM.NameTemplate)
+}
+
+func TestRunPipeNotConfigured(t *testing.T) {
+	testlib.AssertSkipped(t, Pipe{}.Run(context.New(config.Project{})))
+}
+
+func TestRunPipeNoBinaries(t *testing.T) {
+	var ctx = context.New(config.Project{
+		SBOM: config.SBOM{Formats: []string{"spdx"}},
+	})
+	testlib.AssertSkipped(t, Pipe{}.Run(ctx))
+}
+
+func TestRunPipeInvalidFormat(t *testing.T) {
+	var ctx = context.New(config.Project{
+		SBOM: config.SBOM{Formats: []string{"spdx", "swid"}},
+	})
+	assert.EqualError(t, Pipe{}.Run(ctx), "invalid sbom format: swid")
+}
+
+func TestRunPipe(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var dist = filepath.Join(folder, "dist")
+	assert.NoError(t, os.Mkdir(dist, 0755))
+	var ctx = context.New(config.Project{
+		ProjectName: "proj",
+		Dist:        dist,
+		SBOM: config.SBOM{
+			Formats: []string{"cyclonedx", "spdx"},
+			Builds:  []string{"server"},
+		},
+	})
+	ctx.Version = "1.0.0"
+	assert.NoError(t, Pipe{}.Default(ctx))
+	for _, goos := range []string{"linux", "windows"} {
+		ctx.Artifacts.Add(artifact.Artifact{
+			Type:   artifact.Binary,
+			Name:   "server",
+			Path:   buildBinary(t, folder, goos),
+			Goos:   goos,
+			Goarch: "amd64",
+			Extra:  map[string]string{"Binary": "server", "ID": "server"},
+		})
+	}
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type:  artifact.Binary,
+		Name:  "client",
+		Path:  "not built",
+		Extra: map[string]string{"Binary": "client", "ID": "client"},
+	})
+	assert.NoError(t, Pipe{}.Run(ctx))
+
+	var names []string
+	for _, a := range ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List() {
+		names = append(names, a.Name)
+		assert.Equal(t, "server", a.Extra["ID"])
+		_, err := os.Stat(a.Path)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{
+		"server_1.0.0_linux_amd64.cdx.json",
+		"server_1.0.0_linux_amd64.spdx.json",
+		"server_1.0.0_windows_amd64.cdx.json",
+		"server_1.0.0_windows_amd64.spdx.json",
+		"proj_1.0.0.cdx.json",
+		"proj_1.0.0.spdx.json",
+	}, names)
+
+	var cdx cdxDocument
+	readJSON(t, filepath.Join(dist, "proj_1.0.0.cdx.json"), &cdx)
+	assert.Equal(t, "CycloneDX", cdx.BOMFormat)
+	assert.Equal(t, "proj", cdx.Metadata.Component.Name)
+	assert.Equal(t, "1.0.0", cdx.Metadata.Component.Version)
+	assert.Equal(t, ctx.SourceDate().UTC().Format(time.RFC3339), cdx.Metadata.Timestamp)
+	assert.Len(t, cdx.Components, 2, "the main package and the standard library")
+	assert.Equal(t, "stdlib", cdx.Components[1].Name)
+
+	var doc spdxDocument
+	readJSON(t, filepath.Join(dist, "server_1.0.0_linux_amd64.spdx.json"), &doc)
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "server_1.0.0_linux_amd64", doc.Name)
+	assert.Len(t, doc.Packages, 2)
+	assert.Equal(t, "APPLICATION", doc.Packages[0].PrimaryPackagePurpose)
+	assert.Equal(t, "1.0.0", doc.Packages[0].VersionInfo)
+}
+
+func TestRunPipeDryRun(t *testing.T) {
+	var ctx = context.New(config.Project{
+		ProjectName: "proj",
+		Dist:        "/nope",
+		SBOM:        config.SBOM{Formats: []string{"spdx"}},
+	})
+	ctx.Version = "1.0.0"
+	ctx.DryRun = true
+	assert.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type:   artifact.Binary,
+		Name:   "server",
+		Path:   "not built",
+		Goos:   "linux",
+		Goarch: "amd64",
+		Extra:  map[string]string{"Binary": "server", "ID": "server"},
+	})
+	assert.NoError(t, Pipe{}.Run(ctx))
+	assert.Len(t, ctx.Artifacts.Filter(artifact.ByType(artifact.SBOM)).List(), 2)
+}
+
+func TestRunPipeNotAGoBinary(t *testing.T) {
+	folder, back := testlib.Mktmp(t)
+	defer back()
+	var path = filepath.Join(folder, "script.sh")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("#!/bin/sh\n"), 0755))
+	var ctx = context.New(config.Project{
+		Dist: folder,
+		SBOM: config.SBOM{Formats: []string{"spdx"}},
+	})
+	assert.NoError(t, Pipe{}.Default(ctx))
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type:  artifact.Binary,
+		Name:  "script.sh",
+		Path:  path,
+		Extra: map[string]string{"Binary": "script.sh"},
+	})
+	var err = Pipe{}.Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read the modules of "+path)
+}
+
+func TestReleaseBOM(t *testing.T) {
+	var lib = module{Path: "github.com/apex/log", Version: "v1.0.0"}
+	var std = module{Path: "stdlib", Version: "go1.10"}
+	var server = module{Path: "example.com/server", Version: "1.0.0", Application: true}
+	var client = module{Path: "example.com/client", Version: "1.0.0", Application: true}
+	var project = module{Path: "proj", Version: "1.0.0", Application: true}
+	var b = releaseBOM("proj_1.0.0", time.Unix(0, 0), project, []app{
+		{Main: server, Deps: []module{lib, std}},
+		{Main: server, Deps: []module{lib, std}},
+		{Main: client, Deps: []module{std}},
+	})
+	assert.Equal(t, []module{client, server, lib, std}, b.Modules)
+	assert.Equal(t, map[string][]string{
+		"pkg:golang/proj@1.0.0":               {"pkg:golang/example.com/client@1.0.0", "pkg:golang/example.com/server@1.0.0"},
+		"pkg:golang/example.com/server@1.0.0": {"pkg:golang/github.com/apex/log@v1.0.0", "pkg:golang/stdlib@go1.10"},
+		"pkg:golang/example.com/client@1.0.0": {"pkg:golang/stdlib@go1.10"},
+	}, b.DependsOn)
+}
+
+func TestReleaseBOMPlatformDeps(t *testing.T) {
+	var std = module{Path: "stdlib", Version: "go1.10"}
+	var sys = module{Path: "golang.org/x/sys", Version: "v0.1.0"}
+	var term = module{Path: "golang.org/x/term", Version: "v0.1.0"}
+	var server = module{Path: "example.com/server", Version: "1.0.0", Application: true}
+	var project = module{Path: "proj", Version: "1.0.0", Application: true}
+	// e.g. the linux and the windows binaries, whose build-tagged
+	// dependencies differ
+	var b = releaseBOM("proj_1.0.0", time.Unix(0, 0), project, []app{
+		{Main: server, Deps: []module{term, std}},
+		{Main: server, Deps: []module{sys, std}},
+	})
+	assert.Equal(t, []module{server, sys, term, std}, b.Modules)
+	assert.Equal(t, []string{
+		"pkg:golang/golang.org/x/sys@v0.1.0",
+		"pkg:golang/golang.org/x/term@v0.1.0",
+		"pkg:golang/stdlib@go1.10",
+	}, b.DependsOn["pkg:golang/example.com/server@1.0.0"])
+}
+
+func TestDocuments(t *testing.T) {
+	var b = binaryBOM("server", time.Unix(0, 0), app{
+		Main: module{Path: "example.com/server", Version: "1.0.0", Application: true},
+		Deps: []module{
+			{Path: "github.com/apex/log", Version: "v1.0.0"},
+			{Path: "example.com/local"},
+		},
+	})
+
+	bts, err := cycloneDX(b)
+	assert.NoError(t, err)
+	var cdx cdxDocument
+	assert.NoError(t, json.Unmarshal(bts, &cdx))
+	assert.Equal(t, "1970-01-01T00:00:00Z", cdx.Metadata.Timestamp)
+	assert.Equal(t, "application", cdx.Metadata.Component.Type)
+	assert.Equal(t, "library", cdx.Components[0].Type)
+	assert.Equal(t, "pkg:golang/github.com/apex/log@v1.0.0", cdx.Components[0].PURL)
+	assert.Equal(t, "pkg:golang/example.com/local", cdx.Components[1].PURL)
+	assert.Equal(t, []cdxDependency{{
+		Ref:       "pkg:golang/example.com/server@1.0.0",
+		DependsOn: []string{"pkg:golang/github.com/apex/log@v1.0.0", "pkg:golang/example.com/local"},
+	}}, cdx.Dependencies)
+
+	bts, err = spdx(b)
+	assert.NoError(t, err)
+	var doc spdxDocument
+	assert.NoError(t, json.Unmarshal(bts, &doc))
+	assert.Equal(t, "SPDXRef-Package-pkg-golang-example.com-server-1.0.0", doc.Packages[0].SPDXID)
+	assert.Equal(t, []spdxRelationship{
+		{"SPDXRef-DOCUMENT", "DESCRIBES", "SPDXRef-Package-pkg-golang-example.com-server-1.0.0"},
+		{"SPDXRef-Package-pkg-golang-example.com-server-1.0.0", "DEPENDS_ON", "SPDXRef-Package-pkg-golang-github.com-apex-log-v1.0.0"},
+		{"SPDXRef-Package-pkg-golang-example.com-server-1.0.0", "DEPENDS_ON", "SPDXRef-Package-pkg-golang-example.com-local"},
+	}, doc.Relationships)
+
+	again, err := spdx(b)
+	assert.NoError(t, err)
+	assert.Equal(t, string(bts), string(again), "documents should be reproducible")
+}
+
+func buildBinary(t *testing.T, folder, goos string) string {
+	assert.NoError(t, ioutil.WriteFile(
+		filepath.Join(folder, "main.go"),
+		[]byte("package main\nfunc main() {println(0)}"),
+		0644,
+	))
+	var binary = filepath.Join(folder, "server_"+goos)
+	/* #nosec */
+	var cmd = exec.Command("go", "build", "-o", binary, "main.go")
+	cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH=amd64")
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+	return binary
+}
+
+func readJSON(t *testing.T, path string, v interface{}) {
+	bts, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(bts, v))
+}
@@ -0,0 +1,125 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// SPDX 2.3 JSON document, see https://spdx.github.io/spdx-spec/v2.3/
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var invalidSPDXIDChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// spdxID returns the ID of the package with the given purl, which may only
+// have letters, numbers, dots and dashes
+func spdxID(purl string) string {
+	return "SPDXRef-Package-" + invalidSPDXIDChars.ReplaceAllString(purl, "-")
+}
+
+func spdxPackageOf(m module) spdxPackage {
+	var purpose = "LIBRARY"
+	if m.Application {
+		purpose = "APPLICATION"
+	}
+	return spdxPackage{
+		Name:                  m.Path,
+		SPDXID:                spdxID(m.purl()),
+		VersionInfo:           m.Version,
+		DownloadLocation:      "NOASSERTION",
+		PrimaryPackagePurpose: purpose,
+		ExternalRefs: []spdxExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  m.purl(),
+		}},
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "NOASSERTION",
+		CopyrightText:    "NOASSERTION",
+	}
+}
+
+func spdx(b bom) ([]byte, error) {
+	var doc = spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        b.Name,
+		// the namespace must be unique to the document, but the same
+		// document must always get the same one to be reproducible
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%x", b.Name, namespaceHash(b)),
+		CreationInfo: spdxCreationInfo{
+			Created:  b.Date.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: goreleaser"},
+		},
+		Packages: []spdxPackage{spdxPackageOf(b.Subject)},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: spdxID(b.Subject.purl()),
+		}},
+	}
+	for _, m := range b.Modules {
+		doc.Packages = append(doc.Packages, spdxPackageOf(m))
+	}
+	for _, purl := range b.dependents() {
+		for _, dep := range b.DependsOn[purl] {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SPDXElementID:      spdxID(purl),
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: spdxID(dep),
+			})
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func namespaceHash(b bom) []byte {
+	var h = sha256.New()
+	fmt.Fprintln(h, b.Name, b.Date.Unix(), b.Subject.purl())
+	for _, m := range b.Modules {
+		fmt.Fprintln(h, m.purl())
+	}
+	return h.Sum(nil)[:16]
+}
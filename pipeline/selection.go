@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Identifier can be implemented by a Piper to have a stable ID, which is
+// used to select the pipes to run with --only and --skip.
+// Pipes that don't implement it always run.
+type Identifier interface {
+	// ID returns the stable identifier of the pipe
+	ID() string
+}
+
+// Dependant can be implemented by a Piper that needs the output of other
+// pipes to work.
+type Dependant interface {
+	// Dependencies returns the IDs of the pipes this one depends on
+	Dependencies() []string
+}
+
+// Select filters the given pipes, keeping only the ones whose IDs are in
+// only (or all of them, if only is empty) and not in skip.
+// It fails if an ID is unknown or if a selected pipe depends on a pipe
+// that was not selected.
+func Select(pipes []Piper, only, skip []string) ([]Piper, error) {
+	var known = map[string]bool{}
+	for _, pipe := range pipes {
+		if id, ok := pipe.(Identifier); ok {
+			known[id.ID()] = true
+		}
+	}
+	for _, ids := range [][]string{only, skip} {
+		for _, id := range ids {
+			if !known[id] {
+				return nil, fmt.Errorf("unknown pipe: %s", id)
+			}
+		}
+	}
+	var selected = map[string]bool{}
+	for id := range known {
+		selected[id] = len(only) == 0 || contains(only, id)
+		if contains(skip, id) {
+			selected[id] = false
+		}
+	}
+	var result []Piper
+	for _, pipe := range pipes {
+		id, ok := pipe.(Identifier)
+		if ok && !selected[id.ID()] {
+			continue
+		}
+		if err := checkDependencies(pipe, known, selected); err != nil {
+			return nil, err
+		}
+		result = append(result, pipe)
+	}
+	return result, nil
+}
+
+// SplitIDs splits a comma separated list of pipe IDs, as given in the
+// --only and --skip flags
+func SplitIDs(s string) []string {
+	var result []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func checkDependencies(pipe Piper, known, selected map[string]bool) error {
+	dependant, ok := pipe.(Dependant)
+	if !ok {
+		return nil
+	}
+	for _, dep := range dependant.Dependencies() {
+		// dependencies that are not part of this pipeline are expected to
+		// have been satisfied somehow else, e.g. by a previous run
+		if known[dep] && !selected[dep] {
+			return fmt.Errorf("%s depends on %s, which was not selected", name(pipe), dep)
+		}
+	}
+	return nil
+}
+
+func name(pipe Piper) string {
+	if id, ok := pipe.(Identifier); ok {
+		return id.ID()
+	}
+	return pipe.String()
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/goreleaser/goreleaser/context"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePipe struct {
+	id   string
+	deps []string
+}
+
+func (p fakePipe) String() string {
+	return "fake " + p.id
+}
+
+func (fakePipe) Run(ctx *context.Context) error {
+	return nil
+}
+
+func (p fakePipe) ID() string {
+	return p.id
+}
+
+func (p fakePipe) Dependencies() []string {
+	return p.deps
+}
+
+type setupPipe struct{}
+
+func (setupPipe) String() string {
+	return "setup"
+}
+
+func (setupPipe) Run(ctx *context.Context) error {
+	return nil
+}
+
+var pipes = []Piper{
+	setupPipe{},
+	fakePipe{id: "build"},
+	fakePipe{id: "archive", deps: []string{"build"}},
+	fakePipe{id: "docker", deps: []string{"build"}},
+	fakePipe{id: "release", deps: []string{"upload"}},
+}
+
+func ids(pipes []Piper) []string {
+	var result = []string{}
+	for _, pipe := range pipes {
+		if id, ok := pipe.(Identifier); ok {
+			result = append(result, id.ID())
+		} else {
+			result = append(result, pipe.String())
+		}
+	}
+	return result
+}
+
+func TestSelect(t *testing.T) {
+	for name, tt := range map[string]struct {
+		only, skip, expected []string
+	}{
+		"all": {
+			expected: []string{"setup", "build", "archive", "docker", "release"},
+		},
+		"only": {
+			only:     []string{"archive", "build"},
+			expected: []string{"setup", "build", "archive"},
+		},
+		"skip": {
+			skip:     []string{"docker", "release"},
+			expected: []string{"setup", "build", "archive"},
+		},
+		"only and skip": {
+			only:     []string{"build", "docker"},
+			skip:     []string{"docker"},
+			expected: []string{"setup", "build"},
+		},
+		"dependency not in pipeline": {
+			only:     []string{"release"},
+			expected: []string{"setup", "release"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := Select(pipes, tt.only, tt.skip)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ids(result))
+		})
+	}
+}
+
+func TestSelectUnknownPipe(t *testing.T) {
+	_, err := Select(pipes, []string{"build", "nope"}, nil)
+	assert.EqualError(t, err, "unknown pipe: nope")
+	_, err = Select(pipes, nil, []string{"nope"})
+	assert.EqualError(t, err, "unknown pipe: nope")
+}
+
+func TestSelectMissingDependency(t *testing.T) {
+	_, err := Select(pipes, nil, []string{"build"})
+	assert.EqualError(t, err, "archive depends on build, which was not selected")
+	_, err = Select(pipes, []string{"docker"}, nil)
+	assert.EqualError(t, err, "docker depends on build, which was not selected")
+}
+
+func TestSplitIDs(t *testing.T) {
+	assert.Empty(t, SplitIDs(""))
+	assert.Equal(t, []string{"docker", "snapcraft", "sign"}, SplitIDs("docker, snapcraft,,sign"))
+}
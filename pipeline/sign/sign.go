@@ -3,10 +3,11 @@ package sign
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
+	"github.com/goreleaser/goreleaser/internal/runner"
 	"github.com/goreleaser/goreleaser/pipeline"
 )
 
@@ -17,6 +18,16 @@ func (Pipe) String() string {
 	return "signing artifacts"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "sign"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"checksums"}
+}
+
 // Default sets the Pipes defaults.
 func (Pipe) Default(ctx *context.Context) error {
 	cfg := &ctx.Config.Sign
@@ -39,9 +50,19 @@ func (Pipe) Default(ctx *context.Context) error {
 func (Pipe) Run(ctx *context.Context) error {
 	switch ctx.Config.Sign.Artifacts {
 	case "checksum":
-		return sign(ctx, ctx.Checksums)
+		return sign(ctx, ctx.Artifacts.Filter(artifact.ByType(artifact.Checksum)).List())
 	case "all":
-		return sign(ctx, ctx.Artifacts)
+		return sign(ctx, ctx.Artifacts.Filter(
+			artifact.Or(
+				artifact.ByType(artifact.UploadableArchive),
+				artifact.ByType(artifact.UploadableBinary),
+				artifact.ByType(artifact.Checksum),
+				artifact.ByType(artifact.LinuxPackage),
+				artifact.ByType(artifact.Snap),
+				artifact.ByType(artifact.SBOM),
+				artifact.ByType(artifact.Provenance),
+			),
+		).List())
 	case "none":
 		return pipeline.Skip("artifact signing disabled")
 	default:
@@ -49,7 +70,7 @@ func (Pipe) Run(ctx *context.Context) error {
 	}
 }
 
-func sign(ctx *context.Context, artifacts []string) error {
+func sign(ctx *context.Context, artifacts []artifact.Artifact) error {
 	var sigs []string
 	for _, a := range artifacts {
 		sig, err := signone(ctx, a)
@@ -59,17 +80,20 @@ func sign(ctx *context.Context, artifacts []string) error {
 		sigs = append(sigs, sig)
 	}
 	for _, sig := range sigs {
-		ctx.AddArtifact(sig)
+		ctx.Artifacts.Add(artifact.Artifact{
+			Type: artifact.Signature,
+			Name: filepath.Base(sig),
+			Path: sig,
+		})
 	}
 	return nil
 }
 
-func signone(ctx *context.Context, artifact string) (string, error) {
+func signone(ctx *context.Context, a artifact.Artifact) (string, error) {
 	cfg := ctx.Config.Sign
 
-	artifact = filepath.Join(ctx.Config.Dist, artifact)
 	env := map[string]string{
-		"artifact": artifact,
+		"artifact": a.Path,
 	}
 	env["signature"] = expand(cfg.Signature, env)
 
@@ -82,8 +106,9 @@ func signone(ctx *context.Context, artifact string) (string, error) {
 	// However, this works as intended. The nosec annotation
 	// tells the scanner to ignore this.
 	// #nosec
-	cmd := exec.Command(cfg.Cmd, args...)
-	output, err := cmd.CombinedOutput()
+	output, err := runner.Run(ctx, runner.Cmd{
+		Args: append([]string{cfg.Cmd}, args...),
+	})
 	if err != nil {
 		return "", fmt.Errorf("sign: %s failed with %q", cfg.Cmd, string(output))
 	}
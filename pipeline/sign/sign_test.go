@@ -11,6 +11,7 @@ import (
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -20,7 +21,7 @@ func TestDescription(t *testing.T) {
 }
 
 func TestSignDefault(t *testing.T) {
-	ctx := &context.Context{}
+	ctx := context.New(config.Project{})
 	Pipe{}.Default(ctx)
 	assert.Equal(t, ctx.Config.Sign.Cmd, "gpg")
 	assert.Equal(t, ctx.Config.Sign.Signature, "${artifact}.sig")
@@ -29,14 +30,14 @@ func TestSignDefault(t *testing.T) {
 }
 
 func TestSignDisabled(t *testing.T) {
-	ctx := &context.Context{}
+	ctx := context.New(config.Project{})
 	ctx.Config.Sign.Artifacts = "none"
 	err := Pipe{}.Run(ctx)
 	assert.EqualError(t, err, "artifact signing disabled")
 }
 
 func TestSignInvalidArtifacts(t *testing.T) {
-	ctx := &context.Context{}
+	ctx := context.New(config.Project{})
 	ctx.Config.Sign.Artifacts = "foo"
 	err := Pipe{}.Run(ctx)
 	assert.EqualError(t, err, "invalid list of artifacts to sign: foo")
@@ -55,24 +56,16 @@ func TestSignArtifacts(t *testing.T) {
 	}{
 		{
 			desc: "sign all artifacts",
-			ctx: &context.Context{
-				Config: config.Project{
-					Sign: config.Sign{Artifacts: "all"},
-				},
-				Artifacts: []string{"artifact1", "artifact2", "checksum"},
-				Checksums: []string{"checksum"},
-			},
+			ctx: context.New(config.Project{
+				Sign: config.Sign{Artifacts: "all"},
+			}),
 			signatures: []string{"artifact1.sig", "artifact2.sig", "checksum.sig"},
 		},
 		{
 			desc: "sign only checksums",
-			ctx: &context.Context{
-				Config: config.Project{
-					Sign: config.Sign{Artifacts: "checksum"},
-				},
-				Artifacts: []string{"artifact1", "artifact2", "checksum"},
-				Checksums: []string{"checksum"},
-			},
+			ctx: context.New(config.Project{
+				Sign: config.Sign{Artifacts: "checksum"},
+			}),
 			signatures: []string{"checksum.sig"},
 		},
 	}
@@ -98,13 +91,28 @@ func testSign(t *testing.T, ctx *context.Context, signatures []string) {
 	ctx.Config.Dist = tmpdir
 
 	// create some fake artifacts
-	artifacts := ctx.Artifacts
+	var artifacts = []string{"artifact1", "artifact2", "checksum"}
 	for _, f := range artifacts {
 		file := filepath.Join(tmpdir, f)
 		if err2 := ioutil.WriteFile(file, []byte("foo"), 0644); err2 != nil {
 			t.Fatal("WriteFile: ", err2)
 		}
 	}
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "artifact1",
+		Path: filepath.Join(tmpdir, "artifact1"),
+		Type: artifact.UploadableArchive,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "artifact2",
+		Path: filepath.Join(tmpdir, "artifact2"),
+		Type: artifact.UploadableArchive,
+	})
+	ctx.Artifacts.Add(artifact.Artifact{
+		Name: "checksum",
+		Path: filepath.Join(tmpdir, "checksum"),
+		Type: artifact.Checksum,
+	})
 
 	// configure the pipeline
 	// make sure we are using the test keyring
@@ -140,15 +148,19 @@ func testSign(t *testing.T, ctx *context.Context, signatures []string) {
 		verifySignature(t, ctx, sig)
 	}
 
+	var signArtifacts []string
+	for _, sig := range ctx.Artifacts.Filter(artifact.ByType(artifact.Signature)).List() {
+		signArtifacts = append(signArtifacts, sig.Name)
+	}
 	// check signature is an artifact
-	assert.Equal(t, ctx.Artifacts, append(artifacts, signatures...))
+	assert.Equal(t, signArtifacts, signatures)
 }
 
 func verifySignature(t *testing.T, ctx *context.Context, sig string) {
-	artifact := sig[:len(sig)-len(".sig")]
+	art := sig[:len(sig)-len(".sig")]
 
 	// verify signature was made with key for usesr 'nopass'
-	cmd := exec.Command("gpg", "--homedir", keyring, "--verify", filepath.Join(ctx.Config.Dist, sig), filepath.Join(ctx.Config.Dist, artifact))
+	cmd := exec.Command("gpg", "--homedir", keyring, "--verify", filepath.Join(ctx.Config.Dist, sig), filepath.Join(ctx.Config.Dist, art))
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		t.Log(string(out))
@@ -6,13 +6,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 
 	"github.com/apex/log"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/internal/linux"
+	"github.com/goreleaser/goreleaser/internal/nametemplate"
+	"github.com/goreleaser/goreleaser/internal/runner"
 	"github.com/goreleaser/goreleaser/pipeline"
 	"golang.org/x/sync/errgroup"
 	yaml "gopkg.in/yaml.v2"
@@ -53,6 +54,16 @@ func (Pipe) String() string {
 	return "creating Linux packages with snapcraft"
 }
 
+// ID of the pipe
+func (Pipe) ID() string {
+	return "snapcraft"
+}
+
+// Dependencies of the pipe
+func (Pipe) Dependencies() []string {
+	return []string{"build"}
+}
+
 // Run the pipe
 func (Pipe) Run(ctx *context.Context) error {
 	if ctx.Config.Snapcraft.Summary == "" && ctx.Config.Snapcraft.Description == "" {
@@ -64,29 +75,34 @@ func (Pipe) Run(ctx *context.Context) error {
 	if ctx.Config.Snapcraft.Description == "" {
 		return ErrNoDescription
 	}
-	_, err := exec.LookPath("snapcraft")
-	if err != nil {
+	if err := runner.LookPath(ctx, "snapcraft"); err != nil {
 		return ErrNoSnapcraft
 	}
 
 	var g errgroup.Group
-	for platform, groups := range ctx.Binaries {
-		if !strings.Contains(platform, "linux") {
-			log.WithField("platform", platform).Debug("skipped non-linux builds for snapcraft")
-			continue
-		}
-		arch := linux.Arch(platform)
-		for folder, binaries := range groups {
-			g.Go(func() error {
-				return create(ctx, folder, arch, binaries)
-			})
-		}
+	for platform, binaries := range ctx.Artifacts.Filter(
+		artifact.And(
+			artifact.ByGoos("linux"),
+			artifact.ByType(artifact.Binary),
+			artifact.ByIDs(ctx.Config.Snapcraft.Builds...),
+		),
+	).GroupByPlatform() {
+		arch := linux.Arch(binaries[0].Goarch, binaries[0].Variant())
+		binaries := binaries
+		log.WithField("platform", platform).Debug("creating snap")
+		g.Go(func() error {
+			return create(ctx, arch, binaries)
+		})
 	}
 	return g.Wait()
 }
 
-func create(ctx *context.Context, folder, arch string, binaries []context.Binary) error {
+func create(ctx *context.Context, arch string, binaries []artifact.Artifact) error {
 	var log = log.WithField("arch", arch)
+	folder, err := nametemplate.Apply(ctx, ctx.Config.ArchiveFor(binaries[0].Extra["ID"]), binaries[0], ctx.Config.ProjectName)
+	if err != nil {
+		return err
+	}
 	// prime is the directory that then will be compressed to make the .snap package.
 	var folderDir = filepath.Join(ctx.Config.Dist, folder)
 	var primeDir = filepath.Join(folderDir, "prime")
@@ -128,6 +144,12 @@ func create(ctx *context.Context, folder, arch string, binaries []context.Binary
 		metadata.Apps[binary.Name] = appMetadata
 
 		destBinaryPath := filepath.Join(primeDir, filepath.Base(binary.Path))
+		if ctx.DryRun {
+			log.WithField("src", binary.Path).
+				WithField("dst", destBinaryPath).
+				Info("dry-run: would link binary")
+			continue
+		}
 		if err := os.Link(binary.Path, destBinaryPath); err != nil {
 			return err
 		}
@@ -142,11 +164,25 @@ func create(ctx *context.Context, folder, arch string, binaries []context.Binary
 	}
 
 	var snap = filepath.Join(ctx.Config.Dist, folder+".snap")
-	/* #nosec */
-	var cmd = exec.Command("snapcraft", "snap", primeDir, "--output", snap)
-	if out, err = cmd.CombinedOutput(); err != nil {
+	if out, err = runner.Run(ctx, runner.Cmd{
+		Args: []string{"snapcraft", "snap", primeDir, "--output", snap},
+	}); err != nil {
 		return fmt.Errorf("failed to generate snap package: %s", string(out))
 	}
-	ctx.AddArtifact(snap)
+	ctx.Artifacts.Add(artifact.Artifact{
+		Type:    artifact.Snap,
+		Name:    folder + ".snap",
+		Path:    snap,
+		Goos:    binaries[0].Goos,
+		Goarch:  binaries[0].Goarch,
+		Goarm:   binaries[0].Goarm,
+		Goamd64: binaries[0].Goamd64,
+		Go386:   binaries[0].Go386,
+		Gomips:  binaries[0].Gomips,
+		Goppc64: binaries[0].Goppc64,
+		Extra: map[string]string{
+			"ID": artifact.JoinIDs(binaries),
+		},
+	})
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/goreleaser/goreleaser/config"
 	"github.com/goreleaser/goreleaser/context"
+	"github.com/goreleaser/goreleaser/internal/artifact"
 	"github.com/goreleaser/goreleaser/pipeline"
 	"github.com/stretchr/testify/assert"
 	yaml "gopkg.in/yaml.v2"
@@ -29,11 +30,9 @@ func TestRunPipeMissingInfo(t *testing.T) {
 		pipeline.Skip("no summary nor description were provided"): {},
 	} {
 		t.Run(fmt.Sprintf("testing if %v happens", eerr), func(t *testing.T) {
-			var ctx = &context.Context{
-				Config: config.Project{
-					Snapcraft: snap,
-				},
-			}
+			var ctx = context.New(config.Project{
+				Snapcraft: snap,
+			})
 			assert.Equal(t, eerr, Pipe{}.Run(ctx))
 		})
 	}
@@ -45,17 +44,18 @@ func TestRunPipe(t *testing.T) {
 	var dist = filepath.Join(folder, "dist")
 	assert.NoError(t, os.Mkdir(dist, 0755))
 	assert.NoError(t, err)
-	var ctx = &context.Context{
-		Version: "testversion",
-		Config: config.Project{
-			ProjectName: "mybin",
-			Dist:        dist,
-			Snapcraft: config.Snapcraft{
-				Summary:     "test summary",
-				Description: "test description",
-			},
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		Archive: config.Archive{
+			NameTemplate: "foo_{{.Arch}}",
 		},
-	}
+		Snapcraft: config.Snapcraft{
+			Summary:     "test summary",
+			Description: "test description",
+		},
+	})
+	ctx.Version = "testversion"
 	addBinaries(t, ctx, "mybin", dist)
 	assert.NoError(t, Pipe{}.Run(ctx))
 }
@@ -66,21 +66,22 @@ func TestRunPipeWithName(t *testing.T) {
 	var dist = filepath.Join(folder, "dist")
 	assert.NoError(t, os.Mkdir(dist, 0755))
 	assert.NoError(t, err)
-	var ctx = &context.Context{
-		Version: "testversion",
-		Config: config.Project{
-			ProjectName: "testprojectname",
-			Dist:        dist,
-			Snapcraft: config.Snapcraft{
-				Name:        "testsnapname",
-				Summary:     "test summary",
-				Description: "test description",
-			},
+	var ctx = context.New(config.Project{
+		ProjectName: "testprojectname",
+		Dist:        dist,
+		Archive: config.Archive{
+			NameTemplate: "foo_{{.Arch}}",
 		},
-	}
+		Snapcraft: config.Snapcraft{
+			Name:        "testsnapname",
+			Summary:     "test summary",
+			Description: "test description",
+		},
+	})
+	ctx.Version = "testversion"
 	addBinaries(t, ctx, "testprojectname", dist)
 	assert.NoError(t, Pipe{}.Run(ctx))
-	yamlFile, err := ioutil.ReadFile(filepath.Join(dist, "testprojectname_linuxamd64", "prime", "meta", "snap.yaml"))
+	yamlFile, err := ioutil.ReadFile(filepath.Join(dist, "foo_amd64", "prime", "meta", "snap.yaml"))
 	assert.NoError(t, err)
 	var metadata Metadata
 	err = yaml.Unmarshal(yamlFile, &metadata)
@@ -94,26 +95,27 @@ func TestRunPipeWithPlugsAndDaemon(t *testing.T) {
 	var dist = filepath.Join(folder, "dist")
 	assert.NoError(t, os.Mkdir(dist, 0755))
 	assert.NoError(t, err)
-	var ctx = &context.Context{
-		Version: "testversion",
-		Config: config.Project{
-			ProjectName: "mybin",
-			Dist:        dist,
-			Snapcraft: config.Snapcraft{
-				Summary:     "test summary",
-				Description: "test description",
-				Apps: map[string]config.SnapcraftAppMetadata{
-					"mybin": {
-						Plugs:  []string{"home", "network"},
-						Daemon: "simple",
-					},
+	var ctx = context.New(config.Project{
+		ProjectName: "mybin",
+		Dist:        dist,
+		Archive: config.Archive{
+			NameTemplate: "foo_{{.Arch}}",
+		},
+		Snapcraft: config.Snapcraft{
+			Summary:     "test summary",
+			Description: "test description",
+			Apps: map[string]config.SnapcraftAppMetadata{
+				"mybin": {
+					Plugs:  []string{"home", "network"},
+					Daemon: "simple",
 				},
 			},
 		},
-	}
+	})
+	ctx.Version = "testversion"
 	addBinaries(t, ctx, "mybin", dist)
 	assert.NoError(t, Pipe{}.Run(ctx))
-	yamlFile, err := ioutil.ReadFile(filepath.Join(dist, "mybin_linuxamd64", "prime", "meta", "snap.yaml"))
+	yamlFile, err := ioutil.ReadFile(filepath.Join(dist, "foo_amd64", "prime", "meta", "snap.yaml"))
 	assert.NoError(t, err)
 	var metadata Metadata
 	err = yaml.Unmarshal(yamlFile, &metadata)
@@ -128,31 +130,33 @@ func TestNoSnapcraftInPath(t *testing.T) {
 		assert.NoError(t, os.Setenv("PATH", path))
 	}()
 	assert.NoError(t, os.Setenv("PATH", ""))
-	var ctx = &context.Context{
-		Config: config.Project{
-			Snapcraft: config.Snapcraft{
-				Summary:     "dummy",
-				Description: "dummy",
-			},
+	var ctx = context.New(config.Project{
+		Snapcraft: config.Snapcraft{
+			Summary:     "dummy",
+			Description: "dummy",
 		},
-	}
+	})
 	assert.EqualError(t, Pipe{}.Run(ctx), ErrNoSnapcraft.Error())
 }
 
 func addBinaries(t *testing.T, ctx *context.Context, name, dist string) {
-	for _, plat := range []string{
-		"linuxamd64",
-		"linux386",
-		"darwinamd64",
-		"linuxarm64",
-		"linuxarm6",
-		"linuxwtf",
-	} {
-		var folder = name + "_" + plat
-		assert.NoError(t, os.Mkdir(filepath.Join(dist, folder), 0755))
-		var binPath = filepath.Join(dist, folder, name)
-		_, err := os.Create(binPath)
-		assert.NoError(t, err)
-		ctx.AddBinary(plat, folder, name, binPath)
+	for _, goos := range []string{"linux", "darwin"} {
+		for _, goarch := range []string{"amd64", "386"} {
+			var folder = goos + goarch
+			assert.NoError(t, os.Mkdir(filepath.Join(dist, folder), 0755))
+			var binPath = filepath.Join(dist, folder, name)
+			_, err := os.Create(binPath)
+			assert.NoError(t, err)
+			ctx.Artifacts.Add(artifact.Artifact{
+				Name:   name,
+				Path:   binPath,
+				Goarch: goarch,
+				Goos:   goos,
+				Type:   artifact.Binary,
+				Extra: map[string]string{
+					"Binary": name,
+				},
+			})
+		}
 	}
 }